@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rickliujh/trading-chat-aggr/pkg/backfill"
+)
+
+// runBackfill handles `server backfill -symbol S -dir D -start DATE [-end DATE] [-kind aggTrades|klines]`,
+// it aggregates binance bulk download archives found under dir into 1 minute bars of [start, end] days,
+// upserts them into the bar store and prints a report of the filled ranges as json
+func runBackfill(ctx context.Context, logger logr.Logger, conf Config, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	symbol := fs.String("symbol", "", "symbol to backfill bars of")
+	dir := fs.String("dir", ".", "directory holding downloaded archives")
+	startArg := fs.String("start", "", "first day to backfill, 2006-01-02")
+	endArg := fs.String("end", "", "last day to backfill, defaults to start")
+	kind := fs.String("kind", string(backfill.KindAggTrades), "archives to read, aggTrades or klines")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.DateOnly, *startArg)
+	if err != nil {
+		return err
+	}
+	end := start
+	if *endArg != "" {
+		if end, err = time.Parse(time.DateOnly, *endArg); err != nil {
+			return err
+		}
+	}
+	end = end.AddDate(0, 0, 1)
+	if *symbol == "" || !start.Before(end) {
+		fs.Usage()
+		return errors.New("symbol and a valid date range are required")
+	}
+
+	archives, err := backfill.FindArchives(*dir, *symbol, backfill.Kind(*kind), start, end)
+	if err != nil {
+		return err
+	}
+	if len(archives) == 0 {
+		return errors.New("no archives found for symbol and date range")
+	}
+
+	store, closeStore, err := openStore(ctx, conf)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	report, err := backfill.Backfill(ctx, logger, store, *symbol, archives, start, end)
+	if err != nil {
+		return err
+	}
+	logger.Info("backfill finished", "symbol", *symbol, "archives", report.Archives, "bars", report.Bars, "replaced", report.Replaced, "filled_ranges", len(report.Filled))
+	return json.NewEncoder(os.Stdout).Encode(report)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var ErrCheckpointStoreMissing = errors.New("checkpoints need CHECKPOINT_PATH or a postgres DBURI")
+
+// checkpointsInDB tells if checkpoints are kept in the database, which has to be opened for them
+func checkpointsInDB(conf Config) bool {
+	return conf.CheckpointEvery > 0 && conf.CheckpointPath == ""
+}
+
+// openCheckpoints keeps checkpoints in CHECKPOINT_PATH if set, otherwise in the postgres store
+func openCheckpoints(conf Config, store storage.BarStore) (tradingchat.CheckpointStore, error) {
+	if conf.CheckpointPath != "" {
+		return storage.NewFileCheckpoints(conf.CheckpointPath), nil
+	}
+	if pg, ok := store.(interface {
+		Checkpoints(name string) *storage.PostgresCheckpoints
+	}); ok {
+		return pg.Checkpoints(conf.CheckpointName), nil
+	}
+	return nil, ErrCheckpointStoreMissing
+}
+
+// aggrOptions builds options of the aggregation, the latest checkpoint is restored when checkpoints are enabled
+func aggrOptions(ctx context.Context, logger logr.Logger, conf Config, store storage.BarStore) (tradingchat.AggrOptions, error) {
+	opts := tradingchat.AggrOptions{
+		Recoverer: newRecoverer(conf),
+		Shards:    conf.AggrShards,
+	}
+	for _, s := range conf.ActivityBars {
+		if s == "" {
+			continue
+		}
+		spec, err := tradingchat.ParseActivitySpec(s)
+		if err != nil {
+			return opts, err
+		}
+		opts.ActivityBars = append(opts.ActivityBars, spec)
+	}
+	for _, s := range conf.Synthetics {
+		if s == "" {
+			continue
+		}
+		spec, err := tradingchat.ParseSyntheticSpec(s)
+		if err != nil {
+			return opts, err
+		}
+		opts.Synthetics = append(opts.Synthetics, spec)
+	}
+	for _, s := range conf.Composites {
+		if s == "" {
+			continue
+		}
+		spec, err := tradingchat.ParseCompositeSpec(s)
+		if err != nil {
+			return opts, err
+		}
+		spec.MaxDeviation, spec.StaleAfter = conf.CompositeMaxDev, conf.CompositeStale
+		if err := spec.Validate(); err != nil {
+			return opts, err
+		}
+		opts.Composites = append(opts.Composites, spec)
+	}
+	// replays don't arrive in real time, liveness of their trades means nothing
+	if conf.FeedMonitor && len(conf.ReplayFiles) == 0 {
+		opts.Monitor = tradingchat.NewFeedMonitor(tradingchat.MonitorOptions{
+			StaleFactor:  conf.StaleFactor,
+			MinStale:     conf.MinStale,
+			FeedStale:    conf.FeedStale,
+			SpikeStdDevs: conf.SpikeStdDevs,
+			SpikeWindow:  conf.SpikeWindow,
+		})
+	}
+	if conf.CheckpointEvery <= 0 {
+		return opts, nil
+	}
+
+	checkpoints, err := openCheckpoints(conf, store)
+	if err != nil {
+		return opts, err
+	}
+	opts.Checkpoints = checkpoints
+	opts.CheckpointEvery = conf.CheckpointEvery
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	cp, ok, err := checkpoints.LoadCheckpoint(ctx)
+	if err != nil {
+		// starting over is better than not starting
+		logger.Error(err, "unable to load checkpoint")
+		return opts, nil
+	}
+	if ok {
+		opts.Restore = &cp
+	}
+	return opts, nil
+}
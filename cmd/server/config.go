@@ -1,17 +1,54 @@
 package main
 
 import (
+	"time"
+
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBURI         string   `mapstructure:"dburi"`
-	Addr          string   `mapstructure:"addr"`
-	Symbols       []string `mapstructure:"symbols"`
-	LogLevel      int      `mapstructure:"log_level"`
-	EnablePush    bool     `mapstructure:"enable_push"`
-	EnablePersist bool     `mapstructure:"enable_persist"`
+	DBURI           string        `mapstructure:"dburi"`
+	Addr            string        `mapstructure:"addr"`
+	Symbols         []string      `mapstructure:"symbols"`
+	LogLevel        int           `mapstructure:"log_level"`
+	EnablePush      bool          `mapstructure:"enable_push"`
+	EnablePersist   bool          `mapstructure:"enable_persist"`
+	EnableHistory   bool          `mapstructure:"enable_history"`
+	HistorySize     int           `mapstructure:"history_size"`
+	AutoMigrate     bool          `mapstructure:"auto_migrate"`
+	DBProfile       string        `mapstructure:"db_profile"`
+	PersistTrades   bool          `mapstructure:"persist_trades"`
+	TradeTapeDir    string        `mapstructure:"trade_tape_dir"`
+	TapeSegmentSize int           `mapstructure:"tape_segment_size"`
+	ReplayFiles     []string      `mapstructure:"replay_files"`
+	ReplayFormat    string        `mapstructure:"replay_format"`
+	ReplaySpeed     float64       `mapstructure:"replay_speed"`
+	GapRecovery     bool          `mapstructure:"gap_recovery"`
+	BinanceRESTURL  string        `mapstructure:"binance_rest_url"`
+	CheckpointEvery time.Duration `mapstructure:"checkpoint_interval"`
+	CheckpointPath  string        `mapstructure:"checkpoint_path"`
+	CheckpointName  string        `mapstructure:"checkpoint_name"`
+	EnableAdmin     bool          `mapstructure:"enable_admin"`
+	AdminToken      string        `mapstructure:"admin_token"`
+	OnDemandSymbols bool          `mapstructure:"on_demand_symbols"`
+	AggrShards      int           `mapstructure:"aggr_shards"`
+	ActivityBars    []string      `mapstructure:"activity_bars"`
+	Synthetics      []string      `mapstructure:"synthetics"`
+	Composites      []string      `mapstructure:"composites"`
+	CompositeMaxDev float64       `mapstructure:"composite_max_deviation"`
+	CompositeStale  time.Duration `mapstructure:"composite_stale_after"`
+	FeedMonitor     bool          `mapstructure:"feed_monitor"`
+	StaleFactor     float64       `mapstructure:"stale_factor"`
+	MinStale        time.Duration `mapstructure:"min_stale"`
+	FeedStale       time.Duration `mapstructure:"feed_stale"`
+	SpikeStdDevs    float64       `mapstructure:"spike_stddevs"`
+	SpikeWindow     int           `mapstructure:"spike_window"`
+	EnableMetrics   bool          `mapstructure:"enable_metrics"`
+	DepthSymbols    []string      `mapstructure:"depth_symbols"`
+	DepthLevels     int           `mapstructure:"depth_levels"`
+	DepthInterval   time.Duration `mapstructure:"depth_interval"`
+	EnableQuotes    bool          `mapstructure:"enable_quotes"`
 }
 
 func setDefault() {
@@ -21,6 +58,41 @@ func setDefault() {
 	viper.SetDefault("LOG_LEVEL", 0)
 	viper.SetDefault("ENABLE_PUSH", true)
 	viper.SetDefault("ENABLE_PERSIST", false)
+	viper.SetDefault("ENABLE_HISTORY", false)
+	viper.SetDefault("HISTORY_SIZE", 1440)
+	viper.SetDefault("AUTO_MIGRATE", false)
+	viper.SetDefault("DB_PROFILE", "")
+	viper.SetDefault("PERSIST_TRADES", false)
+	viper.SetDefault("TRADE_TAPE_DIR", "")
+	viper.SetDefault("TAPE_SEGMENT_SIZE", 100000)
+	viper.SetDefault("REPLAY_FILES", "")
+	viper.SetDefault("REPLAY_FORMAT", "")
+	viper.SetDefault("REPLAY_SPEED", 0)
+	viper.SetDefault("GAP_RECOVERY", false)
+	viper.SetDefault("BINANCE_REST_URL", "https://api.binance.com")
+	viper.SetDefault("CHECKPOINT_INTERVAL", "0s")
+	viper.SetDefault("CHECKPOINT_PATH", "")
+	viper.SetDefault("CHECKPOINT_NAME", "aggr")
+	viper.SetDefault("ENABLE_ADMIN", false)
+	viper.SetDefault("ADMIN_TOKEN", "")
+	viper.SetDefault("ON_DEMAND_SYMBOLS", false)
+	viper.SetDefault("AGGR_SHARDS", 0)
+	viper.SetDefault("ACTIVITY_BARS", "")
+	viper.SetDefault("SYNTHETICS", "")
+	viper.SetDefault("COMPOSITES", "")
+	viper.SetDefault("COMPOSITE_MAX_DEVIATION", 0.02)
+	viper.SetDefault("COMPOSITE_STALE_AFTER", "30s")
+	viper.SetDefault("FEED_MONITOR", false)
+	viper.SetDefault("STALE_FACTOR", 10)
+	viper.SetDefault("MIN_STALE", "30s")
+	viper.SetDefault("FEED_STALE", "1m")
+	viper.SetDefault("SPIKE_STDDEVS", 6)
+	viper.SetDefault("SPIKE_WINDOW", 100)
+	viper.SetDefault("ENABLE_METRICS", false)
+	viper.SetDefault("DEPTH_SYMBOLS", "")
+	viper.SetDefault("DEPTH_LEVELS", 10)
+	viper.SetDefault("DEPTH_INTERVAL", "1m")
+	viper.SetDefault("ENABLE_QUOTES", false)
 }
 
 func loadConfig() (Config, error) {
@@ -2,17 +2,21 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"net/http"
+	"os"
 
-	"github.com/jackc/pgx/v5"
 	"github.com/rickliujh/trading-chat-aggr/pkg/api/v1/apiv1connect"
 	"github.com/rickliujh/trading-chat-aggr/pkg/server"
-	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
 	"github.com/rickliujh/trading-chat-aggr/pkg/utils"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
 
+var ErrUnknownCommand = errors.New("unknown command, supported: migrate, rebuild, backfill")
+
 func main() {
 	conf, err := loadConfig()
 	if err != nil {
@@ -20,35 +24,135 @@ func main() {
 	}
 
 	logger := utils.NewLogger(conf.LogLevel)
+	ctx := context.Background()
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			if err := runMigrate(ctx, logger.WithName("migrate"), conf, os.Args[2:]); err != nil {
+				logger.Error(err, "migration failed")
+				os.Exit(1)
+			}
+		case "rebuild":
+			if err := runRebuild(ctx, logger.WithName("rebuild"), conf, os.Args[2:]); err != nil {
+				logger.Error(err, "rebuild failed")
+				os.Exit(1)
+			}
+		case "backfill":
+			if err := runBackfill(ctx, logger.WithName("backfill"), conf, os.Args[2:]); err != nil {
+				logger.Error(err, "backfill failed")
+				os.Exit(1)
+			}
+		default:
+			logger.Error(ErrUnknownCommand, "unable to run", "command", os.Args[1])
+			os.Exit(1)
+		}
+		return
+	}
 
 	logger.Info("starting server...")
-	ctx := context.Background()
-	conn, err := pgx.Connect(ctx, conf.DBURI)
+	persist := conf.EnablePersist
+	var store storage.BarStore
+	var trades storage.TradeStore
+	if conf.EnablePersist || conf.EnableHistory || (conf.PersistTrades && conf.TradeTapeDir == "") || checkpointsInDB(conf) {
+		var closeStore func()
+		store, closeStore, err = openStore(ctx, conf)
+		if err != nil {
+			logger.Error(err, "unable to open storage")
+			return
+		}
+		defer closeStore()
+
+		if isPostgres(store) && conf.AutoMigrate {
+			ms, closeConn, err := newMigrators(ctx, logger.WithName("migrate"), conf)
+			if err != nil {
+				logger.Error(err, "unable to connect to db for migration")
+				return
+			}
+			err = migrateUp(ctx, ms)
+			closeConn()
+			if err != nil {
+				logger.Error(err, "migration failed")
+				return
+			}
+		}
+
+		// in-memory history is only filled by the live stream
+		if _, ok := store.(*storage.Memory); ok && conf.EnableHistory {
+			persist = true
+		}
+	}
+
+	if conf.PersistTrades {
+		var closeTrades func()
+		trades, closeTrades, err = openTrades(conf, store)
+		if err != nil {
+			logger.Error(err, "unable to persist trades")
+			return
+		}
+		defer closeTrades()
+	}
+
+	opts, err := aggrOptions(ctx, logger.WithName("checkpoint"), conf, store)
 	if err != nil {
-		logger.Error(err, "unable to connect to db")
+		logger.Error(err, "unable to checkpoint")
 		return
 	}
-	defer conn.Close(ctx)
-	queries := sql.New(conn)
 
 	done := make(chan struct{})
 
 	s, err := server.NewService(
 		*logger,
-		queries,
+		newSource(*logger, conf),
+		opts,
+		store,
+		trades,
 		conf.Symbols,
 		done,
 		conf.EnablePush,
-		conf.EnablePersist,
+		persist,
+		conf.EnableHistory,
+		conf.OnDemandSymbols,
 	)
 	if err != nil {
 		logger.Error(err, "error while creating server")
 		return
 	}
 
+	if depth := newDepth(*logger, conf); depth != nil {
+		bars, err := depth.Stream(done)
+		if err != nil {
+			logger.Error(err, "unable to stream order books")
+			return
+		}
+		s.ServeDepth(done, depth.Symbols(), bars)
+	}
+	if quotes := newQuotes(*logger, conf); quotes != nil {
+		updates, err := quotes.Stream(done)
+		if err != nil {
+			logger.Error(err, "unable to stream quotes")
+			return
+		}
+		if err := s.ServeQuotes(done, quotes.Symbols(), updates, conf.EnablePush, persist); err != nil {
+			logger.Error(err, "unable to serve quotes")
+			return
+		}
+	}
+
 	mux := http.NewServeMux()
 	path, handler := apiv1connect.NewAggrHandler(s)
 	mux.Handle(path, handler)
+	if conf.EnableAdmin {
+		if conf.AdminToken == "" {
+			logger.Info("admin API is enabled without ADMIN_TOKEN")
+		}
+		path, handler := apiv1connect.NewAdminHandler(server.NewAdmin(s, conf.AdminToken))
+		mux.Handle(path, handler)
+	}
+
+	if conf.EnableMetrics {
+		mux.Handle("/debug/vars", expvar.Handler())
+	}
 
 	logger.Info("running...")
 	server := http.Server{
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/jackc/pgx/v5"
+	"github.com/rickliujh/trading-chat-aggr/pkg/migrate"
+	"github.com/rickliujh/trading-chat-aggr/sql/migrations"
+	"github.com/rickliujh/trading-chat-aggr/sql/timescale"
+)
+
+const (
+	ProfileTimescale = "timescale"
+
+	timescaleVersionTable = "schema_migrations_timescale"
+)
+
+var ErrInvalidMigrateArgs = errors.New("usage: server migrate up|down [N]|status")
+
+// runMigrate handles `server migrate up|down [N]|status`, down reverts 1 migration unless N is given
+func runMigrate(ctx context.Context, logger logr.Logger, conf Config, args []string) error {
+	if len(args) == 0 {
+		return ErrInvalidMigrateArgs
+	}
+
+	steps := 1
+	if args[0] == "down" && len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return ErrInvalidMigrateArgs
+		}
+		steps = n
+	}
+
+	ms, closeConn, err := newMigrators(ctx, logger, conf)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	switch args[0] {
+	case "up":
+		return migrateUp(ctx, ms)
+	case "down":
+		// profile migrations sit on top of the base schema, revert them first
+		for i := len(ms) - 1; i >= 0 && steps > 0; i-- {
+			n, err := ms[i].Down(ctx, steps)
+			if err != nil {
+				return err
+			}
+			steps -= n
+		}
+		return nil
+	case "status":
+		for _, m := range ms {
+			st, err := m.Status(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s version: %d, dirty: %t\n", st.Table, st.Version, st.Dirty)
+			for _, mg := range st.Applied {
+				fmt.Printf("applied  %06d_%s\n", mg.Version, mg.Name)
+			}
+			for _, mg := range st.Pending {
+				fmt.Printf("pending  %06d_%s\n", mg.Version, mg.Name)
+			}
+		}
+		return nil
+	default:
+		return ErrInvalidMigrateArgs
+	}
+}
+
+func migrateUp(ctx context.Context, ms []*migrate.Migrator) error {
+	for _, m := range ms {
+		if err := m.Up(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newMigrators opens a dedicated connection, the migration lock lives as long as its session.
+// The base schema comes first, followed by migrations of DB_PROFILE if any
+func newMigrators(ctx context.Context, logger logr.Logger, conf Config) ([]*migrate.Migrator, func(), error) {
+	conn, err := pgx.Connect(ctx, conf.DBURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeConn := func() { conn.Close(context.Background()) }
+
+	base, err := migrate.New(logger, conn, migrations.FS, migrate.VersionTable, false)
+	if err != nil {
+		closeConn()
+		return nil, nil, err
+	}
+	ms := []*migrate.Migrator{base}
+
+	if conf.DBProfile == ProfileTimescale {
+		ts, err := migrate.New(logger.WithName(ProfileTimescale), conn, timescale.FS, timescaleVersionTable, true)
+		if err != nil {
+			closeConn()
+			return nil, nil, err
+		}
+		ms = append(ms, ts)
+	}
+	return ms, closeConn, nil
+}
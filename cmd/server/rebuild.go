@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var ErrTapeNotReadable = errors.New("recorded trades can't be read back from the storage")
+
+// runRebuild handles `server rebuild -symbol S -start T [-end T] [-interval D] [-save]`, it rebuilds bars
+// from recorded trades and prints them as json lines, -save upserts rebuilt 1 minute bars into the bar store.
+// The time range is widened to whole intervals
+func runRebuild(ctx context.Context, logger logr.Logger, conf Config, args []string) error {
+	fs := flag.NewFlagSet("rebuild", flag.ContinueOnError)
+	symbol := fs.String("symbol", "", "symbol to rebuild bars of")
+	startArg := fs.String("start", "", "start of time range in RFC3339")
+	endArg := fs.String("end", "", "end of time range in RFC3339, defaults to now")
+	interval := fs.Duration("interval", tradingchat.Interval1M, "interval of rebuilt bars, a multiple of 1m")
+	save := fs.Bool("save", false, "upsert rebuilt bars into the bar store, interval must be 1m")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	start, err := time.Parse(time.RFC3339, *startArg)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	if *endArg != "" {
+		if end, err = time.Parse(time.RFC3339, *endArg); err != nil {
+			return err
+		}
+	}
+	if *symbol == "" || !start.Before(end) {
+		fs.Usage()
+		return errors.New("symbol and a valid time range are required")
+	}
+	if *save && *interval != tradingchat.Interval1M {
+		return errors.New("only 1m bars can be saved")
+	}
+	start = start.Truncate(*interval)
+	if !end.Truncate(*interval).Equal(end) {
+		end = end.Truncate(*interval).Add(*interval)
+	}
+
+	var store storage.BarStore
+	if *save || conf.TradeTapeDir == "" {
+		var closeStore func()
+		store, closeStore, err = openStore(ctx, conf)
+		if err != nil {
+			return err
+		}
+		defer closeStore()
+	}
+	trades, closeTrades, err := openTrades(conf, store)
+	if err != nil {
+		return err
+	}
+	defer closeTrades()
+	reader, ok := trades.(storage.TradeReader)
+	if !ok {
+		return ErrTapeNotReadable
+	}
+
+	tape, err := reader.ListTrades(ctx, *symbol, start, end)
+	if err != nil {
+		return err
+	}
+	bars, err := tradingchat.RebuildBars(logger, tape, *interval)
+	if err != nil {
+		return err
+	}
+	logger.Info("bars rebuilt", "symbol", *symbol, "start", start, "end", end, "trades", len(tape), "bars", len(bars))
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, bar := range bars {
+		if *save {
+			if err := store.SaveBar(ctx, *symbol, bar); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(bar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/go-logr/logr"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// newSource replays recorded trade files when REPLAY_FILES is set, otherwise streams from binance
+func newSource(logger logr.Logger, conf Config) tradingchat.TradeSource {
+	if len(conf.ReplayFiles) > 0 {
+		return tradingchat.NewReplaySource(
+			logger.WithName("replay"),
+			conf.ReplayFiles,
+			tradingchat.ReplayFormat(conf.ReplayFormat),
+			"",
+			conf.ReplaySpeed,
+		)
+	}
+	return tradingchat.NewBinanceSource(
+		logger.WithName("binance-stream"),
+		conf.Symbols,
+		func(err error) {
+			logger.Error(err, "binance-stream error")
+		},
+	)
+}
+
+// newDepth keeps order books of DEPTH_SYMBOLS from binance diff-depth streams, nil if there are none or trades are replayed
+func newDepth(logger logr.Logger, conf Config) *tradingchat.DepthAggr {
+	symbols := slices.DeleteFunc(slices.Clone(conf.DepthSymbols), func(s string) bool { return s == "" })
+	if len(symbols) == 0 || len(conf.ReplayFiles) > 0 {
+		return nil
+	}
+	return tradingchat.NewDepthAggr(
+		logger.WithName("depth"),
+		symbols,
+		tradingchat.NewRESTSnapshotter(http.DefaultClient, conf.BinanceRESTURL),
+		tradingchat.DepthOptions{Levels: conf.DepthLevels, Interval: conf.DepthInterval},
+	)
+}
+
+// newQuotes builds quote bars of SYMBOLS from binance bookTicker streams when ENABLE_QUOTES is set, nil if trades
+// are replayed
+func newQuotes(logger logr.Logger, conf Config) *tradingchat.QuoteAggr {
+	symbols := slices.DeleteFunc(slices.Clone(conf.Symbols), func(s string) bool { return s == "" })
+	if !conf.EnableQuotes || len(symbols) == 0 || len(conf.ReplayFiles) > 0 {
+		return nil
+	}
+	return tradingchat.NewQuoteAggr(logger.WithName("quotes"), symbols, tradingchat.QuoteOptions{})
+}
+
+// newRecoverer fetches trades missed by the live stream from binance REST API when GAP_RECOVERY is set,
+// replayed files can't be recovered
+func newRecoverer(conf Config) tradingchat.GapRecoverer {
+	if !conf.GapRecovery || len(conf.ReplayFiles) > 0 {
+		return nil
+	}
+	return tradingchat.NewRESTRecoverer(http.DefaultClient, conf.BinanceRESTURL)
+}
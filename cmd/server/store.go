@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	dbsql "database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+)
+
+// openStore opens the bar store DBURI points to, memory:// keeps bars in a ring buffer
+// of HISTORY_SIZE bars per symbol instead of a database, DB_PROFILE=timescale reads
+// longer intervals from TimescaleDB continuous aggregates, clickhouse:// talks to
+// ClickHouse over its native protocol, sqlite://path/to/file.db keeps bars in a SQLite file
+func openStore(ctx context.Context, conf Config) (storage.BarStore, func(), error) {
+	u, err := url.Parse(conf.DBURI)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch u.Scheme {
+	case "memory":
+		return storage.NewMemory(conf.HistorySize), func() {}, nil
+	case "sqlite":
+		db, err := dbsql.Open(storage.SQLiteDriver, strings.TrimPrefix(conf.DBURI, "sqlite://"))
+		if err != nil {
+			return nil, nil, err
+		}
+		lite := storage.NewSQLite(db)
+		if err := lite.Init(ctx); err != nil {
+			lite.Close()
+			return nil, nil, err
+		}
+		return lite, func() { lite.Close() }, nil
+	case "clickhouse":
+		ch, err := storage.OpenClickHouse(conf.DBURI)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := ch.Init(ctx); err != nil {
+			ch.Close()
+			return nil, nil, err
+		}
+		return ch, func() { ch.Close() }, nil
+	case "postgres", "postgresql":
+		// pool dials on first use, so an unreachable db doesn't stop the server from starting
+		pool, err := pgxpool.New(ctx, conf.DBURI)
+		if err != nil {
+			return nil, nil, err
+		}
+		if conf.DBProfile == ProfileTimescale {
+			return storage.NewTimescale(pool), pool.Close, nil
+		}
+		return storage.NewPostgres(sql.New(pool)), pool.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", storage.ErrUnsupportedScheme, u.Scheme)
+	}
+}
+
+func isPostgres(store storage.BarStore) bool {
+	switch store.(type) {
+	case *storage.Postgres, *storage.Timescale:
+		return true
+	}
+	return false
+}
+
+// openTrades opens where trades are recorded, segment files under TRADE_TAPE_DIR if set, otherwise the bar store
+func openTrades(conf Config, store storage.BarStore) (storage.TradeStore, func(), error) {
+	if conf.TradeTapeDir != "" {
+		tape, err := storage.NewFileTape(conf.TradeTapeDir, conf.TapeSegmentSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return tape, func() { tape.Close() }, nil
+	}
+	trades, ok := store.(storage.TradeStore)
+	if !ok {
+		return nil, nil, storage.ErrTradesUnsupported
+	}
+	return trades, func() {}, nil
+}
@@ -9,6 +9,7 @@ package apiv1
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
@@ -21,29 +22,1676 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ActivityBarType int32
+
+const (
+	ActivityBarType_ACTIVITY_BAR_TYPE_UNSPECIFIED ActivityBarType = 0
+	ActivityBarType_ACTIVITY_BAR_TYPE_TICK        ActivityBarType = 1 // every threshold trades
+	ActivityBarType_ACTIVITY_BAR_TYPE_VOLUME      ActivityBarType = 2 // every threshold units of base volume
+	ActivityBarType_ACTIVITY_BAR_TYPE_DOLLAR      ActivityBarType = 3 // every threshold of quote notional
+	ActivityBarType_ACTIVITY_BAR_TYPE_RENKO       ActivityBarType = 4 // bricks of threshold price, reversals take two bricks
+	ActivityBarType_ACTIVITY_BAR_TYPE_RANGE       ActivityBarType = 5 // bars spanning threshold price from high to low
+)
+
+// Enum value maps for ActivityBarType.
+var (
+	ActivityBarType_name = map[int32]string{
+		0: "ACTIVITY_BAR_TYPE_UNSPECIFIED",
+		1: "ACTIVITY_BAR_TYPE_TICK",
+		2: "ACTIVITY_BAR_TYPE_VOLUME",
+		3: "ACTIVITY_BAR_TYPE_DOLLAR",
+		4: "ACTIVITY_BAR_TYPE_RENKO",
+		5: "ACTIVITY_BAR_TYPE_RANGE",
+	}
+	ActivityBarType_value = map[string]int32{
+		"ACTIVITY_BAR_TYPE_UNSPECIFIED": 0,
+		"ACTIVITY_BAR_TYPE_TICK":        1,
+		"ACTIVITY_BAR_TYPE_VOLUME":      2,
+		"ACTIVITY_BAR_TYPE_DOLLAR":      3,
+		"ACTIVITY_BAR_TYPE_RENKO":       4,
+		"ACTIVITY_BAR_TYPE_RANGE":       5,
+	}
+)
+
+func (x ActivityBarType) Enum() *ActivityBarType {
+	p := new(ActivityBarType)
+	*p = x
+	return p
+}
+
+func (x ActivityBarType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ActivityBarType) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_aggregator_proto_enumTypes[0].Descriptor()
+}
+
+func (ActivityBarType) Type() protoreflect.EnumType {
+	return &file_api_v1_aggregator_proto_enumTypes[0]
+}
+
+func (x ActivityBarType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ActivityBarType.Descriptor instead.
+func (ActivityBarType) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{0}
+}
+
+type IndicatorKind int32
+
+const (
+	IndicatorKind_INDICATOR_KIND_UNSPECIFIED IndicatorKind = 0
+	IndicatorKind_INDICATOR_KIND_SMA         IndicatorKind = 1
+	IndicatorKind_INDICATOR_KIND_EMA         IndicatorKind = 2
+	IndicatorKind_INDICATOR_KIND_RSI         IndicatorKind = 3
+	IndicatorKind_INDICATOR_KIND_MACD        IndicatorKind = 4
+	IndicatorKind_INDICATOR_KIND_BOLLINGER   IndicatorKind = 5
+)
+
+// Enum value maps for IndicatorKind.
+var (
+	IndicatorKind_name = map[int32]string{
+		0: "INDICATOR_KIND_UNSPECIFIED",
+		1: "INDICATOR_KIND_SMA",
+		2: "INDICATOR_KIND_EMA",
+		3: "INDICATOR_KIND_RSI",
+		4: "INDICATOR_KIND_MACD",
+		5: "INDICATOR_KIND_BOLLINGER",
+	}
+	IndicatorKind_value = map[string]int32{
+		"INDICATOR_KIND_UNSPECIFIED": 0,
+		"INDICATOR_KIND_SMA":         1,
+		"INDICATOR_KIND_EMA":         2,
+		"INDICATOR_KIND_RSI":         3,
+		"INDICATOR_KIND_MACD":        4,
+		"INDICATOR_KIND_BOLLINGER":   5,
+	}
+)
+
+func (x IndicatorKind) Enum() *IndicatorKind {
+	p := new(IndicatorKind)
+	*p = x
+	return p
+}
+
+func (x IndicatorKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (IndicatorKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_aggregator_proto_enumTypes[1].Descriptor()
+}
+
+func (IndicatorKind) Type() protoreflect.EnumType {
+	return &file_api_v1_aggregator_proto_enumTypes[1]
+}
+
+func (x IndicatorKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use IndicatorKind.Descriptor instead.
+func (IndicatorKind) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{1}
+}
+
+type FeedStatus int32
+
+const (
+	FeedStatus_FEED_STATUS_UNSPECIFIED FeedStatus = 0
+	FeedStatus_FEED_STATUS_STALE       FeedStatus = 1 // the symbol didn't trade for much longer than it usually does
+	FeedStatus_FEED_STATUS_LIVE        FeedStatus = 2 // the stale symbol traded again
+	FeedStatus_FEED_STATUS_SPIKE       FeedStatus = 3 // the price jumped too many standard deviations of recent returns
+	FeedStatus_FEED_STATUS_STALLED     FeedStatus = 4 // no trade of any symbol arrived for a while
+	FeedStatus_FEED_STATUS_RESUMED     FeedStatus = 5 // trades arrive again after a stall
+)
+
+// Enum value maps for FeedStatus.
+var (
+	FeedStatus_name = map[int32]string{
+		0: "FEED_STATUS_UNSPECIFIED",
+		1: "FEED_STATUS_STALE",
+		2: "FEED_STATUS_LIVE",
+		3: "FEED_STATUS_SPIKE",
+		4: "FEED_STATUS_STALLED",
+		5: "FEED_STATUS_RESUMED",
+	}
+	FeedStatus_value = map[string]int32{
+		"FEED_STATUS_UNSPECIFIED": 0,
+		"FEED_STATUS_STALE":       1,
+		"FEED_STATUS_LIVE":        2,
+		"FEED_STATUS_SPIKE":       3,
+		"FEED_STATUS_STALLED":     4,
+		"FEED_STATUS_RESUMED":     5,
+	}
+)
+
+func (x FeedStatus) Enum() *FeedStatus {
+	p := new(FeedStatus)
+	*p = x
+	return p
+}
+
+func (x FeedStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FeedStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_aggregator_proto_enumTypes[2].Descriptor()
+}
+
+func (FeedStatus) Type() protoreflect.EnumType {
+	return &file_api_v1_aggregator_proto_enumTypes[2]
+}
+
+func (x FeedStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FeedStatus.Descriptor instead.
+func (FeedStatus) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{2}
+}
+
+type Interval int32
+
+const (
+	Interval_INTERVAL_UNSPECIFIED Interval = 0 // same as INTERVAL_1M
+	Interval_INTERVAL_1M          Interval = 1
+	Interval_INTERVAL_5M          Interval = 2
+	Interval_INTERVAL_1H          Interval = 3
+	Interval_INTERVAL_1D          Interval = 4
+)
+
+// Enum value maps for Interval.
+var (
+	Interval_name = map[int32]string{
+		0: "INTERVAL_UNSPECIFIED",
+		1: "INTERVAL_1M",
+		2: "INTERVAL_5M",
+		3: "INTERVAL_1H",
+		4: "INTERVAL_1D",
+	}
+	Interval_value = map[string]int32{
+		"INTERVAL_UNSPECIFIED": 0,
+		"INTERVAL_1M":          1,
+		"INTERVAL_5M":          2,
+		"INTERVAL_1H":          3,
+		"INTERVAL_1D":          4,
+	}
+)
+
+func (x Interval) Enum() *Interval {
+	p := new(Interval)
+	*p = x
+	return p
+}
+
+func (x Interval) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Interval) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_aggregator_proto_enumTypes[3].Descriptor()
+}
+
+func (Interval) Type() protoreflect.EnumType {
+	return &file_api_v1_aggregator_proto_enumTypes[3]
+}
+
+func (x Interval) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Interval.Descriptor instead.
+func (Interval) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3}
+}
+
+type AlertKind int32
+
+const (
+	AlertKind_ALERT_KIND_UNSPECIFIED  AlertKind = 0
+	AlertKind_ALERT_KIND_CROSS_ABOVE  AlertKind = 1 // close crosses level upwards
+	AlertKind_ALERT_KIND_CROSS_BELOW  AlertKind = 2 // close crosses level downwards
+	AlertKind_ALERT_KIND_MOVE         AlertKind = 3 // close is level percent or more away from the open of its 1 minute bar
+	AlertKind_ALERT_KIND_VOLUME_SPIKE AlertKind = 4 // volume of the 1 minute bar is level times the average of the last lookback bars or more
+)
+
+// Enum value maps for AlertKind.
+var (
+	AlertKind_name = map[int32]string{
+		0: "ALERT_KIND_UNSPECIFIED",
+		1: "ALERT_KIND_CROSS_ABOVE",
+		2: "ALERT_KIND_CROSS_BELOW",
+		3: "ALERT_KIND_MOVE",
+		4: "ALERT_KIND_VOLUME_SPIKE",
+	}
+	AlertKind_value = map[string]int32{
+		"ALERT_KIND_UNSPECIFIED":  0,
+		"ALERT_KIND_CROSS_ABOVE":  1,
+		"ALERT_KIND_CROSS_BELOW":  2,
+		"ALERT_KIND_MOVE":         3,
+		"ALERT_KIND_VOLUME_SPIKE": 4,
+	}
+)
+
+func (x AlertKind) Enum() *AlertKind {
+	p := new(AlertKind)
+	*p = x
+	return p
+}
+
+func (x AlertKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AlertKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_v1_aggregator_proto_enumTypes[4].Descriptor()
+}
+
+func (AlertKind) Type() protoreflect.EnumType {
+	return &file_api_v1_aggregator_proto_enumTypes[4]
+}
+
+func (x AlertKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AlertKind.Descriptor instead.
+func (AlertKind) EnumDescriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{4}
+}
+
 type Candlesticks1MStreamRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	RequestId string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Symbols   []string               `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	// indicators computed on bars of every subscribed symbol, they replace indicators of earlier requests if any
+	Indicators []*IndicatorSpec `protobuf:"bytes,3,rep,name=indicators,proto3" json:"indicators,omitempty"`
+	// updates are Heikin-Ashi bars, only the first request of a stream sets it
+	HeikinAshi bool `protobuf:"varint,4,opt,name=heikin_ashi,json=heikinAshi,proto3" json:"heikin_ashi,omitempty"`
+	// bars closed by trading activity of every subscribed symbol, they replace activity bars of earlier requests if any
+	ActivityBars []*ActivityBarSpec `protobuf:"bytes,5,rep,name=activity_bars,json=activityBars,proto3" json:"activity_bars,omitempty"`
+	// quote bars of best bids and asks of the subscribed symbols are sent as well, only the first request of a stream sets it.
+	// Quote bars are only built for symbols registered on start, streams asking for them can't subscribe to others
+	Quotes        bool `protobuf:"varint,6,opt,name=quotes,proto3" json:"quotes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candlesticks1MStreamRequest) Reset() {
+	*x = Candlesticks1MStreamRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candlesticks1MStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candlesticks1MStreamRequest) ProtoMessage() {}
+
+func (x *Candlesticks1MStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candlesticks1MStreamRequest.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Candlesticks1MStreamRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamRequest) GetIndicators() []*IndicatorSpec {
+	if x != nil {
+		return x.Indicators
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamRequest) GetHeikinAshi() bool {
+	if x != nil {
+		return x.HeikinAshi
+	}
+	return false
+}
+
+func (x *Candlesticks1MStreamRequest) GetActivityBars() []*ActivityBarSpec {
+	if x != nil {
+		return x.ActivityBars
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamRequest) GetQuotes() bool {
+	if x != nil {
+		return x.Quotes
+	}
+	return false
+}
+
+type ActivityBarSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          ActivityBarType        `protobuf:"varint,1,opt,name=type,proto3,enum=svc.api.v1.ActivityBarType" json:"type,omitempty"`
+	Threshold     float64                `protobuf:"fixed64,2,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActivityBarSpec) Reset() {
+	*x = ActivityBarSpec{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActivityBarSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActivityBarSpec) ProtoMessage() {}
+
+func (x *ActivityBarSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActivityBarSpec.ProtoReflect.Descriptor instead.
+func (*ActivityBarSpec) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ActivityBarSpec) GetType() ActivityBarType {
+	if x != nil {
+		return x.Type
+	}
+	return ActivityBarType_ACTIVITY_BAR_TYPE_UNSPECIFIED
+}
+
+func (x *ActivityBarSpec) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+type IndicatorSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          IndicatorKind          `protobuf:"varint,1,opt,name=kind,proto3,enum=svc.api.v1.IndicatorKind" json:"kind,omitempty"`
+	Interval      Interval               `protobuf:"varint,2,opt,name=interval,proto3,enum=svc.api.v1.Interval" json:"interval,omitempty"`
+	Period        int32                  `protobuf:"varint,3,opt,name=period,proto3" json:"period,omitempty"` // SMA, EMA, RSI and Bollinger
+	Fast          int32                  `protobuf:"varint,4,opt,name=fast,proto3" json:"fast,omitempty"`     // MACD, 12 by default
+	Slow          int32                  `protobuf:"varint,5,opt,name=slow,proto3" json:"slow,omitempty"`     // MACD, 26 by default
+	Signal        int32                  `protobuf:"varint,6,opt,name=signal,proto3" json:"signal,omitempty"` // MACD, 9 by default
+	K             float64                `protobuf:"fixed64,7,opt,name=k,proto3" json:"k,omitempty"`          // width of Bollinger bands in standard deviations, 2 by default
+	Live          bool                   `protobuf:"varint,8,opt,name=live,proto3" json:"live,omitempty"`     // also computed on bars in progress
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndicatorSpec) Reset() {
+	*x = IndicatorSpec{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndicatorSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndicatorSpec) ProtoMessage() {}
+
+func (x *IndicatorSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndicatorSpec.ProtoReflect.Descriptor instead.
+func (*IndicatorSpec) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IndicatorSpec) GetKind() IndicatorKind {
+	if x != nil {
+		return x.Kind
+	}
+	return IndicatorKind_INDICATOR_KIND_UNSPECIFIED
+}
+
+func (x *IndicatorSpec) GetInterval() Interval {
+	if x != nil {
+		return x.Interval
+	}
+	return Interval_INTERVAL_UNSPECIFIED
+}
+
+func (x *IndicatorSpec) GetPeriod() int32 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *IndicatorSpec) GetFast() int32 {
+	if x != nil {
+		return x.Fast
+	}
+	return 0
+}
+
+func (x *IndicatorSpec) GetSlow() int32 {
+	if x != nil {
+		return x.Slow
+	}
+	return 0
+}
+
+func (x *IndicatorSpec) GetSignal() int32 {
+	if x != nil {
+		return x.Signal
+	}
+	return 0
+}
+
+func (x *IndicatorSpec) GetK() float64 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *IndicatorSpec) GetLive() bool {
+	if x != nil {
+		return x.Live
+	}
+	return false
+}
+
+type Candlesticks1MStreamResponse struct {
+	state         protoimpl.MessageState                    `protogen:"open.v1"`
+	Update        *Candlesticks1MStreamResponse_Bar         `protobuf:"bytes,1,opt,name=update,proto3" json:"update,omitempty"`
+	Gap           *Candlesticks1MStreamResponse_Gap         `protobuf:"bytes,2,opt,name=gap,proto3" json:"gap,omitempty"`
+	Indicators    []*Candlesticks1MStreamResponse_Indicator `protobuf:"bytes,3,rep,name=indicators,proto3" json:"indicators,omitempty"`
+	ActivityBar   *Candlesticks1MStreamResponse_ActivityBar `protobuf:"bytes,4,opt,name=activity_bar,json=activityBar,proto3" json:"activity_bar,omitempty"`
+	Status        *Candlesticks1MStreamResponse_Status      `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	QuoteBar      *Candlesticks1MStreamResponse_QuoteBar    `protobuf:"bytes,6,opt,name=quote_bar,json=quoteBar,proto3" json:"quote_bar,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candlesticks1MStreamResponse) Reset() {
+	*x = Candlesticks1MStreamResponse{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candlesticks1MStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candlesticks1MStreamResponse) ProtoMessage() {}
+
+func (x *Candlesticks1MStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candlesticks1MStreamResponse.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Candlesticks1MStreamResponse) GetUpdate() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse) GetGap() *Candlesticks1MStreamResponse_Gap {
+	if x != nil {
+		return x.Gap
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse) GetIndicators() []*Candlesticks1MStreamResponse_Indicator {
+	if x != nil {
+		return x.Indicators
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse) GetActivityBar() *Candlesticks1MStreamResponse_ActivityBar {
+	if x != nil {
+		return x.ActivityBar
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse) GetStatus() *Candlesticks1MStreamResponse_Status {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse) GetQuoteBar() *Candlesticks1MStreamResponse_QuoteBar {
+	if x != nil {
+		return x.QuoteBar
+	}
+	return nil
+}
+
+type CandlesticksHistoryRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Symbol     string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Start      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Interval   Interval               `protobuf:"varint,4,opt,name=interval,proto3,enum=svc.api.v1.Interval" json:"interval,omitempty"`
+	HeikinAshi bool                   `protobuf:"varint,5,opt,name=heikin_ashi,json=heikinAshi,proto3" json:"heikin_ashi,omitempty"` // bars are transformed into Heikin-Ashi bars
+	Quotes     bool                   `protobuf:"varint,6,opt,name=quotes,proto3" json:"quotes,omitempty"`                           // quote bars of the interval are returned in quote_bars instead
+	// closed activity bars of the spec that closed within the range are returned in activity_bars instead, interval is ignored
+	ActivityBar   *ActivityBarSpec `protobuf:"bytes,7,opt,name=activity_bar,json=activityBar,proto3" json:"activity_bar,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandlesticksHistoryRequest) Reset() {
+	*x = CandlesticksHistoryRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandlesticksHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandlesticksHistoryRequest) ProtoMessage() {}
+
+func (x *CandlesticksHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandlesticksHistoryRequest.ProtoReflect.Descriptor instead.
+func (*CandlesticksHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CandlesticksHistoryRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *CandlesticksHistoryRequest) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *CandlesticksHistoryRequest) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *CandlesticksHistoryRequest) GetInterval() Interval {
+	if x != nil {
+		return x.Interval
+	}
+	return Interval_INTERVAL_UNSPECIFIED
+}
+
+func (x *CandlesticksHistoryRequest) GetHeikinAshi() bool {
+	if x != nil {
+		return x.HeikinAshi
+	}
+	return false
+}
+
+func (x *CandlesticksHistoryRequest) GetQuotes() bool {
+	if x != nil {
+		return x.Quotes
+	}
+	return false
+}
+
+func (x *CandlesticksHistoryRequest) GetActivityBar() *ActivityBarSpec {
+	if x != nil {
+		return x.ActivityBar
+	}
+	return nil
+}
+
+type CandlesticksHistoryResponse struct {
+	state         protoimpl.MessageState                      `protogen:"open.v1"`
+	Bars          []*Candlesticks1MStreamResponse_Bar         `protobuf:"bytes,1,rep,name=bars,proto3" json:"bars,omitempty"`
+	QuoteBars     []*Candlesticks1MStreamResponse_QuoteBar    `protobuf:"bytes,2,rep,name=quote_bars,json=quoteBars,proto3" json:"quote_bars,omitempty"`
+	ActivityBars  []*Candlesticks1MStreamResponse_ActivityBar `protobuf:"bytes,3,rep,name=activity_bars,json=activityBars,proto3" json:"activity_bars,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CandlesticksHistoryResponse) Reset() {
+	*x = CandlesticksHistoryResponse{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandlesticksHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandlesticksHistoryResponse) ProtoMessage() {}
+
+func (x *CandlesticksHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandlesticksHistoryResponse.ProtoReflect.Descriptor instead.
+func (*CandlesticksHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CandlesticksHistoryResponse) GetBars() []*Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Bars
+	}
+	return nil
+}
+
+func (x *CandlesticksHistoryResponse) GetQuoteBars() []*Candlesticks1MStreamResponse_QuoteBar {
+	if x != nil {
+		return x.QuoteBars
+	}
+	return nil
+}
+
+func (x *CandlesticksHistoryResponse) GetActivityBars() []*Candlesticks1MStreamResponse_ActivityBar {
+	if x != nil {
+		return x.ActivityBars
+	}
+	return nil
+}
+
+type AlertSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Kind          AlertKind              `protobuf:"varint,2,opt,name=kind,proto3,enum=svc.api.v1.AlertKind" json:"kind,omitempty"`
+	Level         float64                `protobuf:"fixed64,3,opt,name=level,proto3" json:"level,omitempty"`
+	Lookback      int32                  `protobuf:"varint,4,opt,name=lookback,proto3" json:"lookback,omitempty"` // volume spikes only, 20 by default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertSpec) Reset() {
+	*x = AlertSpec{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertSpec) ProtoMessage() {}
+
+func (x *AlertSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertSpec.ProtoReflect.Descriptor instead.
+func (*AlertSpec) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AlertSpec) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *AlertSpec) GetKind() AlertKind {
+	if x != nil {
+		return x.Kind
+	}
+	return AlertKind_ALERT_KIND_UNSPECIFIED
+}
+
+func (x *AlertSpec) GetLevel() float64 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *AlertSpec) GetLookback() int32 {
+	if x != nil {
+		return x.Lookback
+	}
+	return 0
+}
+
+type AlertsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// alerts belong to the request id, a later stream of the same id gets them back along with events fired meanwhile
+	RequestId string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// Types that are valid to be assigned to Action:
+	//
+	//	*AlertsRequest_Add
+	//	*AlertsRequest_Cancel
+	//	*AlertsRequest_List
+	Action        isAlertsRequest_Action `protobuf_oneof:"action"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertsRequest) Reset() {
+	*x = AlertsRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertsRequest) ProtoMessage() {}
+
+func (x *AlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertsRequest.ProtoReflect.Descriptor instead.
+func (*AlertsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AlertsRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *AlertsRequest) GetAction() isAlertsRequest_Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *AlertsRequest) GetAdd() *AlertSpec {
+	if x != nil {
+		if x, ok := x.Action.(*AlertsRequest_Add); ok {
+			return x.Add
+		}
+	}
+	return nil
+}
+
+func (x *AlertsRequest) GetCancel() string {
+	if x != nil {
+		if x, ok := x.Action.(*AlertsRequest_Cancel); ok {
+			return x.Cancel
+		}
+	}
+	return ""
+}
+
+func (x *AlertsRequest) GetList() bool {
+	if x != nil {
+		if x, ok := x.Action.(*AlertsRequest_List); ok {
+			return x.List
+		}
+	}
+	return false
+}
+
+type isAlertsRequest_Action interface {
+	isAlertsRequest_Action()
+}
+
+type AlertsRequest_Add struct {
+	Add *AlertSpec `protobuf:"bytes,2,opt,name=add,proto3,oneof"`
+}
+
+type AlertsRequest_Cancel struct {
+	Cancel string `protobuf:"bytes,3,opt,name=cancel,proto3,oneof"` // id of the alert
+}
+
+type AlertsRequest_List struct {
+	List bool `protobuf:"varint,4,opt,name=list,proto3,oneof"`
+}
+
+func (*AlertsRequest_Add) isAlertsRequest_Action() {}
+
+func (*AlertsRequest_Cancel) isAlertsRequest_Action() {}
+
+func (*AlertsRequest_List) isAlertsRequest_Action() {}
+
+type AlertsResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Added         *AlertsResponse_Alert   `protobuf:"bytes,1,opt,name=added,proto3" json:"added,omitempty"`
+	Cancelled     string                  `protobuf:"bytes,2,opt,name=cancelled,proto3" json:"cancelled,omitempty"`
+	Alerts        []*AlertsResponse_Alert `protobuf:"bytes,3,rep,name=alerts,proto3" json:"alerts,omitempty"` // answer of list
+	Fired         *AlertsResponse_Fired   `protobuf:"bytes,4,opt,name=fired,proto3" json:"fired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertsResponse) Reset() {
+	*x = AlertsResponse{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertsResponse) ProtoMessage() {}
+
+func (x *AlertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertsResponse.ProtoReflect.Descriptor instead.
+func (*AlertsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AlertsResponse) GetAdded() *AlertsResponse_Alert {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *AlertsResponse) GetCancelled() string {
+	if x != nil {
+		return x.Cancelled
+	}
+	return ""
+}
+
+func (x *AlertsResponse) GetAlerts() []*AlertsResponse_Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+func (x *AlertsResponse) GetFired() *AlertsResponse_Fired {
+	if x != nil {
+		return x.Fired
+	}
+	return nil
+}
+
+type OrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbols       []string               `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookRequest) Reset() {
+	*x = OrderBookRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookRequest) ProtoMessage() {}
+
+func (x *OrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookRequest.ProtoReflect.Descriptor instead.
+func (*OrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OrderBookRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type OrderBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bar           *OrderBookResponse_Bar `protobuf:"bytes,1,opt,name=bar,proto3" json:"bar,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookResponse) Reset() {
+	*x = OrderBookResponse{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookResponse) ProtoMessage() {}
+
+func (x *OrderBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookResponse.ProtoReflect.Descriptor instead.
+func (*OrderBookResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OrderBookResponse) GetBar() *OrderBookResponse_Bar {
+	if x != nil {
+		return x.Bar
+	}
+	return nil
+}
+
+type AddSymbolsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbols       []string               `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddSymbolsRequest) Reset() {
+	*x = AddSymbolsRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddSymbolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddSymbolsRequest) ProtoMessage() {}
+
+func (x *AddSymbolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddSymbolsRequest.ProtoReflect.Descriptor instead.
+func (*AddSymbolsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AddSymbolsRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type RemoveSymbolsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbols       []string               `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveSymbolsRequest) Reset() {
+	*x = RemoveSymbolsRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveSymbolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveSymbolsRequest) ProtoMessage() {}
+
+func (x *RemoveSymbolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveSymbolsRequest.ProtoReflect.Descriptor instead.
+func (*RemoveSymbolsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RemoveSymbolsRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type ListSymbolsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSymbolsRequest) Reset() {
+	*x = ListSymbolsRequest{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSymbolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSymbolsRequest) ProtoMessage() {}
+
+func (x *ListSymbolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSymbolsRequest.ProtoReflect.Descriptor instead.
+func (*ListSymbolsRequest) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{13}
+}
+
+type SymbolsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbols       []string               `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"` // every symbol registered after the request
+	Changed       []string               `protobuf:"bytes,2,rep,name=changed,proto3" json:"changed,omitempty"` // symbols the request added or removed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SymbolsResponse) Reset() {
+	*x = SymbolsResponse{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SymbolsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SymbolsResponse) ProtoMessage() {}
+
+func (x *SymbolsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SymbolsResponse.ProtoReflect.Descriptor instead.
+func (*SymbolsResponse) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SymbolsResponse) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *SymbolsResponse) GetChanged() []string {
+	if x != nil {
+		return x.Changed
+	}
+	return nil
+}
+
+type Candlesticks1MStreamResponse_Bar struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	High          string                 `protobuf:"bytes,1,opt,name=High,proto3" json:"High,omitempty"`
+	Low           string                 `protobuf:"bytes,2,opt,name=Low,proto3" json:"Low,omitempty"`
+	Open          string                 `protobuf:"bytes,3,opt,name=Open,proto3" json:"Open,omitempty"`
+	Close         string                 `protobuf:"bytes,4,opt,name=Close,proto3" json:"Close,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=UpdatedAt,proto3" json:"UpdatedAt,omitempty"`
+	Incomplete    bool                   `protobuf:"varint,6,opt,name=Incomplete,proto3" json:"Incomplete,omitempty"` // some trades of the bar never arrived from the feed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) Reset() {
+	*x = Candlesticks1MStreamResponse_Bar{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candlesticks1MStreamResponse_Bar) ProtoMessage() {}
+
+func (x *Candlesticks1MStreamResponse_Bar) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candlesticks1MStreamResponse_Bar.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse_Bar) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) GetHigh() string {
+	if x != nil {
+		return x.High
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) GetLow() string {
+	if x != nil {
+		return x.Low
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) GetClose() string {
+	if x != nil {
+		return x.Close
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Bar) GetIncomplete() bool {
+	if x != nil {
+		return x.Incomplete
+	}
+	return false
+}
+
+// Gap is a range of aggregated trade ids missed by the feed
+type Candlesticks1MStreamResponse_Gap struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	FromId        int64                  `protobuf:"varint,2,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	ToId          int64                  `protobuf:"varint,3,opt,name=to_id,json=toId,proto3" json:"to_id,omitempty"`
+	Start         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start,proto3" json:"start,omitempty"`
+	End           *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=end,proto3" json:"end,omitempty"`
+	Recovered     bool                   `protobuf:"varint,6,opt,name=recovered,proto3" json:"recovered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) Reset() {
+	*x = Candlesticks1MStreamResponse_Gap{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candlesticks1MStreamResponse_Gap) ProtoMessage() {}
+
+func (x *Candlesticks1MStreamResponse_Gap) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candlesticks1MStreamResponse_Gap.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse_Gap) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) GetFromId() int64 {
+	if x != nil {
+		return x.FromId
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) GetToId() int64 {
+	if x != nil {
+		return x.ToId
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Gap) GetRecovered() bool {
+	if x != nil {
+		return x.Recovered
+	}
+	return false
+}
+
+// Indicator is the value of an indicator at the close of a bar of its interval
+type Candlesticks1MStreamResponse_Indicator struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // e.g. sma_20_1m
+	OpenTime      *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"`
+	Closed        bool                   `protobuf:"varint,3,opt,name=closed,proto3" json:"closed,omitempty"` // false if the bar is in progress
+	Ready         bool                   `protobuf:"varint,4,opt,name=ready,proto3" json:"ready,omitempty"`   // false until enough bars were seen
+	Values        map[string]float64     `protobuf:"bytes,5,rep,name=values,proto3" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) Reset() {
+	*x = Candlesticks1MStreamResponse_Indicator{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candlesticks1MStreamResponse_Indicator) ProtoMessage() {}
+
+func (x *Candlesticks1MStreamResponse_Indicator) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candlesticks1MStreamResponse_Indicator.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse_Indicator) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3, 2}
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) GetOpenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OpenTime
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+func (x *Candlesticks1MStreamResponse_Indicator) GetValues() map[string]float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// ActivityBar is a bar closed by trading activity, its UpdatedAt is the time of its last trade
+type Candlesticks1MStreamResponse_ActivityBar struct {
+	state         protoimpl.MessageState            `protogen:"open.v1"`
+	Symbol        string                            `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Name          string                            `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"` // e.g. volume_50
+	Bar           *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,3,opt,name=bar,proto3" json:"bar,omitempty"`
+	OpenTime      *timestamppb.Timestamp            `protobuf:"bytes,4,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"` // time of its first trade
+	FirstId       int64                             `protobuf:"varint,5,opt,name=first_id,json=firstId,proto3" json:"first_id,omitempty"`
+	LastId        int64                             `protobuf:"varint,6,opt,name=last_id,json=lastId,proto3" json:"last_id,omitempty"`
+	Trades        int64                             `protobuf:"varint,7,opt,name=trades,proto3" json:"trades,omitempty"`
+	Volume        float64                           `protobuf:"fixed64,8,opt,name=volume,proto3" json:"volume,omitempty"`
+	Notional      float64                           `protobuf:"fixed64,9,opt,name=notional,proto3" json:"notional,omitempty"`
+	Closed        bool                              `protobuf:"varint,10,opt,name=closed,proto3" json:"closed,omitempty"`
+	Seq           int64                             `protobuf:"varint,11,opt,name=seq,proto3" json:"seq,omitempty"` // bars closed by one trade share first_id, and are ordered by seq
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) Reset() {
+	*x = Candlesticks1MStreamResponse_ActivityBar{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Candlesticks1MStreamResponse_ActivityBar) ProtoMessage() {}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Candlesticks1MStreamResponse_ActivityBar.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse_ActivityBar) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3, 3}
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetBar() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Bar
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetOpenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OpenTime
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetFirstId() int64 {
+	if x != nil {
+		return x.FirstId
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetLastId() int64 {
+	if x != nil {
+		return x.LastId
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetTrades() int64 {
+	if x != nil {
+		return x.Trades
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetVolume() float64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetNotional() float64 {
+	if x != nil {
+		return x.Notional
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+func (x *Candlesticks1MStreamResponse_ActivityBar) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// Status is a change of the health of the feed, symbol is empty when the whole feed stalled or resumed
+type Candlesticks1MStreamResponse_Status struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	Symbols       []string               `protobuf:"bytes,2,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Kind          FeedStatus             `protobuf:"varint,2,opt,name=kind,proto3,enum=svc.api.v1.FeedStatus" json:"kind,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=at,proto3" json:"at,omitempty"`
+	LastTrade     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_trade,json=lastTrade,proto3" json:"last_trade,omitempty"` // arrival of the last trade before the change
+	Silence       *durationpb.Duration   `protobuf:"bytes,5,opt,name=silence,proto3" json:"silence,omitempty"`                      // time without trades
+	Expected      *durationpb.Duration   `protobuf:"bytes,6,opt,name=expected,proto3" json:"expected,omitempty"`                    // time without trades the symbol is stale after
+	Price         string                 `protobuf:"bytes,7,opt,name=price,proto3" json:"price,omitempty"`                          // price of a spike
+	Deviation     float64                `protobuf:"fixed64,8,opt,name=deviation,proto3" json:"deviation,omitempty"`                // standard deviations of a spike
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Candlesticks1MStreamRequest) Reset() {
-	*x = Candlesticks1MStreamRequest{}
-	mi := &file_api_v1_aggregator_proto_msgTypes[0]
+func (x *Candlesticks1MStreamResponse_Status) Reset() {
+	*x = Candlesticks1MStreamResponse_Status{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Candlesticks1MStreamRequest) String() string {
+func (x *Candlesticks1MStreamResponse_Status) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Candlesticks1MStreamRequest) ProtoMessage() {}
+func (*Candlesticks1MStreamResponse_Status) ProtoMessage() {}
 
-func (x *Candlesticks1MStreamRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_aggregator_proto_msgTypes[0]
+func (x *Candlesticks1MStreamResponse_Status) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -54,47 +1702,96 @@ func (x *Candlesticks1MStreamRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Candlesticks1MStreamRequest.ProtoReflect.Descriptor instead.
-func (*Candlesticks1MStreamRequest) Descriptor() ([]byte, []int) {
-	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use Candlesticks1MStreamResponse_Status.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse_Status) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3, 4}
 }
 
-func (x *Candlesticks1MStreamRequest) GetRequestId() string {
+func (x *Candlesticks1MStreamResponse_Status) GetSymbol() string {
 	if x != nil {
-		return x.RequestId
+		return x.Symbol
 	}
 	return ""
 }
 
-func (x *Candlesticks1MStreamRequest) GetSymbols() []string {
+func (x *Candlesticks1MStreamResponse_Status) GetKind() FeedStatus {
 	if x != nil {
-		return x.Symbols
+		return x.Kind
+	}
+	return FeedStatus_FEED_STATUS_UNSPECIFIED
+}
+
+func (x *Candlesticks1MStreamResponse_Status) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
 	}
 	return nil
 }
 
-type Candlesticks1MStreamResponse struct {
+func (x *Candlesticks1MStreamResponse_Status) GetLastTrade() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastTrade
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Status) GetSilence() *durationpb.Duration {
+	if x != nil {
+		return x.Silence
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Status) GetExpected() *durationpb.Duration {
+	if x != nil {
+		return x.Expected
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_Status) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_Status) GetDeviation() float64 {
+	if x != nil {
+		return x.Deviation
+	}
+	return 0
+}
+
+// QuoteBar is the best bid and ask of a symbol over a minute, UpdatedAt of its bars is the time of the latest quote
+type Candlesticks1MStreamResponse_QuoteBar struct {
 	state         protoimpl.MessageState            `protogen:"open.v1"`
-	Update        *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,1,opt,name=update,proto3" json:"update,omitempty"`
+	Symbol        string                            `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	OpenTime      *timestamppb.Timestamp            `protobuf:"bytes,2,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"`
+	Bid           *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,3,opt,name=bid,proto3" json:"bid,omitempty"`
+	Ask           *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,4,opt,name=ask,proto3" json:"ask,omitempty"`
+	MeanSpread    float64                           `protobuf:"fixed64,5,opt,name=mean_spread,json=meanSpread,proto3" json:"mean_spread,omitempty"` // weighted by the time each spread lasted
+	Quotes        int64                             `protobuf:"varint,6,opt,name=quotes,proto3" json:"quotes,omitempty"`
+	Closed        bool                              `protobuf:"varint,7,opt,name=closed,proto3" json:"closed,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Candlesticks1MStreamResponse) Reset() {
-	*x = Candlesticks1MStreamResponse{}
-	mi := &file_api_v1_aggregator_proto_msgTypes[1]
+func (x *Candlesticks1MStreamResponse_QuoteBar) Reset() {
+	*x = Candlesticks1MStreamResponse_QuoteBar{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Candlesticks1MStreamResponse) String() string {
+func (x *Candlesticks1MStreamResponse_QuoteBar) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Candlesticks1MStreamResponse) ProtoMessage() {}
+func (*Candlesticks1MStreamResponse_QuoteBar) ProtoMessage() {}
 
-func (x *Candlesticks1MStreamResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_aggregator_proto_msgTypes[1]
+func (x *Candlesticks1MStreamResponse_QuoteBar) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -105,44 +1802,86 @@ func (x *Candlesticks1MStreamResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Candlesticks1MStreamResponse.ProtoReflect.Descriptor instead.
-func (*Candlesticks1MStreamResponse) Descriptor() ([]byte, []int) {
-	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use Candlesticks1MStreamResponse_QuoteBar.ProtoReflect.Descriptor instead.
+func (*Candlesticks1MStreamResponse_QuoteBar) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{3, 5}
 }
 
-func (x *Candlesticks1MStreamResponse) GetUpdate() *Candlesticks1MStreamResponse_Bar {
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetSymbol() string {
 	if x != nil {
-		return x.Update
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetOpenTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OpenTime
 	}
 	return nil
 }
 
-type Candlesticks1MStreamResponse_Bar struct {
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetBid() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Bid
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetAsk() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Ask
+	}
+	return nil
+}
+
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetMeanSpread() float64 {
+	if x != nil {
+		return x.MeanSpread
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetQuotes() int64 {
+	if x != nil {
+		return x.Quotes
+	}
+	return 0
+}
+
+func (x *Candlesticks1MStreamResponse_QuoteBar) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+type AlertsResponse_Alert struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	High          string                 `protobuf:"bytes,1,opt,name=High,proto3" json:"High,omitempty"`
-	Low           string                 `protobuf:"bytes,2,opt,name=Low,proto3" json:"Low,omitempty"`
-	Open          string                 `protobuf:"bytes,3,opt,name=Open,proto3" json:"Open,omitempty"`
-	Close         string                 `protobuf:"bytes,4,opt,name=Close,proto3" json:"Close,omitempty"`
-	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=UpdatedAt,proto3" json:"UpdatedAt,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Spec          *AlertSpec             `protobuf:"bytes,2,opt,name=spec,proto3" json:"spec,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Fired         int64                  `protobuf:"varint,4,opt,name=fired,proto3" json:"fired,omitempty"`
+	LastFired     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_fired,json=lastFired,proto3" json:"last_fired,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) Reset() {
-	*x = Candlesticks1MStreamResponse_Bar{}
-	mi := &file_api_v1_aggregator_proto_msgTypes[2]
+func (x *AlertsResponse_Alert) Reset() {
+	*x = AlertsResponse_Alert{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) String() string {
+func (x *AlertsResponse_Alert) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Candlesticks1MStreamResponse_Bar) ProtoMessage() {}
+func (*AlertsResponse_Alert) ProtoMessage() {}
 
-func (x *Candlesticks1MStreamResponse_Bar) ProtoReflect() protoreflect.Message {
-	mi := &file_api_v1_aggregator_proto_msgTypes[2]
+func (x *AlertsResponse_Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -153,93 +1892,649 @@ func (x *Candlesticks1MStreamResponse_Bar) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Candlesticks1MStreamResponse_Bar.ProtoReflect.Descriptor instead.
-func (*Candlesticks1MStreamResponse_Bar) Descriptor() ([]byte, []int) {
-	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{1, 0}
+// Deprecated: Use AlertsResponse_Alert.ProtoReflect.Descriptor instead.
+func (*AlertsResponse_Alert) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{8, 0}
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) GetHigh() string {
+func (x *AlertsResponse_Alert) GetId() string {
 	if x != nil {
-		return x.High
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) GetLow() string {
+func (x *AlertsResponse_Alert) GetSpec() *AlertSpec {
 	if x != nil {
-		return x.Low
+		return x.Spec
 	}
-	return ""
+	return nil
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) GetOpen() string {
+func (x *AlertsResponse_Alert) GetCreatedAt() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Open
+		return x.CreatedAt
 	}
-	return ""
+	return nil
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) GetClose() string {
+func (x *AlertsResponse_Alert) GetFired() int64 {
 	if x != nil {
-		return x.Close
+		return x.Fired
+	}
+	return 0
+}
+
+func (x *AlertsResponse_Alert) GetLastFired() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFired
+	}
+	return nil
+}
+
+// Fired is an alert firing on a bar, value is the close crossing, the move in percent or the multiple of the average volume
+type AlertsResponse_Fired struct {
+	state         protoimpl.MessageState            `protogen:"open.v1"`
+	Alert         *AlertsResponse_Alert             `protobuf:"bytes,1,opt,name=alert,proto3" json:"alert,omitempty"`
+	Bar           *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,2,opt,name=bar,proto3" json:"bar,omitempty"`
+	Value         float64                           `protobuf:"fixed64,3,opt,name=value,proto3" json:"value,omitempty"`
+	At            *timestamppb.Timestamp            `protobuf:"bytes,4,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertsResponse_Fired) Reset() {
+	*x = AlertsResponse_Fired{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertsResponse_Fired) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertsResponse_Fired) ProtoMessage() {}
+
+func (x *AlertsResponse_Fired) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertsResponse_Fired.ProtoReflect.Descriptor instead.
+func (*AlertsResponse_Fired) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{8, 1}
+}
+
+func (x *AlertsResponse_Fired) GetAlert() *AlertsResponse_Alert {
+	if x != nil {
+		return x.Alert
+	}
+	return nil
+}
+
+func (x *AlertsResponse_Fired) GetBar() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Bar
+	}
+	return nil
+}
+
+func (x *AlertsResponse_Fired) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *AlertsResponse_Fired) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+// Bar sums up the order book of a symbol over an interval, the book is sampled whenever it changes
+type OrderBookResponse_Bar struct {
+	state         protoimpl.MessageState            `protogen:"open.v1"`
+	Symbol        string                            `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	OpenTime      *timestamppb.Timestamp            `protobuf:"bytes,2,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"`
+	Bid           *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,3,opt,name=bid,proto3" json:"bid,omitempty"`         // best bid, UpdatedAt is the time of its latest sample
+	Ask           *Candlesticks1MStreamResponse_Bar `protobuf:"bytes,4,opt,name=ask,proto3" json:"ask,omitempty"`         // best ask
+	Mid           float64                           `protobuf:"fixed64,5,opt,name=mid,proto3" json:"mid,omitempty"`       // at the close
+	Spread        float64                           `protobuf:"fixed64,6,opt,name=spread,proto3" json:"spread,omitempty"` // at the close
+	MeanSpread    float64                           `protobuf:"fixed64,7,opt,name=mean_spread,json=meanSpread,proto3" json:"mean_spread,omitempty"`
+	Imbalance     float64                           `protobuf:"fixed64,8,opt,name=imbalance,proto3" json:"imbalance,omitempty"` // (bids - asks) / (bids + asks) of quantities of the top levels at the close
+	Samples       int64                             `protobuf:"varint,9,opt,name=samples,proto3" json:"samples,omitempty"`
+	Resyncs       int64                             `protobuf:"varint,10,opt,name=resyncs,proto3" json:"resyncs,omitempty"` // times the book fell out of sync and was fetched again
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookResponse_Bar) Reset() {
+	*x = OrderBookResponse_Bar{}
+	mi := &file_api_v1_aggregator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookResponse_Bar) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookResponse_Bar) ProtoMessage() {}
+
+func (x *OrderBookResponse_Bar) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_aggregator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookResponse_Bar.ProtoReflect.Descriptor instead.
+func (*OrderBookResponse_Bar) Descriptor() ([]byte, []int) {
+	return file_api_v1_aggregator_proto_rawDescGZIP(), []int{10, 0}
+}
+
+func (x *OrderBookResponse_Bar) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
 	}
 	return ""
 }
 
-func (x *Candlesticks1MStreamResponse_Bar) GetUpdatedAt() *timestamppb.Timestamp {
+func (x *OrderBookResponse_Bar) GetOpenTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.UpdatedAt
+		return x.OpenTime
+	}
+	return nil
+}
+
+func (x *OrderBookResponse_Bar) GetBid() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Bid
+	}
+	return nil
+}
+
+func (x *OrderBookResponse_Bar) GetAsk() *Candlesticks1MStreamResponse_Bar {
+	if x != nil {
+		return x.Ask
 	}
 	return nil
 }
 
+func (x *OrderBookResponse_Bar) GetMid() float64 {
+	if x != nil {
+		return x.Mid
+	}
+	return 0
+}
+
+func (x *OrderBookResponse_Bar) GetSpread() float64 {
+	if x != nil {
+		return x.Spread
+	}
+	return 0
+}
+
+func (x *OrderBookResponse_Bar) GetMeanSpread() float64 {
+	if x != nil {
+		return x.MeanSpread
+	}
+	return 0
+}
+
+func (x *OrderBookResponse_Bar) GetImbalance() float64 {
+	if x != nil {
+		return x.Imbalance
+	}
+	return 0
+}
+
+func (x *OrderBookResponse_Bar) GetSamples() int64 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *OrderBookResponse_Bar) GetResyncs() int64 {
+	if x != nil {
+		return x.Resyncs
+	}
+	return 0
+}
+
 var File_api_v1_aggregator_proto protoreflect.FileDescriptor
 
 var file_api_v1_aggregator_proto_rawDesc = []byte{
 	0x0a, 0x17, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61,
 	0x74, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0a, 0x73, 0x76, 0x63, 0x2e, 0x61,
-	0x70, 0x69, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
 	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x56, 0x0a, 0x1b, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x8c, 0x02, 0x0a, 0x1b, 0x43, 0x61, 0x6e, 0x64, 0x6c,
+	0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x12,
+	0x39, 0x0a, 0x0a, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31,
+	0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x70, 0x65, 0x63, 0x52, 0x0a,
+	0x69, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x65,
+	0x69, 0x6b, 0x69, 0x6e, 0x5f, 0x61, 0x73, 0x68, 0x69, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x0a, 0x68, 0x65, 0x69, 0x6b, 0x69, 0x6e, 0x41, 0x73, 0x68, 0x69, 0x12, 0x40, 0x0a, 0x0d, 0x61,
+	0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x5f, 0x62, 0x61, 0x72, 0x73, 0x18, 0x05, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x53, 0x70, 0x65, 0x63, 0x52,
+	0x0c, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x71,
+	0x75, 0x6f, 0x74, 0x65, 0x73, 0x22, 0x60, 0x0a, 0x0f, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74,
+	0x79, 0x42, 0x61, 0x72, 0x53, 0x70, 0x65, 0x63, 0x12, 0x2f, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x74, 0x68,
+	0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22, 0xea, 0x01, 0x0a, 0x0d, 0x49, 0x6e, 0x64, 0x69,
+	0x63, 0x61, 0x74, 0x6f, 0x72, 0x53, 0x70, 0x65, 0x63, 0x12, 0x2d, 0x0a, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x4b, 0x69,
+	0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x30, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65,
+	0x72, 0x76, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x73, 0x76, 0x63,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c,
+	0x52, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69,
+	0x6f, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x61, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x66, 0x61, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x6f, 0x77, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x6c, 0x6f, 0x77, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69,
+	0x67, 0x6e, 0x61, 0x6c, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e,
+	0x61, 0x6c, 0x12, 0x0c, 0x0a, 0x01, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x6b,
+	0x12, 0x12, 0x0a, 0x04, 0x6c, 0x69, 0x76, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
+	0x6c, 0x69, 0x76, 0x65, 0x22, 0xe8, 0x10, 0x0a, 0x1c, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73,
+	0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x06, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31,
+	0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x42, 0x61, 0x72, 0x52, 0x06, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x12, 0x3e, 0x0a, 0x03, 0x67,
+	0x61, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x47, 0x61, 0x70, 0x52, 0x03, 0x67, 0x61, 0x70, 0x12, 0x52, 0x0a, 0x0a, 0x69,
+	0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x32, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e,
+	0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61,
+	0x74, 0x6f, 0x72, 0x52, 0x0a, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x12,
+	0x57, 0x0a, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x5f, 0x62, 0x61, 0x72, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31,
+	0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x52, 0x0b, 0x61, 0x63, 0x74,
+	0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x12, 0x47, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x4e, 0x0a, 0x09, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x5f, 0x62, 0x61, 0x72, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x51,
+	0x75, 0x6f, 0x74, 0x65, 0x42, 0x61, 0x72, 0x52, 0x08, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x42, 0x61,
+	0x72, 0x1a, 0xaf, 0x01, 0x0a, 0x03, 0x42, 0x61, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x48, 0x69, 0x67,
+	0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x48, 0x69, 0x67, 0x68, 0x12, 0x10, 0x0a,
+	0x03, 0x4c, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x4c, 0x6f, 0x77, 0x12,
+	0x12, 0x0a, 0x04, 0x4f, 0x70, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x4f,
+	0x70, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x09, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x49, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x49, 0x6e, 0x63, 0x6f, 0x6d, 0x70, 0x6c,
+	0x65, 0x74, 0x65, 0x1a, 0xc9, 0x01, 0x0a, 0x03, 0x47, 0x61, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x66, 0x72, 0x6f, 0x6d, 0x49, 0x64, 0x12, 0x13, 0x0a, 0x05,
+	0x74, 0x6f, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x6f, 0x49,
+	0x64, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x12, 0x2c, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x03, 0x65, 0x6e,
+	0x64, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x65, 0x64, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x65, 0x64, 0x1a,
+	0x99, 0x02, 0x0a, 0x09, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x37, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c,
+	0x6f, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x63, 0x6c, 0x6f, 0x73,
+	0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x61, 0x64, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x05, 0x72, 0x65, 0x61, 0x64, 0x79, 0x12, 0x56, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3e, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x2e, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73,
+	0x1a, 0x39, 0x0a, 0x0b, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0xdc, 0x02, 0x0a, 0x0b,
+	0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3e, 0x0a, 0x03, 0x62, 0x61, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42,
+	0x61, 0x72, 0x52, 0x03, 0x62, 0x61, 0x72, 0x12, 0x37, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x6e, 0x5f,
+	0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x19, 0x0a, 0x08, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x07, 0x66, 0x69, 0x72, 0x73, 0x74, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x6c, 0x61,
+	0x73, 0x74, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x74, 0x72, 0x61, 0x64, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x76, 0x6f,
+	0x6c, 0x75, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x61, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x65, 0x71, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x73, 0x65, 0x71, 0x1a, 0xd3, 0x02, 0x0a, 0x06, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x2a, 0x0a,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x73, 0x76,
+	0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x65, 0x65, 0x64, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x02, 0x61, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x02, 0x61, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x72,
+	0x61, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x72, 0x61, 0x64, 0x65,
+	0x12, 0x33, 0x0a, 0x07, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x73, 0x69,
+	0x6c, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x35, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x08, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05,
+	0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x72, 0x69,
+	0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x64, 0x65, 0x76, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x1a, 0xac, 0x02, 0x0a, 0x08, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x42, 0x61, 0x72, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x37, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3e,
+	0x0a, 0x03, 0x62, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76,
+	0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73,
+	0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x03, 0x62, 0x69, 0x64, 0x12, 0x3e,
+	0x0a, 0x03, 0x61, 0x73, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76,
+	0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73,
+	0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x03, 0x61, 0x73, 0x6b, 0x12, 0x1f,
+	0x0a, 0x0b, 0x6d, 0x65, 0x61, 0x6e, 0x5f, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x65, 0x61, 0x6e, 0x53, 0x70, 0x72, 0x65, 0x61, 0x64, 0x12,
+	0x16, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c, 0x6f, 0x73, 0x65,
+	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x22,
+	0xbf, 0x02, 0x0a, 0x1a, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73,
+	0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x30, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x2c, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x12, 0x30, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x52, 0x08,
+	0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x68, 0x65, 0x69, 0x6b,
+	0x69, 0x6e, 0x5f, 0x61, 0x73, 0x68, 0x69, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x68,
+	0x65, 0x69, 0x6b, 0x69, 0x6e, 0x41, 0x73, 0x68, 0x69, 0x12, 0x16, 0x0a, 0x06, 0x71, 0x75, 0x6f,
+	0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x74, 0x65,
+	0x73, 0x12, 0x3e, 0x0a, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x5f, 0x62, 0x61,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72,
+	0x53, 0x70, 0x65, 0x63, 0x52, 0x0b, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61,
+	0x72, 0x22, 0x8c, 0x02, 0x0a, 0x1b, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x40, 0x0a, 0x04, 0x62, 0x61, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e,
+	0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x04, 0x62,
+	0x61, 0x72, 0x73, 0x12, 0x50, 0x0a, 0x0a, 0x71, 0x75, 0x6f, 0x74, 0x65, 0x5f, 0x62, 0x61, 0x72,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b,
+	0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x65, 0x42, 0x61, 0x72, 0x52, 0x09, 0x71, 0x75, 0x6f, 0x74,
+	0x65, 0x42, 0x61, 0x72, 0x73, 0x12, 0x59, 0x0a, 0x0d, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74,
+	0x79, 0x5f, 0x62, 0x61, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x73,
+	0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65,
 	0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42,
+	0x61, 0x72, 0x52, 0x0c, 0x61, 0x63, 0x74, 0x69, 0x76, 0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x73,
+	0x22, 0x80, 0x01, 0x0a, 0x09, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x53, 0x70, 0x65, 0x63, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x12, 0x29, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x52, 0x04, 0x6b, 0x69, 0x6e,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x6f, 0x6b, 0x62,
+	0x61, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6c, 0x6f, 0x6f, 0x6b, 0x62,
+	0x61, 0x63, 0x6b, 0x22, 0x93, 0x01, 0x0a, 0x0d, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65,
 	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
 	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x22, 0xf6,
-	0x01, 0x0a, 0x1c, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31,
-	0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x44, 0x0a, 0x06, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e,
+	0x73, 0x74, 0x49, 0x64, 0x12, 0x29, 0x0a, 0x03, 0x61, 0x64, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x6c, 0x65, 0x72, 0x74, 0x53, 0x70, 0x65, 0x63, 0x48, 0x00, 0x52, 0x03, 0x61, 0x64, 0x64, 0x12,
+	0x18, 0x0a, 0x06, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x00, 0x52, 0x06, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x12, 0x14, 0x0a, 0x04, 0x6c, 0x69, 0x73,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x42,
+	0x08, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0xed, 0x04, 0x0a, 0x0e, 0x41, 0x6c,
+	0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x05,
+	0x61, 0x64, 0x64, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x76,
+	0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x52, 0x05, 0x61,
+	0x64, 0x64, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c, 0x65,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x6c,
+	0x65, 0x64, 0x12, 0x38, 0x0a, 0x06, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x41,
+	0x6c, 0x65, 0x72, 0x74, 0x52, 0x06, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x12, 0x36, 0x0a, 0x05,
+	0x66, 0x69, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x76,
+	0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x46, 0x69, 0x72, 0x65, 0x64, 0x52, 0x05, 0x66,
+	0x69, 0x72, 0x65, 0x64, 0x1a, 0xce, 0x01, 0x0a, 0x05, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x29,
+	0x0a, 0x04, 0x73, 0x70, 0x65, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73,
+	0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x53,
+	0x70, 0x65, 0x63, 0x52, 0x04, 0x73, 0x70, 0x65, 0x63, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x66, 0x69, 0x72, 0x65, 0x64, 0x12, 0x39, 0x0a, 0x0a, 0x6c, 0x61,
+	0x73, 0x74, 0x5f, 0x66, 0x69, 0x72, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74,
+	0x46, 0x69, 0x72, 0x65, 0x64, 0x1a, 0xc1, 0x01, 0x0a, 0x05, 0x46, 0x69, 0x72, 0x65, 0x64, 0x12,
+	0x36, 0x0a, 0x05, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20,
+	0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74,
+	0x52, 0x05, 0x61, 0x6c, 0x65, 0x72, 0x74, 0x12, 0x3e, 0x0a, 0x03, 0x62, 0x61, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42,
+	0x61, 0x72, 0x52, 0x03, 0x62, 0x61, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x2a, 0x0a,
+	0x02, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x02, 0x61, 0x74, 0x22, 0x2c, 0x0a, 0x10, 0x4f, 0x72, 0x64,
+	0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x22, 0xbe, 0x03, 0x0a, 0x11, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x33, 0x0a,
+	0x03, 0x62, 0x61, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x73, 0x76, 0x63,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f,
+	0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x03, 0x62,
+	0x61, 0x72, 0x1a, 0xf3, 0x02, 0x0a, 0x03, 0x42, 0x61, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x79,
+	0x6d, 0x62, 0x6f, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x79, 0x6d, 0x62,
+	0x6f, 0x6c, 0x12, 0x37, 0x0a, 0x09, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3e, 0x0a, 0x03, 0x62,
+	0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x03, 0x62, 0x69, 0x64, 0x12, 0x3e, 0x0a, 0x03, 0x61,
+	0x73, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x03, 0x61, 0x73, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x6d,
+	0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x03, 0x6d, 0x69, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x70, 0x72, 0x65, 0x61, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x73,
+	0x70, 0x72, 0x65, 0x61, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65, 0x61, 0x6e, 0x5f, 0x73, 0x70,
+	0x72, 0x65, 0x61, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x65, 0x61, 0x6e,
+	0x53, 0x70, 0x72, 0x65, 0x61, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6d, 0x62, 0x61, 0x6c, 0x61,
+	0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x69, 0x6d, 0x62, 0x61, 0x6c,
+	0x61, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x07, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x73, 0x22, 0x2d, 0x0a, 0x11, 0x41, 0x64, 0x64, 0x53,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
+	0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x22, 0x30, 0x0a, 0x14, 0x52, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x18, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x22, 0x14, 0x0a, 0x12, 0x4c, 0x69, 0x73,
+	0x74, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22,
+	0x45, 0x0a, 0x0f, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x64, 0x2a, 0xc6, 0x01, 0x0a, 0x0f, 0x41, 0x63, 0x74, 0x69, 0x76,
+	0x69, 0x74, 0x79, 0x42, 0x61, 0x72, 0x54, 0x79, 0x70, 0x65, 0x12, 0x21, 0x0a, 0x1d, 0x41, 0x43,
+	0x54, 0x49, 0x56, 0x49, 0x54, 0x59, 0x5f, 0x42, 0x41, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1a, 0x0a,
+	0x16, 0x41, 0x43, 0x54, 0x49, 0x56, 0x49, 0x54, 0x59, 0x5f, 0x42, 0x41, 0x52, 0x5f, 0x54, 0x59,
+	0x50, 0x45, 0x5f, 0x54, 0x49, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18, 0x41, 0x43, 0x54,
+	0x49, 0x56, 0x49, 0x54, 0x59, 0x5f, 0x42, 0x41, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x56,
+	0x4f, 0x4c, 0x55, 0x4d, 0x45, 0x10, 0x02, 0x12, 0x1c, 0x0a, 0x18, 0x41, 0x43, 0x54, 0x49, 0x56,
+	0x49, 0x54, 0x59, 0x5f, 0x42, 0x41, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x4f, 0x4c,
+	0x4c, 0x41, 0x52, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x41, 0x43, 0x54, 0x49, 0x56, 0x49, 0x54,
+	0x59, 0x5f, 0x42, 0x41, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x4e, 0x4b, 0x4f,
+	0x10, 0x04, 0x12, 0x1b, 0x0a, 0x17, 0x41, 0x43, 0x54, 0x49, 0x56, 0x49, 0x54, 0x59, 0x5f, 0x42,
+	0x41, 0x52, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x41, 0x4e, 0x47, 0x45, 0x10, 0x05, 0x2a,
+	0xae, 0x01, 0x0a, 0x0d, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x6f, 0x72, 0x4b, 0x69, 0x6e,
+	0x64, 0x12, 0x1e, 0x0a, 0x1a, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4b,
+	0x49, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x16, 0x0a, 0x12, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4b,
+	0x49, 0x4e, 0x44, 0x5f, 0x53, 0x4d, 0x41, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x49, 0x4e, 0x44,
+	0x49, 0x43, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x45, 0x4d, 0x41, 0x10,
+	0x02, 0x12, 0x16, 0x0a, 0x12, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4b,
+	0x49, 0x4e, 0x44, 0x5f, 0x52, 0x53, 0x49, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x49, 0x4e, 0x44,
+	0x49, 0x43, 0x41, 0x54, 0x4f, 0x52, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x4d, 0x41, 0x43, 0x44,
+	0x10, 0x04, 0x12, 0x1c, 0x0a, 0x18, 0x49, 0x4e, 0x44, 0x49, 0x43, 0x41, 0x54, 0x4f, 0x52, 0x5f,
+	0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x42, 0x4f, 0x4c, 0x4c, 0x49, 0x4e, 0x47, 0x45, 0x52, 0x10, 0x05,
+	0x2a, 0x9f, 0x01, 0x0a, 0x0a, 0x46, 0x65, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x1b, 0x0a, 0x17, 0x46, 0x45, 0x45, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11,
+	0x46, 0x45, 0x45, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x53, 0x54, 0x41, 0x4c,
+	0x45, 0x10, 0x01, 0x12, 0x14, 0x0a, 0x10, 0x46, 0x45, 0x45, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54,
+	0x55, 0x53, 0x5f, 0x4c, 0x49, 0x56, 0x45, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x46, 0x45, 0x45,
+	0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x53, 0x50, 0x49, 0x4b, 0x45, 0x10, 0x03,
+	0x12, 0x17, 0x0a, 0x13, 0x46, 0x45, 0x45, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f,
+	0x53, 0x54, 0x41, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x45, 0x45,
+	0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x55, 0x53, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4d, 0x45, 0x44,
+	0x10, 0x05, 0x2a, 0x68, 0x0a, 0x08, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x18,
+	0x0a, 0x14, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x56, 0x41, 0x4c, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45,
+	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4e, 0x54, 0x45,
+	0x52, 0x56, 0x41, 0x4c, 0x5f, 0x31, 0x4d, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4e, 0x54,
+	0x45, 0x52, 0x56, 0x41, 0x4c, 0x5f, 0x35, 0x4d, 0x10, 0x02, 0x12, 0x0f, 0x0a, 0x0b, 0x49, 0x4e,
+	0x54, 0x45, 0x52, 0x56, 0x41, 0x4c, 0x5f, 0x31, 0x48, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x49,
+	0x4e, 0x54, 0x45, 0x52, 0x56, 0x41, 0x4c, 0x5f, 0x31, 0x44, 0x10, 0x04, 0x2a, 0x91, 0x01, 0x0a,
+	0x09, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x4b, 0x69, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x16, 0x41, 0x4c,
+	0x45, 0x52, 0x54, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x41, 0x4c, 0x45, 0x52, 0x54, 0x5f,
+	0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x43, 0x52, 0x4f, 0x53, 0x53, 0x5f, 0x41, 0x42, 0x4f, 0x56, 0x45,
+	0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x41, 0x4c, 0x45, 0x52, 0x54, 0x5f, 0x4b, 0x49, 0x4e, 0x44,
+	0x5f, 0x43, 0x52, 0x4f, 0x53, 0x53, 0x5f, 0x42, 0x45, 0x4c, 0x4f, 0x57, 0x10, 0x02, 0x12, 0x13,
+	0x0a, 0x0f, 0x41, 0x4c, 0x45, 0x52, 0x54, 0x5f, 0x4b, 0x49, 0x4e, 0x44, 0x5f, 0x4d, 0x4f, 0x56,
+	0x45, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x41, 0x4c, 0x45, 0x52, 0x54, 0x5f, 0x4b, 0x49, 0x4e,
+	0x44, 0x5f, 0x56, 0x4f, 0x4c, 0x55, 0x4d, 0x45, 0x5f, 0x53, 0x50, 0x49, 0x4b, 0x45, 0x10, 0x04,
+	0x32, 0xee, 0x02, 0x0a, 0x04, 0x41, 0x67, 0x67, 0x72, 0x12, 0x6d, 0x0a, 0x14, 0x43, 0x61, 0x6e,
 	0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x42, 0x61, 0x72, 0x52, 0x06, 0x75,
-	0x70, 0x64, 0x61, 0x74, 0x65, 0x1a, 0x8f, 0x01, 0x0a, 0x03, 0x42, 0x61, 0x72, 0x12, 0x12, 0x0a,
-	0x04, 0x48, 0x69, 0x67, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x48, 0x69, 0x67,
-	0x68, 0x12, 0x10, 0x0a, 0x03, 0x4c, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x4c, 0x6f, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x4f, 0x70, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6c, 0x6f, 0x73, 0x65,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x38, 0x0a,
-	0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
-	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x55, 0x70,
-	0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x32, 0x75, 0x0a, 0x04, 0x41, 0x67, 0x67, 0x72, 0x12,
-	0x6d, 0x0a, 0x14, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31,
-	0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x27, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70,
+	0x6d, 0x12, 0x27, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x73, 0x76, 0x63,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74,
+	0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x66, 0x0a, 0x13, 0x43, 0x61, 0x6e, 0x64,
+	0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12,
+	0x26, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e,
+	0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70,
 	0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b,
-	0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x28, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
-	0x6e, 0x64, 0x6c, 0x65, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x73, 0x31, 0x4d, 0x53, 0x74, 0x72, 0x65,
-	0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0xa4,
-	0x01, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
-	0x31, 0x42, 0x0f, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x72, 0x6f,
-	0x74, 0x6f, 0x50, 0x01, 0x5a, 0x37, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x72, 0x69, 0x63, 0x6b, 0x6c, 0x69, 0x75, 0x6a, 0x68, 0x2f, 0x74, 0x72, 0x61, 0x64, 0x69,
-	0x6e, 0x67, 0x2d, 0x63, 0x68, 0x61, 0x74, 0x2d, 0x61, 0x67, 0x67, 0x72, 0x2f, 0x70, 0x6b, 0x67,
-	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x3b, 0x61, 0x70, 0x69, 0x76, 0x31, 0xa2, 0x02, 0x03,
-	0x53, 0x41, 0x58, 0xaa, 0x02, 0x0a, 0x53, 0x76, 0x63, 0x2e, 0x41, 0x70, 0x69, 0x2e, 0x56, 0x31,
-	0xca, 0x02, 0x0a, 0x53, 0x76, 0x63, 0x5c, 0x41, 0x70, 0x69, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x16,
-	0x53, 0x76, 0x63, 0x5c, 0x41, 0x70, 0x69, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x0c, 0x53, 0x76, 0x63, 0x3a, 0x3a, 0x41, 0x70,
-	0x69, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x43, 0x0a, 0x06, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x12, 0x19, 0x2e, 0x73, 0x76, 0x63,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e,
+	0x76, 0x31, 0x2e, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x28, 0x01, 0x30, 0x01, 0x12, 0x4a, 0x0a, 0x09, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f,
+	0x6f, 0x6b, 0x12, 0x1c, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e,
+	0x4f, 0x72, 0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1d, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x72,
+	0x64, 0x65, 0x72, 0x42, 0x6f, 0x6f, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30,
+	0x01, 0x32, 0xed, 0x01, 0x0a, 0x05, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x48, 0x0a, 0x0a, 0x41,
+	0x64, 0x64, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x12, 0x1d, 0x2e, 0x73, 0x76, 0x63, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x64, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4e, 0x0a, 0x0d, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x53,
+	0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x12, 0x20, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4a, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x79, 0x6d,
+	0x62, 0x6f, 0x6c, 0x73, 0x12, 0x1e, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0xa4, 0x01, 0x0a, 0x0e, 0x63, 0x6f, 0x6d, 0x2e, 0x73, 0x76, 0x63, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x76, 0x31, 0x42, 0x0f, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x6f, 0x72,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x37, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
+	0x63, 0x6f, 0x6d, 0x2f, 0x72, 0x69, 0x63, 0x6b, 0x6c, 0x69, 0x75, 0x6a, 0x68, 0x2f, 0x74, 0x72,
+	0x61, 0x64, 0x69, 0x6e, 0x67, 0x2d, 0x63, 0x68, 0x61, 0x74, 0x2d, 0x61, 0x67, 0x67, 0x72, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x3b, 0x61, 0x70, 0x69, 0x76, 0x31,
+	0xa2, 0x02, 0x03, 0x53, 0x41, 0x58, 0xaa, 0x02, 0x0a, 0x53, 0x76, 0x63, 0x2e, 0x41, 0x70, 0x69,
+	0x2e, 0x56, 0x31, 0xca, 0x02, 0x0a, 0x53, 0x76, 0x63, 0x5c, 0x41, 0x70, 0x69, 0x5c, 0x56, 0x31,
+	0xe2, 0x02, 0x16, 0x53, 0x76, 0x63, 0x5c, 0x41, 0x70, 0x69, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50,
+	0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0xea, 0x02, 0x0c, 0x53, 0x76, 0x63, 0x3a,
+	0x3a, 0x41, 0x70, 0x69, 0x3a, 0x3a, 0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -254,23 +2549,110 @@ func file_api_v1_aggregator_proto_rawDescGZIP() []byte {
 	return file_api_v1_aggregator_proto_rawDescData
 }
 
-var file_api_v1_aggregator_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_api_v1_aggregator_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_api_v1_aggregator_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_api_v1_aggregator_proto_goTypes = []any{
-	(*Candlesticks1MStreamRequest)(nil),      // 0: svc.api.v1.Candlesticks1MStreamRequest
-	(*Candlesticks1MStreamResponse)(nil),     // 1: svc.api.v1.Candlesticks1MStreamResponse
-	(*Candlesticks1MStreamResponse_Bar)(nil), // 2: svc.api.v1.Candlesticks1MStreamResponse.Bar
-	(*timestamppb.Timestamp)(nil),            // 3: google.protobuf.Timestamp
+	(ActivityBarType)(0),                             // 0: svc.api.v1.ActivityBarType
+	(IndicatorKind)(0),                               // 1: svc.api.v1.IndicatorKind
+	(FeedStatus)(0),                                  // 2: svc.api.v1.FeedStatus
+	(Interval)(0),                                    // 3: svc.api.v1.Interval
+	(AlertKind)(0),                                   // 4: svc.api.v1.AlertKind
+	(*Candlesticks1MStreamRequest)(nil),              // 5: svc.api.v1.Candlesticks1MStreamRequest
+	(*ActivityBarSpec)(nil),                          // 6: svc.api.v1.ActivityBarSpec
+	(*IndicatorSpec)(nil),                            // 7: svc.api.v1.IndicatorSpec
+	(*Candlesticks1MStreamResponse)(nil),             // 8: svc.api.v1.Candlesticks1MStreamResponse
+	(*CandlesticksHistoryRequest)(nil),               // 9: svc.api.v1.CandlesticksHistoryRequest
+	(*CandlesticksHistoryResponse)(nil),              // 10: svc.api.v1.CandlesticksHistoryResponse
+	(*AlertSpec)(nil),                                // 11: svc.api.v1.AlertSpec
+	(*AlertsRequest)(nil),                            // 12: svc.api.v1.AlertsRequest
+	(*AlertsResponse)(nil),                           // 13: svc.api.v1.AlertsResponse
+	(*OrderBookRequest)(nil),                         // 14: svc.api.v1.OrderBookRequest
+	(*OrderBookResponse)(nil),                        // 15: svc.api.v1.OrderBookResponse
+	(*AddSymbolsRequest)(nil),                        // 16: svc.api.v1.AddSymbolsRequest
+	(*RemoveSymbolsRequest)(nil),                     // 17: svc.api.v1.RemoveSymbolsRequest
+	(*ListSymbolsRequest)(nil),                       // 18: svc.api.v1.ListSymbolsRequest
+	(*SymbolsResponse)(nil),                          // 19: svc.api.v1.SymbolsResponse
+	(*Candlesticks1MStreamResponse_Bar)(nil),         // 20: svc.api.v1.Candlesticks1MStreamResponse.Bar
+	(*Candlesticks1MStreamResponse_Gap)(nil),         // 21: svc.api.v1.Candlesticks1MStreamResponse.Gap
+	(*Candlesticks1MStreamResponse_Indicator)(nil),   // 22: svc.api.v1.Candlesticks1MStreamResponse.Indicator
+	(*Candlesticks1MStreamResponse_ActivityBar)(nil), // 23: svc.api.v1.Candlesticks1MStreamResponse.ActivityBar
+	(*Candlesticks1MStreamResponse_Status)(nil),      // 24: svc.api.v1.Candlesticks1MStreamResponse.Status
+	(*Candlesticks1MStreamResponse_QuoteBar)(nil),    // 25: svc.api.v1.Candlesticks1MStreamResponse.QuoteBar
+	nil,                           // 26: svc.api.v1.Candlesticks1MStreamResponse.Indicator.ValuesEntry
+	(*AlertsResponse_Alert)(nil),  // 27: svc.api.v1.AlertsResponse.Alert
+	(*AlertsResponse_Fired)(nil),  // 28: svc.api.v1.AlertsResponse.Fired
+	(*OrderBookResponse_Bar)(nil), // 29: svc.api.v1.OrderBookResponse.Bar
+	(*timestamppb.Timestamp)(nil), // 30: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 31: google.protobuf.Duration
 }
 var file_api_v1_aggregator_proto_depIdxs = []int32{
-	2, // 0: svc.api.v1.Candlesticks1MStreamResponse.update:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
-	3, // 1: svc.api.v1.Candlesticks1MStreamResponse.Bar.UpdatedAt:type_name -> google.protobuf.Timestamp
-	0, // 2: svc.api.v1.Aggr.Candlesticks1MStream:input_type -> svc.api.v1.Candlesticks1MStreamRequest
-	1, // 3: svc.api.v1.Aggr.Candlesticks1MStream:output_type -> svc.api.v1.Candlesticks1MStreamResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	7,  // 0: svc.api.v1.Candlesticks1MStreamRequest.indicators:type_name -> svc.api.v1.IndicatorSpec
+	6,  // 1: svc.api.v1.Candlesticks1MStreamRequest.activity_bars:type_name -> svc.api.v1.ActivityBarSpec
+	0,  // 2: svc.api.v1.ActivityBarSpec.type:type_name -> svc.api.v1.ActivityBarType
+	1,  // 3: svc.api.v1.IndicatorSpec.kind:type_name -> svc.api.v1.IndicatorKind
+	3,  // 4: svc.api.v1.IndicatorSpec.interval:type_name -> svc.api.v1.Interval
+	20, // 5: svc.api.v1.Candlesticks1MStreamResponse.update:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	21, // 6: svc.api.v1.Candlesticks1MStreamResponse.gap:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Gap
+	22, // 7: svc.api.v1.Candlesticks1MStreamResponse.indicators:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Indicator
+	23, // 8: svc.api.v1.Candlesticks1MStreamResponse.activity_bar:type_name -> svc.api.v1.Candlesticks1MStreamResponse.ActivityBar
+	24, // 9: svc.api.v1.Candlesticks1MStreamResponse.status:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Status
+	25, // 10: svc.api.v1.Candlesticks1MStreamResponse.quote_bar:type_name -> svc.api.v1.Candlesticks1MStreamResponse.QuoteBar
+	30, // 11: svc.api.v1.CandlesticksHistoryRequest.start:type_name -> google.protobuf.Timestamp
+	30, // 12: svc.api.v1.CandlesticksHistoryRequest.end:type_name -> google.protobuf.Timestamp
+	3,  // 13: svc.api.v1.CandlesticksHistoryRequest.interval:type_name -> svc.api.v1.Interval
+	6,  // 14: svc.api.v1.CandlesticksHistoryRequest.activity_bar:type_name -> svc.api.v1.ActivityBarSpec
+	20, // 15: svc.api.v1.CandlesticksHistoryResponse.bars:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	25, // 16: svc.api.v1.CandlesticksHistoryResponse.quote_bars:type_name -> svc.api.v1.Candlesticks1MStreamResponse.QuoteBar
+	23, // 17: svc.api.v1.CandlesticksHistoryResponse.activity_bars:type_name -> svc.api.v1.Candlesticks1MStreamResponse.ActivityBar
+	4,  // 18: svc.api.v1.AlertSpec.kind:type_name -> svc.api.v1.AlertKind
+	11, // 19: svc.api.v1.AlertsRequest.add:type_name -> svc.api.v1.AlertSpec
+	27, // 20: svc.api.v1.AlertsResponse.added:type_name -> svc.api.v1.AlertsResponse.Alert
+	27, // 21: svc.api.v1.AlertsResponse.alerts:type_name -> svc.api.v1.AlertsResponse.Alert
+	28, // 22: svc.api.v1.AlertsResponse.fired:type_name -> svc.api.v1.AlertsResponse.Fired
+	29, // 23: svc.api.v1.OrderBookResponse.bar:type_name -> svc.api.v1.OrderBookResponse.Bar
+	30, // 24: svc.api.v1.Candlesticks1MStreamResponse.Bar.UpdatedAt:type_name -> google.protobuf.Timestamp
+	30, // 25: svc.api.v1.Candlesticks1MStreamResponse.Gap.start:type_name -> google.protobuf.Timestamp
+	30, // 26: svc.api.v1.Candlesticks1MStreamResponse.Gap.end:type_name -> google.protobuf.Timestamp
+	30, // 27: svc.api.v1.Candlesticks1MStreamResponse.Indicator.open_time:type_name -> google.protobuf.Timestamp
+	26, // 28: svc.api.v1.Candlesticks1MStreamResponse.Indicator.values:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Indicator.ValuesEntry
+	20, // 29: svc.api.v1.Candlesticks1MStreamResponse.ActivityBar.bar:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	30, // 30: svc.api.v1.Candlesticks1MStreamResponse.ActivityBar.open_time:type_name -> google.protobuf.Timestamp
+	2,  // 31: svc.api.v1.Candlesticks1MStreamResponse.Status.kind:type_name -> svc.api.v1.FeedStatus
+	30, // 32: svc.api.v1.Candlesticks1MStreamResponse.Status.at:type_name -> google.protobuf.Timestamp
+	30, // 33: svc.api.v1.Candlesticks1MStreamResponse.Status.last_trade:type_name -> google.protobuf.Timestamp
+	31, // 34: svc.api.v1.Candlesticks1MStreamResponse.Status.silence:type_name -> google.protobuf.Duration
+	31, // 35: svc.api.v1.Candlesticks1MStreamResponse.Status.expected:type_name -> google.protobuf.Duration
+	30, // 36: svc.api.v1.Candlesticks1MStreamResponse.QuoteBar.open_time:type_name -> google.protobuf.Timestamp
+	20, // 37: svc.api.v1.Candlesticks1MStreamResponse.QuoteBar.bid:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	20, // 38: svc.api.v1.Candlesticks1MStreamResponse.QuoteBar.ask:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	11, // 39: svc.api.v1.AlertsResponse.Alert.spec:type_name -> svc.api.v1.AlertSpec
+	30, // 40: svc.api.v1.AlertsResponse.Alert.created_at:type_name -> google.protobuf.Timestamp
+	30, // 41: svc.api.v1.AlertsResponse.Alert.last_fired:type_name -> google.protobuf.Timestamp
+	27, // 42: svc.api.v1.AlertsResponse.Fired.alert:type_name -> svc.api.v1.AlertsResponse.Alert
+	20, // 43: svc.api.v1.AlertsResponse.Fired.bar:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	30, // 44: svc.api.v1.AlertsResponse.Fired.at:type_name -> google.protobuf.Timestamp
+	30, // 45: svc.api.v1.OrderBookResponse.Bar.open_time:type_name -> google.protobuf.Timestamp
+	20, // 46: svc.api.v1.OrderBookResponse.Bar.bid:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	20, // 47: svc.api.v1.OrderBookResponse.Bar.ask:type_name -> svc.api.v1.Candlesticks1MStreamResponse.Bar
+	5,  // 48: svc.api.v1.Aggr.Candlesticks1MStream:input_type -> svc.api.v1.Candlesticks1MStreamRequest
+	9,  // 49: svc.api.v1.Aggr.CandlesticksHistory:input_type -> svc.api.v1.CandlesticksHistoryRequest
+	12, // 50: svc.api.v1.Aggr.Alerts:input_type -> svc.api.v1.AlertsRequest
+	14, // 51: svc.api.v1.Aggr.OrderBook:input_type -> svc.api.v1.OrderBookRequest
+	16, // 52: svc.api.v1.Admin.AddSymbols:input_type -> svc.api.v1.AddSymbolsRequest
+	17, // 53: svc.api.v1.Admin.RemoveSymbols:input_type -> svc.api.v1.RemoveSymbolsRequest
+	18, // 54: svc.api.v1.Admin.ListSymbols:input_type -> svc.api.v1.ListSymbolsRequest
+	8,  // 55: svc.api.v1.Aggr.Candlesticks1MStream:output_type -> svc.api.v1.Candlesticks1MStreamResponse
+	10, // 56: svc.api.v1.Aggr.CandlesticksHistory:output_type -> svc.api.v1.CandlesticksHistoryResponse
+	13, // 57: svc.api.v1.Aggr.Alerts:output_type -> svc.api.v1.AlertsResponse
+	15, // 58: svc.api.v1.Aggr.OrderBook:output_type -> svc.api.v1.OrderBookResponse
+	19, // 59: svc.api.v1.Admin.AddSymbols:output_type -> svc.api.v1.SymbolsResponse
+	19, // 60: svc.api.v1.Admin.RemoveSymbols:output_type -> svc.api.v1.SymbolsResponse
+	19, // 61: svc.api.v1.Admin.ListSymbols:output_type -> svc.api.v1.SymbolsResponse
+	55, // [55:62] is the sub-list for method output_type
+	48, // [48:55] is the sub-list for method input_type
+	48, // [48:48] is the sub-list for extension type_name
+	48, // [48:48] is the sub-list for extension extendee
+	0,  // [0:48] is the sub-list for field type_name
 }
 
 func init() { file_api_v1_aggregator_proto_init() }
@@ -278,18 +2660,24 @@ func file_api_v1_aggregator_proto_init() {
 	if File_api_v1_aggregator_proto != nil {
 		return
 	}
+	file_api_v1_aggregator_proto_msgTypes[7].OneofWrappers = []any{
+		(*AlertsRequest_Add)(nil),
+		(*AlertsRequest_Cancel)(nil),
+		(*AlertsRequest_List)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_v1_aggregator_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      5,
+			NumMessages:   25,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_api_v1_aggregator_proto_goTypes,
 		DependencyIndexes: file_api_v1_aggregator_proto_depIdxs,
+		EnumInfos:         file_api_v1_aggregator_proto_enumTypes,
 		MessageInfos:      file_api_v1_aggregator_proto_msgTypes,
 	}.Build()
 	File_api_v1_aggregator_proto = out.File
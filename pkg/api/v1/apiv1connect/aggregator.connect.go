@@ -23,6 +23,8 @@ const _ = connect.IsAtLeastVersion1_13_0
 const (
 	// AggrName is the fully-qualified name of the Aggr service.
 	AggrName = "svc.api.v1.Aggr"
+	// AdminName is the fully-qualified name of the Admin service.
+	AdminName = "svc.api.v1.Admin"
 )
 
 // These constants are the fully-qualified names of the RPCs defined in this package. They're
@@ -36,11 +38,27 @@ const (
 	// AggrCandlesticks1MStreamProcedure is the fully-qualified name of the Aggr's Candlesticks1MStream
 	// RPC.
 	AggrCandlesticks1MStreamProcedure = "/svc.api.v1.Aggr/Candlesticks1MStream"
+	// AggrCandlesticksHistoryProcedure is the fully-qualified name of the Aggr's CandlesticksHistory
+	// RPC.
+	AggrCandlesticksHistoryProcedure = "/svc.api.v1.Aggr/CandlesticksHistory"
+	// AggrAlertsProcedure is the fully-qualified name of the Aggr's Alerts RPC.
+	AggrAlertsProcedure = "/svc.api.v1.Aggr/Alerts"
+	// AggrOrderBookProcedure is the fully-qualified name of the Aggr's OrderBook RPC.
+	AggrOrderBookProcedure = "/svc.api.v1.Aggr/OrderBook"
+	// AdminAddSymbolsProcedure is the fully-qualified name of the Admin's AddSymbols RPC.
+	AdminAddSymbolsProcedure = "/svc.api.v1.Admin/AddSymbols"
+	// AdminRemoveSymbolsProcedure is the fully-qualified name of the Admin's RemoveSymbols RPC.
+	AdminRemoveSymbolsProcedure = "/svc.api.v1.Admin/RemoveSymbols"
+	// AdminListSymbolsProcedure is the fully-qualified name of the Admin's ListSymbols RPC.
+	AdminListSymbolsProcedure = "/svc.api.v1.Admin/ListSymbols"
 )
 
 // AggrClient is a client for the svc.api.v1.Aggr service.
 type AggrClient interface {
 	Candlesticks1MStream(context.Context) *connect.BidiStreamForClient[v1.Candlesticks1MStreamRequest, v1.Candlesticks1MStreamResponse]
+	CandlesticksHistory(context.Context, *connect.Request[v1.CandlesticksHistoryRequest]) (*connect.Response[v1.CandlesticksHistoryResponse], error)
+	Alerts(context.Context) *connect.BidiStreamForClient[v1.AlertsRequest, v1.AlertsResponse]
+	OrderBook(context.Context, *connect.Request[v1.OrderBookRequest]) (*connect.ServerStreamForClient[v1.OrderBookResponse], error)
 }
 
 // NewAggrClient constructs a client for the svc.api.v1.Aggr service. By default, it uses the
@@ -60,12 +78,33 @@ func NewAggrClient(httpClient connect.HTTPClient, baseURL string, opts ...connec
 			connect.WithSchema(aggrMethods.ByName("Candlesticks1MStream")),
 			connect.WithClientOptions(opts...),
 		),
+		candlesticksHistory: connect.NewClient[v1.CandlesticksHistoryRequest, v1.CandlesticksHistoryResponse](
+			httpClient,
+			baseURL+AggrCandlesticksHistoryProcedure,
+			connect.WithSchema(aggrMethods.ByName("CandlesticksHistory")),
+			connect.WithClientOptions(opts...),
+		),
+		alerts: connect.NewClient[v1.AlertsRequest, v1.AlertsResponse](
+			httpClient,
+			baseURL+AggrAlertsProcedure,
+			connect.WithSchema(aggrMethods.ByName("Alerts")),
+			connect.WithClientOptions(opts...),
+		),
+		orderBook: connect.NewClient[v1.OrderBookRequest, v1.OrderBookResponse](
+			httpClient,
+			baseURL+AggrOrderBookProcedure,
+			connect.WithSchema(aggrMethods.ByName("OrderBook")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // aggrClient implements AggrClient.
 type aggrClient struct {
 	candlesticks1MStream *connect.Client[v1.Candlesticks1MStreamRequest, v1.Candlesticks1MStreamResponse]
+	candlesticksHistory  *connect.Client[v1.CandlesticksHistoryRequest, v1.CandlesticksHistoryResponse]
+	alerts               *connect.Client[v1.AlertsRequest, v1.AlertsResponse]
+	orderBook            *connect.Client[v1.OrderBookRequest, v1.OrderBookResponse]
 }
 
 // Candlesticks1MStream calls svc.api.v1.Aggr.Candlesticks1MStream.
@@ -73,9 +112,27 @@ func (c *aggrClient) Candlesticks1MStream(ctx context.Context) *connect.BidiStre
 	return c.candlesticks1MStream.CallBidiStream(ctx)
 }
 
+// CandlesticksHistory calls svc.api.v1.Aggr.CandlesticksHistory.
+func (c *aggrClient) CandlesticksHistory(ctx context.Context, req *connect.Request[v1.CandlesticksHistoryRequest]) (*connect.Response[v1.CandlesticksHistoryResponse], error) {
+	return c.candlesticksHistory.CallUnary(ctx, req)
+}
+
+// Alerts calls svc.api.v1.Aggr.Alerts.
+func (c *aggrClient) Alerts(ctx context.Context) *connect.BidiStreamForClient[v1.AlertsRequest, v1.AlertsResponse] {
+	return c.alerts.CallBidiStream(ctx)
+}
+
+// OrderBook calls svc.api.v1.Aggr.OrderBook.
+func (c *aggrClient) OrderBook(ctx context.Context, req *connect.Request[v1.OrderBookRequest]) (*connect.ServerStreamForClient[v1.OrderBookResponse], error) {
+	return c.orderBook.CallServerStream(ctx, req)
+}
+
 // AggrHandler is an implementation of the svc.api.v1.Aggr service.
 type AggrHandler interface {
 	Candlesticks1MStream(context.Context, *connect.BidiStream[v1.Candlesticks1MStreamRequest, v1.Candlesticks1MStreamResponse]) error
+	CandlesticksHistory(context.Context, *connect.Request[v1.CandlesticksHistoryRequest]) (*connect.Response[v1.CandlesticksHistoryResponse], error)
+	Alerts(context.Context, *connect.BidiStream[v1.AlertsRequest, v1.AlertsResponse]) error
+	OrderBook(context.Context, *connect.Request[v1.OrderBookRequest], *connect.ServerStream[v1.OrderBookResponse]) error
 }
 
 // NewAggrHandler builds an HTTP handler from the service implementation. It returns the path on
@@ -91,10 +148,34 @@ func NewAggrHandler(svc AggrHandler, opts ...connect.HandlerOption) (string, htt
 		connect.WithSchema(aggrMethods.ByName("Candlesticks1MStream")),
 		connect.WithHandlerOptions(opts...),
 	)
+	aggrCandlesticksHistoryHandler := connect.NewUnaryHandler(
+		AggrCandlesticksHistoryProcedure,
+		svc.CandlesticksHistory,
+		connect.WithSchema(aggrMethods.ByName("CandlesticksHistory")),
+		connect.WithHandlerOptions(opts...),
+	)
+	aggrAlertsHandler := connect.NewBidiStreamHandler(
+		AggrAlertsProcedure,
+		svc.Alerts,
+		connect.WithSchema(aggrMethods.ByName("Alerts")),
+		connect.WithHandlerOptions(opts...),
+	)
+	aggrOrderBookHandler := connect.NewServerStreamHandler(
+		AggrOrderBookProcedure,
+		svc.OrderBook,
+		connect.WithSchema(aggrMethods.ByName("OrderBook")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/svc.api.v1.Aggr/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case AggrCandlesticks1MStreamProcedure:
 			aggrCandlesticks1MStreamHandler.ServeHTTP(w, r)
+		case AggrCandlesticksHistoryProcedure:
+			aggrCandlesticksHistoryHandler.ServeHTTP(w, r)
+		case AggrAlertsProcedure:
+			aggrAlertsHandler.ServeHTTP(w, r)
+		case AggrOrderBookProcedure:
+			aggrOrderBookHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -107,3 +188,137 @@ type UnimplementedAggrHandler struct{}
 func (UnimplementedAggrHandler) Candlesticks1MStream(context.Context, *connect.BidiStream[v1.Candlesticks1MStreamRequest, v1.Candlesticks1MStreamResponse]) error {
 	return connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Aggr.Candlesticks1MStream is not implemented"))
 }
+
+func (UnimplementedAggrHandler) CandlesticksHistory(context.Context, *connect.Request[v1.CandlesticksHistoryRequest]) (*connect.Response[v1.CandlesticksHistoryResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Aggr.CandlesticksHistory is not implemented"))
+}
+
+func (UnimplementedAggrHandler) Alerts(context.Context, *connect.BidiStream[v1.AlertsRequest, v1.AlertsResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Aggr.Alerts is not implemented"))
+}
+
+func (UnimplementedAggrHandler) OrderBook(context.Context, *connect.Request[v1.OrderBookRequest], *connect.ServerStream[v1.OrderBookResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Aggr.OrderBook is not implemented"))
+}
+
+// AdminClient is a client for the svc.api.v1.Admin service.
+type AdminClient interface {
+	AddSymbols(context.Context, *connect.Request[v1.AddSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error)
+	RemoveSymbols(context.Context, *connect.Request[v1.RemoveSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error)
+	ListSymbols(context.Context, *connect.Request[v1.ListSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error)
+}
+
+// NewAdminClient constructs a client for the svc.api.v1.Admin service. By default, it uses the
+// Connect protocol with the binary Protobuf Codec, asks for gzipped responses, and sends
+// uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the connect.WithGRPC() or
+// connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewAdminClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) AdminClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	adminMethods := v1.File_api_v1_aggregator_proto.Services().ByName("Admin").Methods()
+	return &adminClient{
+		addSymbols: connect.NewClient[v1.AddSymbolsRequest, v1.SymbolsResponse](
+			httpClient,
+			baseURL+AdminAddSymbolsProcedure,
+			connect.WithSchema(adminMethods.ByName("AddSymbols")),
+			connect.WithClientOptions(opts...),
+		),
+		removeSymbols: connect.NewClient[v1.RemoveSymbolsRequest, v1.SymbolsResponse](
+			httpClient,
+			baseURL+AdminRemoveSymbolsProcedure,
+			connect.WithSchema(adminMethods.ByName("RemoveSymbols")),
+			connect.WithClientOptions(opts...),
+		),
+		listSymbols: connect.NewClient[v1.ListSymbolsRequest, v1.SymbolsResponse](
+			httpClient,
+			baseURL+AdminListSymbolsProcedure,
+			connect.WithSchema(adminMethods.ByName("ListSymbols")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// adminClient implements AdminClient.
+type adminClient struct {
+	addSymbols    *connect.Client[v1.AddSymbolsRequest, v1.SymbolsResponse]
+	removeSymbols *connect.Client[v1.RemoveSymbolsRequest, v1.SymbolsResponse]
+	listSymbols   *connect.Client[v1.ListSymbolsRequest, v1.SymbolsResponse]
+}
+
+// AddSymbols calls svc.api.v1.Admin.AddSymbols.
+func (c *adminClient) AddSymbols(ctx context.Context, req *connect.Request[v1.AddSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error) {
+	return c.addSymbols.CallUnary(ctx, req)
+}
+
+// RemoveSymbols calls svc.api.v1.Admin.RemoveSymbols.
+func (c *adminClient) RemoveSymbols(ctx context.Context, req *connect.Request[v1.RemoveSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error) {
+	return c.removeSymbols.CallUnary(ctx, req)
+}
+
+// ListSymbols calls svc.api.v1.Admin.ListSymbols.
+func (c *adminClient) ListSymbols(ctx context.Context, req *connect.Request[v1.ListSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error) {
+	return c.listSymbols.CallUnary(ctx, req)
+}
+
+// AdminHandler is an implementation of the svc.api.v1.Admin service.
+type AdminHandler interface {
+	AddSymbols(context.Context, *connect.Request[v1.AddSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error)
+	RemoveSymbols(context.Context, *connect.Request[v1.RemoveSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error)
+	ListSymbols(context.Context, *connect.Request[v1.ListSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error)
+}
+
+// NewAdminHandler builds an HTTP handler from the service implementation. It returns the path on
+// which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewAdminHandler(svc AdminHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	adminMethods := v1.File_api_v1_aggregator_proto.Services().ByName("Admin").Methods()
+	adminAddSymbolsHandler := connect.NewUnaryHandler(
+		AdminAddSymbolsProcedure,
+		svc.AddSymbols,
+		connect.WithSchema(adminMethods.ByName("AddSymbols")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminRemoveSymbolsHandler := connect.NewUnaryHandler(
+		AdminRemoveSymbolsProcedure,
+		svc.RemoveSymbols,
+		connect.WithSchema(adminMethods.ByName("RemoveSymbols")),
+		connect.WithHandlerOptions(opts...),
+	)
+	adminListSymbolsHandler := connect.NewUnaryHandler(
+		AdminListSymbolsProcedure,
+		svc.ListSymbols,
+		connect.WithSchema(adminMethods.ByName("ListSymbols")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/svc.api.v1.Admin/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case AdminAddSymbolsProcedure:
+			adminAddSymbolsHandler.ServeHTTP(w, r)
+		case AdminRemoveSymbolsProcedure:
+			adminRemoveSymbolsHandler.ServeHTTP(w, r)
+		case AdminListSymbolsProcedure:
+			adminListSymbolsHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedAdminHandler returns CodeUnimplemented from all methods.
+type UnimplementedAdminHandler struct{}
+
+func (UnimplementedAdminHandler) AddSymbols(context.Context, *connect.Request[v1.AddSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Admin.AddSymbols is not implemented"))
+}
+
+func (UnimplementedAdminHandler) RemoveSymbols(context.Context, *connect.Request[v1.RemoveSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Admin.RemoveSymbols is not implemented"))
+}
+
+func (UnimplementedAdminHandler) ListSymbols(context.Context, *connect.Request[v1.ListSymbolsRequest]) (*connect.Response[v1.SymbolsResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("svc.api.v1.Admin.ListSymbols is not implemented"))
+}
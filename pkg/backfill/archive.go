@@ -0,0 +1,150 @@
+package backfill
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+type Kind string
+
+const (
+	KindAggTrades Kind = "aggTrades"
+	KindKlines    Kind = "klines"
+)
+
+var (
+	ErrUnknownKind    = errors.New("unknown archive kind, supported: aggTrades, klines")
+	ErrMalformedKline = errors.New("malformed kline row")
+
+	// SYMBOL-aggTrades-2025-01-24.zip, SYMBOL-1m-2025-01.zip
+	archiveNameRe = regexp.MustCompile(`^([A-Z0-9]+)-(aggTrades|1m)-([0-9]{4}-[0-9]{2}(?:-[0-9]{2})?)\.(zip|csv)$`)
+)
+
+// Archive is a bulk download file of binance covering trades or klines of [Start, End)
+type Archive struct {
+	Path  string
+	Kind  Kind
+	Start time.Time
+	End   time.Time
+}
+
+// FindArchives walks dir for daily or monthly archives of symbol overlapping [start, end),
+// sorted by the period they cover, daily archives come before the monthly one they overlap
+func FindArchives(dir, symbol string, kind Kind, start, end time.Time) ([]Archive, error) {
+	var want string
+	switch kind {
+	case KindAggTrades:
+		want = "aggTrades"
+	case KindKlines:
+		want = "1m"
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+	}
+
+	var archives []Archive
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		m := archiveNameRe.FindStringSubmatch(d.Name())
+		if m == nil || m[1] != symbol || m[2] != want {
+			return nil
+		}
+
+		a := Archive{Path: path, Kind: kind}
+		if len(m[3]) == len("2006-01") {
+			if a.Start, err = time.Parse("2006-01", m[3]); err != nil {
+				return nil
+			}
+			a.End = a.Start.AddDate(0, 1, 0)
+		} else {
+			if a.Start, err = time.Parse(time.DateOnly, m[3]); err != nil {
+				return nil
+			}
+			a.End = a.Start.AddDate(0, 0, 1)
+		}
+		if a.Start.Before(end) && a.End.After(start) {
+			archives = append(archives, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(archives, func(i, j int) bool {
+		if !archives[i].Start.Equal(archives[j].Start) {
+			return archives[i].Start.Before(archives[j].Start)
+		}
+		return archives[i].End.Before(archives[j].End)
+	})
+	return archives, nil
+}
+
+// klineDecoder reads 1m kline rows of open_time,open,high,low,close,volume,close_time,quote_volume,count,...
+// as bars, minutes without trades are skipped as the live aggregator wouldn't produce them
+type klineDecoder struct {
+	r *csv.Reader
+}
+
+func newKlineDecoder(r io.Reader) *klineDecoder {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &klineDecoder{r: cr}
+}
+
+func (d *klineDecoder) Next() (tradingchat.OHLCBar, error) {
+	for {
+		row, err := d.r.Read()
+		if err != nil {
+			return tradingchat.OHLCBar{}, err
+		}
+		if len(row) < 9 {
+			return tradingchat.OHLCBar{}, fmt.Errorf("%w: %v", ErrMalformedKline, row)
+		}
+		if _, err := strconv.ParseInt(row[0], 10, 64); err != nil {
+			// header row
+			continue
+		}
+		closeTime, err1 := strconv.ParseInt(row[6], 10, 64)
+		count, err2 := strconv.ParseInt(row[8], 10, 64)
+		if err := errors.Join(err1, err2); err != nil {
+			return tradingchat.OHLCBar{}, fmt.Errorf("%w: %v", ErrMalformedKline, row)
+		}
+		if count == 0 {
+			continue
+		}
+		return tradingchat.OHLCBar{
+			O: row[1],
+			H: row[2],
+			L: row[3],
+			C: row[4],
+			T: unixSeconds(closeTime),
+		}, nil
+	}
+}
+
+// unixSeconds normalizes timestamps of dumps in seconds, milliseconds or microseconds
+func unixSeconds(ts int64) int64 {
+	switch {
+	case ts >= 1e14:
+		return ts / 1e6
+	case ts >= 1e11:
+		return ts / 1e3
+	default:
+		return ts
+	}
+}
@@ -0,0 +1,147 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// Range is a span of minutes [Start, End)
+type Range struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type Report struct {
+	Symbol   string  `json:"symbol"`
+	Archives int     `json:"archives"`
+	Bars     int     `json:"bars"`     // bars upserted
+	Replaced int     `json:"replaced"` // upserted bars of minutes already stored
+	Filled   []Range `json:"filled"`   // minutes that were missing before the backfill
+}
+
+// Backfill aggregates archives of symbol into 1 minute bars within [start, end) and upserts them
+// into store, trades are aggregated with the same OHLCCalc logic as the live stream
+func Backfill(ctx context.Context, logger logr.Logger, store storage.BarStore, symbol string, archives []Archive, start, end time.Time) (Report, error) {
+	report := Report{Symbol: symbol, Archives: len(archives)}
+
+	existing, err := store.ListBars(ctx, symbol, tradingchat.Interval1M, start, end)
+	if err != nil {
+		return report, err
+	}
+	stored := make(map[int64]bool, len(existing))
+	for _, bar := range existing {
+		stored[bar.OpenTime().Unix()] = true
+	}
+
+	// the same minute may be covered by a daily and a monthly archive
+	saved := map[int64]bool{}
+	var filled []time.Time
+	save := func(bar tradingchat.OHLCBar) error {
+		t := bar.OpenTime()
+		if t.Before(start) || !t.Before(end) {
+			return nil
+		}
+		if err := store.SaveBar(ctx, symbol, bar); err != nil {
+			return err
+		}
+		report.Bars++
+		if stored[t.Unix()] {
+			report.Replaced++
+		} else if !saved[t.Unix()] {
+			filled = append(filled, t.UTC())
+		}
+		saved[t.Unix()] = true
+		return nil
+	}
+
+	for _, a := range archives {
+		logger.Info("backfilling archive", "file", a.Path, "kind", a.Kind)
+		var err error
+		switch a.Kind {
+		case KindAggTrades:
+			err = fromTrades(logger, a, symbol, save)
+		case KindKlines:
+			err = fromKlines(a, save)
+		default:
+			err = ErrUnknownKind
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	sort.Slice(filled, func(i, j int) bool { return filled[i].Before(filled[j]) })
+	report.Filled = toRanges(filled)
+	return report, nil
+}
+
+func fromTrades(logger logr.Logger, a Archive, symbol string, save func(tradingchat.OHLCBar) error) error {
+	dec, closer, err := tradingchat.OpenTradeFile(a.Path, tradingchat.FormatBinanceAggTrades, symbol)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	b := tradingchat.NewBarBuilder(logger)
+	for {
+		e, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if bar, ok := b.Add(e); ok {
+			if err := save(bar); err != nil {
+				return err
+			}
+		}
+	}
+	if bar, ok := b.Flush(); ok {
+		return save(bar)
+	}
+	return nil
+}
+
+func fromKlines(a Archive, save func(tradingchat.OHLCBar) error) error {
+	rd, closer, err := tradingchat.OpenDecompressed(a.Path)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	dec := newKlineDecoder(rd)
+	for {
+		bar, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := save(bar); err != nil {
+			return err
+		}
+	}
+}
+
+// toRanges merges minutes sorted in time into contiguous ranges
+func toRanges(minutes []time.Time) []Range {
+	var ranges []Range
+	for _, t := range minutes {
+		if n := len(ranges); n > 0 && ranges[n-1].End.Equal(t) {
+			ranges[n-1].End = t.Add(tradingchat.Interval1M)
+			continue
+		}
+		ranges = append(ranges, Range{Start: t, End: t.Add(tradingchat.Interval1M)})
+	}
+	return ranges
+}
@@ -0,0 +1,137 @@
+package backfill
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func writeZip(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create(filepath.Base(path[:len(path)-len(".zip")]) + ".csv")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+}
+
+func TestFindArchives(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"BNBBTC-aggTrades-2025-01-23.zip",
+		"spot/BNBBTC-aggTrades-2025-01-24.zip",
+		"BNBBTC-aggTrades-2025-01.zip",
+		"BNBBTC-1m-2025-01-24.zip",
+		"ETHBTC-aggTrades-2025-01-24.zip",
+		"BNBBTC-aggTrades-2025-01-24.zip.CHECKSUM",
+	} {
+		writeZip(t, filepath.Join(dir, name), "")
+	}
+	start := time.Date(2025, 1, 24, 0, 0, 0, 0, time.UTC)
+
+	t.Run("archives overlapping the range should be found in period order", func(t *testing.T) {
+		archives, err := FindArchives(dir, "BNBBTC", KindAggTrades, start, start.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		if assert.Len(t, archives, 2) {
+			assert.Equal(t, filepath.Join(dir, "BNBBTC-aggTrades-2025-01.zip"), archives[0].Path)
+			assert.Equal(t, filepath.Join(dir, "spot/BNBBTC-aggTrades-2025-01-24.zip"), archives[1].Path)
+			assert.Equal(t, start.AddDate(0, 0, 1), archives[1].End)
+		}
+	})
+
+	t.Run("klines should be found by interval", func(t *testing.T) {
+		archives, err := FindArchives(dir, "BNBBTC", KindKlines, start, start.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Len(t, archives, 1)
+	})
+
+	t.Run("unknown kind should be rejected", func(t *testing.T) {
+		_, err := FindArchives(dir, "BNBBTC", "trades", start, start.AddDate(0, 0, 1))
+		assert.ErrorIs(t, err, ErrUnknownKind)
+	})
+}
+
+func TestBackfill(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// 16:05 - 16:08 on Jan 24th 2025, nothing traded at 16:07
+	writeZip(t, filepath.Join(dir, "BNBBTC-aggTrades-2025-01-24.zip"), `agg_trade_id,price,quantity,first_trade_id,last_trade_id,transact_time,is_buyer_maker,is_best_match
+1,0.11111,1,1,1,1737734701000,true,true
+2,0.11121,1,2,2,1737734711000,true,true
+3,0.11104,1,3,3,1737734759000,true,true
+4,0.11101,1,4,4,1737734760000,true,true
+5,0.11131,1,5,5,1737734881000,true,true
+`)
+	writeZip(t, filepath.Join(dir, "BNBBTC-1m-2025-01-24.zip"), `1737734700000,0.11111,0.11121,0.11104,0.11104,3,1737734759999,0.3,3,1,0.1,0
+1737734760000,0.11101,0.11101,0.11101,0.11101,1,1737734819999,0.1,1,1,0.1,0
+1737734820000,0.11101,0.11101,0.11101,0.11101,0,1737734879999,0,0,0,0,0
+1737734880000,0.11131,0.11131,0.11131,0.11131,1,1737734939999,0.1,1,1,0.1,0
+`)
+	start := time.Date(2025, 1, 24, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	minute := func(m int) time.Time { return time.Date(2025, 1, 24, 16, m, 0, 0, time.UTC) }
+
+	t.Run("trades should be aggregated into missing bars", func(t *testing.T) {
+		store := storage.NewMemory(100)
+		assert.NoError(t, store.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: 1737734700}))
+
+		archives, err := FindArchives(dir, "BNBBTC", KindAggTrades, start, end)
+		assert.NoError(t, err)
+		report, err := Backfill(ctx, logger, store, "BNBBTC", archives, start, end)
+		assert.NoError(t, err)
+		assert.Equal(t, Report{
+			Symbol:   "BNBBTC",
+			Archives: 1,
+			Bars:     3,
+			Replaced: 1,
+			Filled: []Range{
+				{Start: minute(6), End: minute(7)},
+				{Start: minute(8), End: minute(9)},
+			},
+		}, report)
+
+		bars, err := store.ListBars(ctx, "BNBBTC", tradingchat.Interval1M, start, end)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{
+				{H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11104", T: 1737734700},
+				{H: "0.11101", L: "0.11101", O: "0.11101", C: "0.11101", T: 1737734760},
+				{H: "0.11131", L: "0.11131", O: "0.11131", C: "0.11131", T: 1737734880},
+			},
+			bars,
+		)
+	})
+
+	t.Run("klines should be upserted as bars skipping idle minutes", func(t *testing.T) {
+		store := storage.NewMemory(100)
+		archives, err := FindArchives(dir, "BNBBTC", KindKlines, start, end)
+		assert.NoError(t, err)
+		report, err := Backfill(ctx, logger, store, "BNBBTC", archives, start, end)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Bars)
+		assert.Equal(t, []Range{
+			{Start: minute(5), End: minute(7)},
+			{Start: minute(8), End: minute(9)},
+		}, report.Filled)
+
+		bars, err := store.ListBars(ctx, "BNBBTC", tradingchat.Interval1M, start, end)
+		assert.NoError(t, err)
+		assert.Equal(t, tradingchat.OHLCBar{H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11104", T: 1737734700}, bars[0])
+	})
+}
@@ -0,0 +1,291 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// VersionTable is shared with golang-migrate so `make mgrt` and the server agree on the schema version
+	VersionTable = "schema_migrations"
+
+	// same salt golang-migrate uses, the lock is held against it as well
+	advisoryLockIDSalt uint32 = 1486364155
+)
+
+var (
+	ErrDirty            = errors.New("database is dirty, fix the failed migration manually before migrating again")
+	ErrInvalidFileName  = errors.New("invalid migration file name")
+	ErrMissingMigration = errors.New("migration file is missing")
+
+	fileNameRe  = regexp.MustCompile(`^([0-9]+)_(.*)\.(up|down)\.sql$`)
+	statementRe = regexp.MustCompile(`;[ \t]*(\r?\n|$)`)
+)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Table   string
+	Version uint64 // 0 when nothing is applied
+	Dirty   bool
+	Applied []Migration
+	Pending []Migration
+}
+
+// Load reads migrations from fsys in golang-migrate's `{version}_{name}.{up|down}.sql` layout,
+// files not ending with .sql are ignored
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		match := fileNameRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, e.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFileName, e.Name())
+		}
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("%w: both up and down are required for version %d", ErrMissingMigration, m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Migrator applies migrations over a single connection, the session holds a postgres
+// advisory lock while migrating so replicas starting together run them only once
+type Migrator struct {
+	logger         logr.Logger
+	conn           *pgx.Conn
+	migrations     []Migration
+	table          string
+	multiStatement bool
+}
+
+// New creates a Migrator recording its version in table, with multiStatement every statement
+// of a migration is sent on its own, for statements that refuse to run inside a transaction block
+func New(logger logr.Logger, conn *pgx.Conn, fsys fs.FS, table string, multiStatement bool) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		logger:         logger,
+		conn:           conn,
+		migrations:     migrations,
+		table:          table,
+		multiStatement: multiStatement,
+	}, nil
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func() error {
+		version, dirty, err := m.version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+		for _, mg := range m.migrations {
+			if mg.Version <= version {
+				continue
+			}
+			m.logger.Info("applying migration", "version", mg.Version, "name", mg.Name)
+			if err := m.run(ctx, mg.Version, mg.Up, mg.Version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the latest n applied migrations, and returns how many of them were reverted
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func() error {
+		version, dirty, err := m.version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w: version %d", ErrDirty, version)
+		}
+		for i := len(m.migrations) - 1; i >= 0 && n > 0; i-- {
+			mg := m.migrations[i]
+			if mg.Version > version {
+				continue
+			}
+			var prev uint64
+			if i > 0 {
+				prev = m.migrations[i-1].Version
+			}
+			m.logger.Info("reverting migration", "version", mg.Version, "name", mg.Name)
+			if err := m.run(ctx, mg.Version, mg.Down, prev); err != nil {
+				return err
+			}
+			n--
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	var st Status
+	err := m.withLock(ctx, func() error {
+		version, dirty, err := m.version(ctx)
+		if err != nil {
+			return err
+		}
+		st.Table, st.Version, st.Dirty = m.table, version, dirty
+		for _, mg := range m.migrations {
+			if mg.Version <= version {
+				st.Applied = append(st.Applied, mg)
+			} else {
+				st.Pending = append(st.Pending, mg)
+			}
+		}
+		return nil
+	})
+	return st, err
+}
+
+// run executes script marking version dirty until it succeeds, then records target as the current version
+func (m *Migrator) run(ctx context.Context, version uint64, script string, target uint64) error {
+	if err := m.setVersion(ctx, version, true); err != nil {
+		return err
+	}
+	statements := []string{script}
+	if m.multiStatement {
+		statements = splitStatements(script)
+	}
+	for _, stmt := range statements {
+		if _, err := m.conn.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("migration %d failed: %w", version, err)
+		}
+	}
+	return m.setVersion(ctx, target, false)
+}
+
+// splitStatements splits script at semicolons that end a line
+func splitStatements(script string) []string {
+	var statements []string
+	for _, stmt := range statementRe.Split(script, -1) {
+		if strings.TrimSpace(stripComments(stmt)) != "" {
+			statements = append(statements, stmt)
+		}
+	}
+	return statements
+}
+
+func stripComments(stmt string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(stmt, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	var db, schema string
+	if err := m.conn.QueryRow(ctx, "SELECT current_database(), current_schema()").Scan(&db, &schema); err != nil {
+		return err
+	}
+	id := advisoryLockID(db, schema, m.table)
+
+	m.logger.V(2).Info("acquiring migration lock", "lock_id", id)
+	if _, err := m.conn.Exec(ctx, "SELECT pg_advisory_lock($1)", id); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := m.conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", id); err != nil {
+			m.logger.Error(err, "failed to release migration lock", "lock_id", id)
+		}
+	}()
+
+	if _, err := m.conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS `+m.table+` (version bigint not null primary key, dirty boolean not null)`); err != nil {
+		return err
+	}
+	return fn()
+}
+
+func (m *Migrator) version(ctx context.Context) (uint64, bool, error) {
+	var version int64
+	var dirty bool
+	err := m.conn.QueryRow(ctx, `SELECT version, dirty FROM `+m.table+` LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	return uint64(version), dirty, err
+}
+
+func (m *Migrator) setVersion(ctx context.Context, version uint64, dirty bool) error {
+	return pgx.BeginFunc(ctx, m.conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `TRUNCATE `+m.table); err != nil {
+			return err
+		}
+		// golang-migrate treats an empty table as nothing applied
+		if version == 0 && !dirty {
+			return nil
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO `+m.table+` (version, dirty) VALUES ($1, $2)`, int64(version), dirty)
+		return err
+	})
+}
+
+// advisoryLockID derives the lock key the same way golang-migrate's postgres driver does
+func advisoryLockID(db string, names ...string) int64 {
+	sum := crc32.ChecksumIEEE([]byte(strings.Join(append(names, db), "\x00")))
+	return int64(sum * advisoryLockIDSalt)
+}
@@ -0,0 +1,75 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/sql/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("migrations should be paired and ordered by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000002_add-col.up.sql":       {Data: []byte("ALTER 2")},
+			"000002_add-col.down.sql":     {Data: []byte("REVERT 2")},
+			"000001_init-schema.up.sql":   {Data: []byte("CREATE 1")},
+			"000001_init-schema.down.sql": {Data: []byte("DROP 1")},
+			"migrations.go":               {Data: []byte("package migrations")},
+		}
+
+		ms, err := Load(fsys)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]Migration{
+				{Version: 1, Name: "init-schema", Up: "CREATE 1", Down: "DROP 1"},
+				{Version: 2, Name: "add-col", Up: "ALTER 2", Down: "REVERT 2"},
+			},
+			ms,
+		)
+	})
+
+	t.Run("migration without down file should fail", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"000001_init-schema.up.sql": {Data: []byte("CREATE 1")},
+		})
+		assert.ErrorIs(t, err, ErrMissingMigration)
+	})
+
+	t.Run("malformed file name should fail", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"init-schema.sql": {Data: []byte("CREATE 1")},
+		})
+		assert.ErrorIs(t, err, ErrInvalidFileName)
+	})
+
+	t.Run("embedded migrations should load", func(t *testing.T) {
+		ms, err := Load(migrations.FS)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ms)
+		assert.Equal(t, uint64(1), ms[0].Version)
+	})
+}
+
+func TestAdvisoryLockID(t *testing.T) {
+	t.Run("lock id should be stable and positive", func(t *testing.T) {
+		id := advisoryLockID("database_name", "public", VersionTable)
+		assert.Equal(t, id, advisoryLockID("database_name", "public", VersionTable))
+		assert.Greater(t, id, int64(0))
+		assert.NotEqual(t, id, advisoryLockID("other_database", "public", VersionTable))
+	})
+}
+
+func TestSplitStatements(t *testing.T) {
+	t.Run("statements should be split at the end of lines", func(t *testing.T) {
+		script := "-- leading comment\nCREATE MATERIALIZED VIEW v AS\nSELECT 1;\n\nSELECT add_policy('v', start_offset => INTERVAL '1 hour');\n-- trailing comment\n"
+		assert.Equal(t,
+			[]string{
+				"-- leading comment\nCREATE MATERIALIZED VIEW v AS\nSELECT 1",
+				"\nSELECT add_policy('v', start_offset => INTERVAL '1 hour')",
+			},
+			splitStatements(script),
+		)
+	})
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+	"github.com/rickliujh/trading-chat-aggr/pkg/utils"
+)
+
+const (
+	maxActivityBars = 8
+	// closed activity bars waiting to be saved, the relay blocks once it's full
+	activitySaveBuffer = 500
+)
+
+var ErrActivityBarsUnsupported = connect.NewError(connect.CodeUnimplemented, errors.New("storage doesn't keep activity bars"))
+
+var activityTypes = map[apiv1.ActivityBarType]tradingchat.ActivityType{
+	apiv1.ActivityBarType_ACTIVITY_BAR_TYPE_TICK:   tradingchat.ActivityTick,
+	apiv1.ActivityBarType_ACTIVITY_BAR_TYPE_VOLUME: tradingchat.ActivityVolume,
+	apiv1.ActivityBarType_ACTIVITY_BAR_TYPE_DOLLAR: tradingchat.ActivityDollar,
+	apiv1.ActivityBarType_ACTIVITY_BAR_TYPE_RENKO:  tradingchat.ActivityRenko,
+	apiv1.ActivityBarType_ACTIVITY_BAR_TYPE_RANGE:  tradingchat.ActivityRange,
+}
+
+func toActivitySpecs(pbs []*apiv1.ActivityBarSpec) ([]tradingchat.ActivitySpec, error) {
+	if len(pbs) > maxActivityBars {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("at most %d activity bars are allowed", maxActivityBars))
+	}
+	specs := make([]tradingchat.ActivitySpec, 0, len(pbs))
+	for _, pb := range pbs {
+		spec := tradingchat.ActivitySpec{Type: activityTypes[pb.GetType()], Threshold: pb.GetThreshold()}
+		if err := spec.Validate(); err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// subscribeActivityBars builds activity bars of symbols for strm. Non-empty specs replace activity bars of
+// earlier requests, otherwise symbols without activity bars yet get the ones of earlier requests
+func (s *Service) subscribeActivityBars(strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse], symbols []string, specs []tradingchat.ActivitySpec) error {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	sub := s.subscriptions[strm]
+	replace := len(specs) > 0
+	if !replace && (sub == nil || len(sub.activitySpecs) == 0) {
+		return nil
+	}
+	if sub == nil {
+		sub = &subscription{}
+		s.subscriptions[strm] = sub
+	}
+	if sub.activity == nil {
+		sub.activity = map[string][]tradingchat.ActivitySpec{}
+	}
+	if replace {
+		s.removeActivityBars(sub)
+		sub.activitySpecs = specs
+	}
+
+	for _, symbol := range symbols {
+		if len(sub.activity[symbol]) > 0 {
+			continue
+		}
+		for _, spec := range sub.activitySpecs {
+			if err := s.aggr.AddActivityBars(symbol, spec); err != nil {
+				return connect.NewError(connect.CodeInvalidArgument, err)
+			}
+			sub.activity[symbol] = append(sub.activity[symbol], spec)
+		}
+	}
+	return nil
+}
+
+// removeActivityBars stops activity bars of sub, callers hold s.rw
+func (s *Service) removeActivityBars(sub *subscription) {
+	for symbol, specs := range sub.activity {
+		for _, spec := range specs {
+			s.aggr.RemoveActivityBars(symbol, spec)
+		}
+	}
+	sub.activity = map[string][]tradingchat.ActivitySpec{}
+}
+
+// relayActivityBars saves closed bars of specs built for every symbol into the store if persist is set, and
+// relays activity bars to the push goroutine if push is set, which sends them to subscribers asked for them.
+// Bars are saved in the background, so a slow store doesn't hold pushes back
+func (s *Service) relayActivityBars(done <-chan struct{}, pinned []tradingchat.ActivitySpec, push, persist bool) <-chan tradingchat.ActivityUpdate {
+	store, ok := s.store.(storage.ActivityBarStore)
+	if persist && len(pinned) > 0 && !ok {
+		s.logger.Info("storage doesn't support activity bars, they won't be persisted")
+	}
+	persist = persist && ok && len(pinned) > 0
+
+	var saveCh chan tradingchat.ActivityUpdate
+	if persist {
+		saveCh = make(chan tradingchat.ActivityUpdate, activitySaveBuffer)
+		go func() {
+			for u := range saveCh {
+				ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+				err := store.SaveActivityBar(ctx, u.Symbol, u.Spec, u.Bar)
+				cancel()
+				if err != nil {
+					s.logger.Error(err, "failed to persist activity bar", "symbol", u.Symbol, "spec", u.Spec, "bar", u.Bar)
+				}
+			}
+		}()
+	}
+
+	relay := make(chan tradingchat.ActivityUpdate, 500)
+	go func() {
+		defer close(relay)
+		if saveCh != nil {
+			defer close(saveCh)
+		}
+		for u := range utils.OrDone(done, s.aggr.ActivityBars()) {
+			if persist && u.Closed && slices.Contains(pinned, u.Spec) {
+				if len(saveCh) == cap(saveCh) {
+					s.logger.V(1).Info("activity bars are saved slower than they close", "symbol", u.Symbol, "spec", u.Spec)
+				}
+				select {
+				case saveCh <- u:
+				case <-done:
+					return
+				}
+			}
+			if !push {
+				continue
+			}
+			select {
+			case relay <- u:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return relay
+}
+
+// activityHistory lists closed activity bars of spec and symbol that closed within [start, end)
+func (s *Service) activityHistory(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, start, end time.Time) (*connect.Response[apiv1.CandlesticksHistoryResponse], error) {
+	store, ok := s.store.(storage.ActivityBarStore)
+	if !ok {
+		return nil, ErrActivityBarsUnsupported
+	}
+	bars, err := store.ListActivityBars(ctx, symbol, spec, start, end)
+	if err != nil {
+		s.logger.Error(err, "failed to list activity bars", "symbol", symbol, "spec", spec, "start", start, "end", end)
+		return nil, ErrHistoryUnavailable
+	}
+
+	res := &apiv1.CandlesticksHistoryResponse{
+		ActivityBars: make([]*apiv1.Candlesticks1MStreamResponse_ActivityBar, 0, len(bars)),
+	}
+	for _, bar := range bars {
+		res.ActivityBars = append(res.ActivityBars, toPBActivityBar(tradingchat.ActivityUpdate{Symbol: symbol, Spec: spec, Bar: bar, Closed: true}))
+	}
+	return connect.NewResponse(res), nil
+}
+
+// sendActivityBar sends u to subscribers of its symbol asked for its spec
+func (s *Service) sendActivityBar(u tradingchat.ActivityUpdate) {
+	var res *apiv1.Candlesticks1MStreamResponse
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	for _, to := range s.notifyList[u.Symbol] {
+		sub, ok := s.subscriptions[to]
+		if !ok || !slices.Contains(sub.activity[u.Symbol], u.Spec) {
+			continue
+		}
+		if res == nil {
+			res = &apiv1.Candlesticks1MStreamResponse{ActivityBar: toPBActivityBar(u)}
+		}
+		to.Send(res)
+	}
+}
+
+func toPBActivityBar(u tradingchat.ActivityUpdate) *apiv1.Candlesticks1MStreamResponse_ActivityBar {
+	return &apiv1.Candlesticks1MStreamResponse_ActivityBar{
+		Symbol:   u.Symbol,
+		Name:     u.Spec.Name(),
+		Bar:      toPBBar(u.Bar.OHLCBar),
+		OpenTime: timestamppb.New(time.Unix(u.Bar.OpenT, 0)),
+		FirstId:  u.Bar.FirstID,
+		Seq:      u.Bar.Seq,
+		LastId:   u.Bar.LastID,
+		Trades:   u.Bar.Trades,
+		Volume:   u.Bar.Volume,
+		Notional: u.Bar.Notional,
+		Closed:   u.Closed,
+	}
+}
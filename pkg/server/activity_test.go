@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// blockingActivityStore holds saves back until it's released
+type blockingActivityStore struct {
+	*storage.Memory
+	release chan struct{}
+}
+
+func (b *blockingActivityStore) SaveActivityBar(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, bar tradingchat.ActivityBar) error {
+	<-b.release
+	return b.Memory.SaveActivityBar(ctx, symbol, spec, bar)
+}
+
+func TestActivityBars(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+	tick := tradingchat.ActivitySpec{Type: tradingchat.ActivityTick, Threshold: 2}
+
+	// aggregates trades of BNBBTC with tick bars of 2 trades built for every symbol
+	newService := func(t *testing.T, store storage.BarStore) (*Service, chan<- *bconn.WsAggTradeEvent, chan struct{}) {
+		done := make(chan struct{})
+		t.Cleanup(func() { close(done) })
+		stream := make(chan *bconn.WsAggTradeEvent)
+		aggr, _, _ := tradingchat.NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, tradingchat.AggrOptions{Shards: 1, ActivityBars: []tradingchat.ActivitySpec{tick}})
+		s := &Service{logger: logger, clock: tradingchat.NewFakeClock(time.Unix(inittime+60, 0)), store: store, history: true, aggr: aggr, rw: &sync.RWMutex{}}
+		return s, stream, done
+	}
+	trade := func(id int64) *bconn.WsAggTradeEvent {
+		return &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: id, Price: "0.11111", Quantity: "1", TradeTime: (inittime + id) * 1000}
+	}
+
+	t.Run("closed bars should be saved and listed by history", func(t *testing.T) {
+		store := storage.NewMemory(10)
+		s, stream, done := newService(t, store)
+		s.relayActivityBars(done, []tradingchat.ActivitySpec{tick}, false, true)
+		for id := int64(1); id <= 3; id++ {
+			stream <- trade(id)
+		}
+
+		req := connect.NewRequest(&apiv1.CandlesticksHistoryRequest{
+			Symbol:      "BNBBTC",
+			Start:       timestamppb.New(time.Unix(inittime, 0)),
+			ActivityBar: &apiv1.ActivityBarSpec{Type: apiv1.ActivityBarType_ACTIVITY_BAR_TYPE_TICK, Threshold: 2},
+		})
+		var res *connect.Response[apiv1.CandlesticksHistoryResponse]
+		assert.Eventually(t, func() bool {
+			var err error
+			res, err = s.CandlesticksHistory(ctx, req)
+			return err == nil && len(res.Msg.GetActivityBars()) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		bar := res.Msg.GetActivityBars()[0]
+		assert.Equal(t, "tick_2", bar.GetName())
+		assert.Equal(t, int64(1), bar.GetFirstId())
+		assert.Equal(t, int64(2), bar.GetLastId())
+		assert.True(t, bar.GetClosed())
+		assert.Empty(t, res.Msg.GetBars())
+	})
+
+	t.Run("slow store should not hold pushes back", func(t *testing.T) {
+		store := &blockingActivityStore{Memory: storage.NewMemory(10), release: make(chan struct{})}
+		s, stream, done := newService(t, store)
+		relay := s.relayActivityBars(done, []tradingchat.ActivitySpec{tick}, true, true)
+		for id := int64(1); id <= 3; id++ {
+			stream <- trade(id)
+		}
+		for id := int64(1); id <= 3; id++ {
+			select {
+			case u := <-relay:
+				assert.Equal(t, id, u.Bar.LastID)
+			case <-time.After(time.Second):
+				t.Fatal("activity bars aren't relayed while the store is busy")
+			}
+		}
+
+		close(store.release)
+		assert.Eventually(t, func() bool {
+			bars, _ := store.ListActivityBars(ctx, "BNBBTC", tick, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+			return len(bars) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("history of activity bars should need a store keeping them", func(t *testing.T) {
+		// keeps 1 minute bars only
+		s, _, _ := newService(t, struct{ storage.BarStore }{storage.NewMemory(10)})
+		_, err := s.activityHistory(ctx, "BNBBTC", tick, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.ErrorIs(t, err, ErrActivityBarsUnsupported)
+	})
+}
+
+func TestSubscribeActivityBars(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+	tick2 := tradingchat.ActivitySpec{Type: tradingchat.ActivityTick, Threshold: 2}
+	tick3 := tradingchat.ActivitySpec{Type: tradingchat.ActivityTick, Threshold: 3}
+
+	newService := func(t *testing.T) (*Service, chan<- *bconn.WsAggTradeEvent) {
+		done := make(chan struct{})
+		t.Cleanup(func() { close(done) })
+		stream := make(chan *bconn.WsAggTradeEvent)
+		aggr, _, _ := tradingchat.NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC", "ETHBTC"}, tradingchat.AggrOptions{Shards: 1})
+		s := &Service{
+			logger:        logger,
+			clock:         tradingchat.NewFakeClock(time.Unix(inittime+60, 0)),
+			aggr:          aggr,
+			subscriptions: map[*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]]*subscription{},
+			rw:            &sync.RWMutex{},
+		}
+		return s, stream
+	}
+	// streams are only told apart by their address here
+	newStream := func() *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse] {
+		return &connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]{}
+	}
+	// trades BNBBTC n times, and returns specs of activity bars built on them
+	built := func(t *testing.T, s *Service, stream chan<- *bconn.WsAggTradeEvent, n int64) map[tradingchat.ActivitySpec]bool {
+		t.Helper()
+		for id := int64(1); id <= n; id++ {
+			stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: id, Price: "0.11111", Quantity: "1", TradeTime: (inittime + id) * 1000}
+		}
+		assert.Eventually(t, func() bool {
+			bar, err := s.aggr.OHLCBar("BNBBTC")
+			return err == nil && bar.T == inittime+n
+		}, time.Second, 10*time.Millisecond)
+
+		specs := map[tradingchat.ActivitySpec]bool{}
+		for {
+			select {
+			case u := <-s.aggr.ActivityBars():
+				specs[u.Spec] = true
+			default:
+				return specs
+			}
+		}
+	}
+
+	t.Run("requests without activity bars should add none", func(t *testing.T) {
+		s, _ := newService(t)
+		assert.NoError(t, s.subscribeActivityBars(newStream(), []string{"BNBBTC"}, nil))
+		assert.Empty(t, s.subscriptions)
+	})
+
+	t.Run("later symbols should get activity bars of earlier requests", func(t *testing.T) {
+		s, _ := newService(t)
+		strm := newStream()
+		assert.NoError(t, s.subscribeActivityBars(strm, []string{"BNBBTC"}, []tradingchat.ActivitySpec{tick2}))
+		assert.NoError(t, s.subscribeActivityBars(strm, []string{"BNBBTC", "ETHBTC"}, nil))
+
+		sub := s.subscriptions[strm]
+		assert.Equal(t, []tradingchat.ActivitySpec{tick2}, sub.activitySpecs)
+		assert.Equal(t, map[string][]tradingchat.ActivitySpec{
+			"BNBBTC": {tick2},
+			"ETHBTC": {tick2},
+		}, sub.activity, "symbols having activity bars aren't added twice")
+	})
+
+	t.Run("new activity bars should replace the ones of every symbol", func(t *testing.T) {
+		s, stream := newService(t)
+		strm := newStream()
+		assert.NoError(t, s.subscribeActivityBars(strm, []string{"BNBBTC", "ETHBTC"}, []tradingchat.ActivitySpec{tick2}))
+		assert.NoError(t, s.subscribeActivityBars(strm, []string{"BNBBTC"}, []tradingchat.ActivitySpec{tick3}))
+
+		sub := s.subscriptions[strm]
+		assert.Equal(t, []tradingchat.ActivitySpec{tick3}, sub.activitySpecs)
+		assert.Equal(t, map[string][]tradingchat.ActivitySpec{"BNBBTC": {tick3}}, sub.activity)
+		assert.Equal(t, map[tradingchat.ActivitySpec]bool{tick3: true}, built(t, s, stream, 3), "replaced activity bars stop")
+	})
+
+	t.Run("activity bars shared with another stream should outlive unsubscribing", func(t *testing.T) {
+		s, stream := newService(t)
+		strm, other := newStream(), newStream()
+		assert.NoError(t, s.subscribeActivityBars(strm, []string{"BNBBTC"}, []tradingchat.ActivitySpec{tick2}))
+		assert.NoError(t, s.subscribeActivityBars(other, []string{"BNBBTC"}, []tradingchat.ActivitySpec{tick2, tick3}))
+
+		s.unsubscribe(other)
+		assert.Equal(t, map[tradingchat.ActivitySpec]bool{tick2: true}, built(t, s, stream, 3))
+	})
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	"connectrpc.com/connect"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/api/v1/apiv1connect"
+)
+
+var (
+	_ apiv1connect.AdminHandler = (*Admin)(nil)
+
+	ErrUnauthenticated = connect.NewError(connect.CodeUnauthenticated, errors.New("invalid admin token"))
+)
+
+// Admin manages symbols of a running Service, requests have to carry `Authorization: Bearer <token>`
+// unless token is empty
+type Admin struct {
+	s     *Service
+	token string
+}
+
+func NewAdmin(s *Service, token string) *Admin {
+	return &Admin{s: s, token: token}
+}
+
+// AddSymbols implements apiv1connect.AdminHandler.
+func (a *Admin) AddSymbols(ctx context.Context, req *connect.Request[apiv1.AddSymbolsRequest]) (*connect.Response[apiv1.SymbolsResponse], error) {
+	if err := a.authorize(req); err != nil {
+		return nil, err
+	}
+	if len(req.Msg.GetSymbols()) == 0 {
+		return nil, ErrInvalidRequest
+	}
+	added, err := a.s.addSymbols(req.Msg.GetSymbols(), false)
+	if err != nil {
+		return nil, err
+	}
+	return a.symbols(added), nil
+}
+
+// RemoveSymbols implements apiv1connect.AdminHandler.
+func (a *Admin) RemoveSymbols(ctx context.Context, req *connect.Request[apiv1.RemoveSymbolsRequest]) (*connect.Response[apiv1.SymbolsResponse], error) {
+	if err := a.authorize(req); err != nil {
+		return nil, err
+	}
+	if len(req.Msg.GetSymbols()) == 0 {
+		return nil, ErrInvalidRequest
+	}
+	removed, err := a.s.removeSymbols(req.Msg.GetSymbols())
+	if err != nil {
+		return nil, err
+	}
+	return a.symbols(removed), nil
+}
+
+// ListSymbols implements apiv1connect.AdminHandler.
+func (a *Admin) ListSymbols(ctx context.Context, req *connect.Request[apiv1.ListSymbolsRequest]) (*connect.Response[apiv1.SymbolsResponse], error) {
+	if err := a.authorize(req); err != nil {
+		return nil, err
+	}
+	return a.symbols(nil), nil
+}
+
+func (a *Admin) authorize(req connect.AnyRequest) error {
+	if a.token == "" {
+		return nil
+	}
+	got := req.Header().Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(got), []byte("Bearer "+a.token)) != 1 {
+		return ErrUnauthenticated
+	}
+	return nil
+}
+
+func (a *Admin) symbols(changed []string) *connect.Response[apiv1.SymbolsResponse] {
+	return connect.NewResponse(&apiv1.SymbolsResponse{
+		Symbols: a.s.aggr.Symbols(),
+		Changed: changed,
+	})
+}
@@ -0,0 +1,206 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// alerts of a request id nobody streams are kept this long
+const alertOwnerTTL = 24 * time.Hour
+
+var ErrAlertsDisabled = connect.NewError(connect.CodeUnimplemented, errors.New("alerts need push to be enabled"))
+
+var alertKinds = map[apiv1.AlertKind]tradingchat.AlertKind{
+	apiv1.AlertKind_ALERT_KIND_CROSS_ABOVE:  tradingchat.AlertCrossAbove,
+	apiv1.AlertKind_ALERT_KIND_CROSS_BELOW:  tradingchat.AlertCrossBelow,
+	apiv1.AlertKind_ALERT_KIND_MOVE:         tradingchat.AlertMove,
+	apiv1.AlertKind_ALERT_KIND_VOLUME_SPIKE: tradingchat.AlertVolumeSpike,
+}
+
+// alertStream serializes responses of the request handler and events fired by the push goroutine
+type alertStream struct {
+	mu   *sync.Mutex
+	strm *connect.BidiStream[apiv1.AlertsRequest, apiv1.AlertsResponse]
+}
+
+func (a *alertStream) send(res *apiv1.AlertsResponse) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.strm.Send(res)
+}
+
+// Alerts implements apiv1connect.AggrHandler.
+func (s *Service) Alerts(ctx context.Context, strm *connect.BidiStream[apiv1.AlertsRequest, apiv1.AlertsResponse]) error {
+	if s.alerts == nil {
+		return ErrAlertsDisabled
+	}
+	var id string
+	var to *alertStream
+	defer func() {
+		if to != nil {
+			s.detachAlerts(id, to)
+			s.logger.V(2).Info("alert subscriber disconnected", "req_id", id)
+		}
+	}()
+	for {
+		req, err := strm.Receive()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			s.logger.Error(err, "error when reading alert request")
+			return ErrNotRevieved
+		}
+
+		reqID := req.GetRequestId()
+		if reqID == "" || (id != "" && reqID != id) {
+			s.logger.Info("found invalid alert request disconnecting with client", "req_id", id, "req_id_new", reqID)
+			return ErrInvalidRequest
+		}
+		if to == nil {
+			id = reqID
+			to = s.attachAlerts(id, strm)
+		}
+
+		res, err := s.alertAction(id, req)
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			continue
+		}
+		if err := to.send(res); err != nil {
+			return err
+		}
+	}
+}
+
+// alertAction carries out the action of req for alerts of id
+func (s *Service) alertAction(id string, req *apiv1.AlertsRequest) (*apiv1.AlertsResponse, error) {
+	switch action := req.GetAction().(type) {
+	case *apiv1.AlertsRequest_Add:
+		spec := tradingchat.AlertSpec{
+			Symbol:   action.Add.GetSymbol(),
+			Kind:     alertKinds[action.Add.GetKind()],
+			Level:    action.Add.GetLevel(),
+			Lookback: int(action.Add.GetLookback()),
+		}
+		if !s.isSymbolRegistered([]string{spec.Symbol}) {
+			return nil, ErrSymbolsNotSupported
+		}
+		alert, err := s.alerts.Add(id, spec)
+		if errors.Is(err, tradingchat.ErrTooManyAlerts) {
+			return nil, connect.NewError(connect.CodeResourceExhausted, err)
+		}
+		if err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		s.logger.Info("alert added", "req_id", id, "alert", alert)
+		return &apiv1.AlertsResponse{Added: toPBAlert(alert)}, nil
+	case *apiv1.AlertsRequest_Cancel:
+		if err := s.alerts.Cancel(id, action.Cancel); err != nil {
+			return nil, connect.NewError(connect.CodeNotFound, err)
+		}
+		return &apiv1.AlertsResponse{Cancelled: action.Cancel}, nil
+	case *apiv1.AlertsRequest_List:
+		alerts := s.alerts.List(id)
+		res := &apiv1.AlertsResponse{Alerts: make([]*apiv1.AlertsResponse_Alert, 0, len(alerts))}
+		for _, alert := range alerts {
+			res.Alerts = append(res.Alerts, toPBAlert(alert))
+		}
+		return res, nil
+	default:
+		// only attaching
+		return nil, nil
+	}
+}
+
+// attachAlerts sends events of id to strm from now on, starting with the ones fired while nobody streamed them
+func (s *Service) attachAlerts(id string, strm *connect.BidiStream[apiv1.AlertsRequest, apiv1.AlertsResponse]) *alertStream {
+	to := &alertStream{mu: &sync.Mutex{}, strm: strm}
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+	held := s.alerts.Attach(id)
+	s.alertStreams[id] = to
+	for _, ev := range held {
+		to.send(&apiv1.AlertsResponse{Fired: toPBFired(ev)})
+	}
+	s.logger.Info("alert subscriber attached", "req_id", id, "held", len(held))
+	return to
+}
+
+// detachAlerts stops sending events of id to to, unless a newer stream of id took over
+func (s *Service) detachAlerts(id string, to *alertStream) {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+	if s.alertStreams[id] != to {
+		return
+	}
+	delete(s.alertStreams, id)
+	s.alerts.Detach(id)
+}
+
+// fireAlerts evaluates alerts on the latest state of symbol, events of request ids nobody streams are held
+func (s *Service) fireAlerts(symbol string) {
+	if s.alerts == nil {
+		return
+	}
+	st, err := s.aggr.State(symbol)
+	if err != nil {
+		return
+	}
+	events := s.alerts.Update(symbol, st)
+	if len(events) == 0 {
+		return
+	}
+
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+	for _, ev := range events {
+		s.logger.V(2).Info("alert fired", "req_id", ev.Owner, "alert", ev.Alert.ID, "value", ev.Value)
+		to, ok := s.alertStreams[ev.Owner]
+		if !ok || to.send(&apiv1.AlertsResponse{Fired: toPBFired(ev)}) != nil {
+			s.alerts.Hold(ev)
+		}
+	}
+}
+
+func toPBAlert(alert tradingchat.Alert) *apiv1.AlertsResponse_Alert {
+	pb := &apiv1.AlertsResponse_Alert{
+		Id: alert.ID,
+		Spec: &apiv1.AlertSpec{
+			Symbol:   alert.Spec.Symbol,
+			Level:    alert.Spec.Level,
+			Lookback: int32(alert.Spec.Lookback),
+		},
+		CreatedAt: timestamppb.New(alert.CreatedAt),
+		Fired:     alert.Fired,
+	}
+	for kind, k := range alertKinds {
+		if k == alert.Spec.Kind {
+			pb.Spec.Kind = kind
+		}
+	}
+	if !alert.LastFired.IsZero() {
+		pb.LastFired = timestamppb.New(alert.LastFired)
+	}
+	return pb
+}
+
+func toPBFired(ev tradingchat.AlertEvent) *apiv1.AlertsResponse_Fired {
+	return &apiv1.AlertsResponse_Fired{
+		Alert: toPBAlert(ev.Alert),
+		Bar:   toPBBar(ev.Bar),
+		Value: ev.Value,
+		At:    timestamppb.New(ev.At),
+	}
+}
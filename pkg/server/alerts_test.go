@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/api/v1/apiv1connect"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestAlerts(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+	move := &apiv1.AlertSpec{Symbol: "BNBBTC", Kind: apiv1.AlertKind_ALERT_KIND_MOVE, Level: 50}
+
+	// serves alerts of BNBBTC over HTTP/2, as alert streams are bidirectional
+	newService := func(t *testing.T) (*Service, apiv1connect.AggrClient, chan<- *bconn.WsAggTradeEvent) {
+		done := make(chan struct{})
+		t.Cleanup(func() { close(done) })
+		stream := make(chan *bconn.WsAggTradeEvent)
+		aggr, _, _ := tradingchat.NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, tradingchat.AggrOptions{Shards: 1})
+		clock := tradingchat.NewFakeClock(time.Unix(inittime+30, 0))
+		s := &Service{
+			logger:       logger,
+			clock:        clock,
+			aggr:         aggr,
+			rw:           &sync.RWMutex{},
+			alerts:       tradingchat.NewAlertBook(clock, alertOwnerTTL),
+			alertStreams: map[string]*alertStream{},
+			alertsMu:     &sync.Mutex{},
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(apiv1connect.NewAggrHandler(s))
+		srv := httptest.NewUnstartedServer(mux)
+		srv.EnableHTTP2 = true
+		srv.StartTLS()
+		t.Cleanup(srv.Close)
+		return s, apiv1connect.NewAggrClient(srv.Client(), srv.URL), stream
+	}
+	// moves BNBBTC from 1 to 2 within the bar in progress, and fires alerts on it
+	moveUp := func(t *testing.T, s *Service, stream chan<- *bconn.WsAggTradeEvent) {
+		t.Helper()
+		stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 1, Price: "1", Quantity: "1", TradeTime: (inittime + 1) * 1000}
+		stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 2, Price: "2", Quantity: "1", TradeTime: (inittime + 2) * 1000}
+		assert.Eventually(t, func() bool {
+			bar, err := s.aggr.OHLCBar("BNBBTC")
+			return err == nil && bar.C == "2"
+		}, time.Second, 10*time.Millisecond)
+		s.fireAlerts("BNBBTC")
+	}
+	// sends req and receives its response
+	roundTrip := func(t *testing.T, strm *connect.BidiStreamForClient[apiv1.AlertsRequest, apiv1.AlertsResponse], req *apiv1.AlertsRequest) *apiv1.AlertsResponse {
+		t.Helper()
+		assert.NoError(t, strm.Send(req))
+		res, err := strm.Receive()
+		assert.NoError(t, err)
+		return res
+	}
+	// closes strm and waits until the server is done with it
+	hangUp := func(t *testing.T, strm *connect.BidiStreamForClient[apiv1.AlertsRequest, apiv1.AlertsResponse]) {
+		t.Helper()
+		assert.NoError(t, strm.CloseRequest())
+		_, err := strm.Receive()
+		assert.True(t, errors.Is(err, io.EOF), "stream ends without errors, got %v", err)
+		assert.NoError(t, strm.CloseResponse())
+	}
+
+	t.Run("events fired while detached should be held for the request id", func(t *testing.T) {
+		s, client, stream := newService(t)
+
+		first := client.Alerts(ctx)
+		added := roundTrip(t, first, &apiv1.AlertsRequest{RequestId: "req", Action: &apiv1.AlertsRequest_Add{Add: move}}).GetAdded()
+		assert.NotEmpty(t, added.GetId())
+		hangUp(t, first)
+
+		moveUp(t, s, stream)
+
+		// attaching alone gets the held event back
+		second := client.Alerts(ctx)
+		defer hangUp(t, second)
+		fired := roundTrip(t, second, &apiv1.AlertsRequest{RequestId: "req"}).GetFired()
+		assert.Equal(t, added.GetId(), fired.GetAlert().GetId())
+		assert.Equal(t, 100.0, fired.GetValue())
+
+		alerts := roundTrip(t, second, &apiv1.AlertsRequest{RequestId: "req", Action: &apiv1.AlertsRequest_List{List: true}}).GetAlerts()
+		if assert.Len(t, alerts, 1) {
+			assert.Equal(t, added.GetId(), alerts[0].GetId())
+			assert.Equal(t, int64(1), alerts[0].GetFired())
+		}
+	})
+
+	t.Run("alerts of other request ids should be kept apart", func(t *testing.T) {
+		s, client, stream := newService(t)
+
+		first := client.Alerts(ctx)
+		roundTrip(t, first, &apiv1.AlertsRequest{RequestId: "req", Action: &apiv1.AlertsRequest_Add{Add: move}})
+		hangUp(t, first)
+		moveUp(t, s, stream)
+
+		other := client.Alerts(ctx)
+		defer hangUp(t, other)
+		res := roundTrip(t, other, &apiv1.AlertsRequest{RequestId: "other", Action: &apiv1.AlertsRequest_List{List: true}})
+		assert.Nil(t, res.GetFired())
+		assert.Empty(t, res.GetAlerts())
+	})
+
+	t.Run("newer stream of the request id should take events over", func(t *testing.T) {
+		s, client, stream := newService(t)
+
+		older := client.Alerts(ctx)
+		roundTrip(t, older, &apiv1.AlertsRequest{RequestId: "req", Action: &apiv1.AlertsRequest_List{List: true}})
+		newer := client.Alerts(ctx)
+		defer hangUp(t, newer)
+		added := roundTrip(t, newer, &apiv1.AlertsRequest{RequestId: "req", Action: &apiv1.AlertsRequest_Add{Add: move}}).GetAdded()
+
+		// the older stream leaving doesn't detach the newer one
+		hangUp(t, older)
+		moveUp(t, s, stream)
+
+		res, err := newer.Receive()
+		assert.NoError(t, err)
+		assert.Equal(t, added.GetId(), res.GetFired().GetAlert().GetId())
+	})
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+	"github.com/rickliujh/trading-chat-aggr/pkg/utils"
+)
+
+// book bars queued for a subscriber, later ones are dropped until it catches up
+const bookQueueSize = 100
+
+var ErrDepthDisabled = connect.NewError(connect.CodeUnimplemented, errors.New("order book stream is not enabled"))
+
+// ServeDepth relays book bars of symbols to subscribers of OrderBook, the stream is disabled until it's called
+func (s *Service) ServeDepth(done <-chan struct{}, symbols []string, bars <-chan tradingchat.BookBar) {
+	s.bookMu.Lock()
+	s.depthSymbols = symbols
+	s.bookMu.Unlock()
+
+	go func() {
+		for bar := range utils.OrDone(done, bars) {
+			res := &apiv1.OrderBookResponse{Bar: toPBBookBar(bar)}
+			s.bookMu.RLock()
+			for _, to := range s.bookSubs[bar.Symbol] {
+				select {
+				case to <- res:
+				default:
+					s.logger.V(1).Info("order book subscriber is behind, bar dropped", "symbol", bar.Symbol)
+				}
+			}
+			s.bookMu.RUnlock()
+		}
+	}()
+}
+
+// OrderBook implements apiv1connect.AggrHandler.
+func (s *Service) OrderBook(ctx context.Context, req *connect.Request[apiv1.OrderBookRequest], strm *connect.ServerStream[apiv1.OrderBookResponse]) error {
+	symbols := req.Msg.GetSymbols()
+	to := make(chan *apiv1.OrderBookResponse, bookQueueSize)
+
+	s.bookMu.Lock()
+	if s.depthSymbols == nil {
+		s.bookMu.Unlock()
+		return ErrDepthDisabled
+	}
+	if len(symbols) == 0 {
+		s.bookMu.Unlock()
+		return ErrInvalidRequest
+	}
+	for _, symbol := range symbols {
+		if !slices.Contains(s.depthSymbols, symbol) {
+			s.bookMu.Unlock()
+			return ErrSymbolsNotSupported
+		}
+	}
+	for _, symbol := range symbols {
+		s.bookSubs[symbol] = append(s.bookSubs[symbol], to)
+	}
+	s.bookMu.Unlock()
+	s.logger.Info("user registered for order book updates", "symbols", symbols)
+
+	defer func() {
+		s.bookMu.Lock()
+		defer s.bookMu.Unlock()
+		for _, symbol := range symbols {
+			s.bookSubs[symbol] = slices.DeleteFunc(s.bookSubs[symbol], func(c chan *apiv1.OrderBookResponse) bool { return c == to })
+		}
+		s.logger.V(2).Info("order book subscriber disconnected", "symbols", symbols)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case res := <-to:
+			if err := strm.Send(res); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toPBBookBar(bar tradingchat.BookBar) *apiv1.OrderBookResponse_Bar {
+	return &apiv1.OrderBookResponse_Bar{
+		Symbol:     bar.Symbol,
+		OpenTime:   timestamppb.New(time.Unix(bar.OpenTime, 0)),
+		Bid:        toPBBar(bar.Bid),
+		Ask:        toPBBar(bar.Ask),
+		Mid:        bar.Mid,
+		Spread:     bar.Spread,
+		MeanSpread: bar.MeanSpread,
+		Imbalance:  bar.Imbalance,
+		Samples:    bar.Samples,
+		Resyncs:    bar.Resyncs,
+	}
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// bars the Heikin-Ashi series is started from before the first bar it's asked for, as every Heikin-Ashi bar
+// depends on the one before it, the effect of the start fades away after a few dozens of bars
+const heikinAshiWarmup = 60
+
+// subscribeHeikinAshi transforms bars of symbols into Heikin-Ashi bars for strm if enable is set or strm already
+// asked for them
+func (s *Service) subscribeHeikinAshi(ctx context.Context, strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse], symbols []string, enable bool) {
+	s.rw.RLock()
+	sub := s.subscriptions[strm]
+	enabled := sub != nil && sub.heikinAshi != nil
+	s.rw.RUnlock()
+	if !enable && !enabled {
+		return
+	}
+
+	calcs := map[string]*tradingchat.HeikinAshiCalc{}
+	for _, symbol := range symbols {
+		calcs[symbol] = s.newHeikinAshiCalc(ctx, symbol)
+	}
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if sub == nil {
+		sub = &subscription{}
+		s.subscriptions[strm] = sub
+	}
+	if sub.heikinAshi == nil {
+		sub.heikinAshi = map[string]*tradingchat.HeikinAshiCalc{}
+	}
+	for symbol, calc := range calcs {
+		sub.heikinAshi[symbol] = calc
+	}
+}
+
+// newHeikinAshiCalc starts the Heikin-Ashi series from stored bars and bars restored on start or kept by the aggregation
+func (s *Service) newHeikinAshiCalc(ctx context.Context, symbol string) *tradingchat.HeikinAshiCalc {
+	calc := tradingchat.NewHeikinAshiCalc()
+	if s.store == nil && s.restored == nil && s.aggr == nil {
+		return calc
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, indicatorWarmTimeout)
+	defer cancel()
+	now := s.clock.Now()
+	current := now.Truncate(tradingchat.Interval1M)
+	bars, err := s.warmupBars(ctx, symbol, tradingchat.Interval1M, current.Add(-heikinAshiWarmup*tradingchat.Interval1M), now)
+	if err != nil {
+		s.logger.Error(err, "unable to start Heikin-Ashi bars from stored bars", "symbol", symbol)
+		return calc
+	}
+	for _, bar := range bars {
+		if bar.OpenTime().Before(current) {
+			calc.Update(bar)
+		}
+	}
+	return calc
+}
+
+// heikinAshiBar transforms bar if strm asked for Heikin-Ashi bars, callers hold s.rw
+func (s *Service) heikinAshiBar(strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse], symbol string, bar tradingchat.OHLCBar) (tradingchat.OHLCBar, bool) {
+	sub, ok := s.subscriptions[strm]
+	if !ok || sub.heikinAshi[symbol] == nil {
+		return bar, false
+	}
+	ha, err := sub.heikinAshi[symbol].Update(bar)
+	if err != nil {
+		s.logger.Error(err, "unable to transform bar into Heikin-Ashi bar", "symbol", symbol, "bar", bar)
+		return bar, false
+	}
+	return ha, true
+}
+
+// heikinAshiHistory lists Heikin-Ashi bars of symbol at interval within start and end, the series starts
+// heikinAshiWarmup bars before start
+func (s *Service) heikinAshiHistory(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error) {
+	bars, err := s.store.ListBars(ctx, symbol, interval, start.Add(-heikinAshiWarmup*interval), end)
+	if err != nil {
+		return nil, err
+	}
+	ha, err := tradingchat.HeikinAshi(bars)
+	if err != nil {
+		return nil, err
+	}
+	from := start.Truncate(interval)
+	for i, bar := range ha {
+		if !time.Unix(bar.T, 0).Before(from) {
+			return ha[i:], nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+const (
+	maxIndicators = 16
+	// time bars warming up indicators of a symbol are read in
+	indicatorWarmTimeout = 10 * time.Second
+)
+
+var indicatorKinds = map[apiv1.IndicatorKind]tradingchat.IndicatorKind{
+	apiv1.IndicatorKind_INDICATOR_KIND_SMA:       tradingchat.IndicatorSMA,
+	apiv1.IndicatorKind_INDICATOR_KIND_EMA:       tradingchat.IndicatorEMA,
+	apiv1.IndicatorKind_INDICATOR_KIND_RSI:       tradingchat.IndicatorRSI,
+	apiv1.IndicatorKind_INDICATOR_KIND_MACD:      tradingchat.IndicatorMACD,
+	apiv1.IndicatorKind_INDICATOR_KIND_BOLLINGER: tradingchat.IndicatorBollinger,
+}
+
+// subscription holds indicators, transforms and activity bars a stream asked for, indicators and transforms
+// are only updated by the push goroutine
+type subscription struct {
+	specs []tradingchat.IndicatorSpec
+	sets  map[string]*tradingchat.IndicatorSet
+	// Heikin-Ashi calculators of every symbol if the stream asked for Heikin-Ashi bars
+	heikinAshi map[string]*tradingchat.HeikinAshiCalc
+	// activity bars the stream asked for, and the ones added to the aggregation for every symbol
+	activitySpecs []tradingchat.ActivitySpec
+	activity      map[string][]tradingchat.ActivitySpec
+	// quote bars the stream asked for
+	quotes bool
+}
+
+func toIndicatorSpecs(pbs []*apiv1.IndicatorSpec) ([]tradingchat.IndicatorSpec, error) {
+	if len(pbs) > maxIndicators {
+		return nil, connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("at most %d indicators are allowed", maxIndicators))
+	}
+	specs := make([]tradingchat.IndicatorSpec, 0, len(pbs))
+	for _, pb := range pbs {
+		interval, ok := intervals[pb.GetInterval()]
+		if !ok {
+			return nil, ErrInvalidInterval
+		}
+		spec := tradingchat.IndicatorSpec{
+			Kind:     indicatorKinds[pb.GetKind()],
+			Interval: interval,
+			Period:   int(pb.GetPeriod()),
+			Fast:     int(pb.GetFast()),
+			Slow:     int(pb.GetSlow()),
+			Signal:   int(pb.GetSignal()),
+			K:        pb.GetK(),
+			Live:     pb.GetLive(),
+		}
+		if err := spec.Validate(); err != nil {
+			return nil, connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// subscribeIndicators computes indicators on bars of symbols for strm. Non-empty specs replace indicators of
+// earlier requests, otherwise symbols without indicators yet get the ones of earlier requests
+func (s *Service) subscribeIndicators(ctx context.Context, strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse], symbols []string, specs []tradingchat.IndicatorSpec) error {
+	s.rw.RLock()
+	sub := s.subscriptions[strm]
+	s.rw.RUnlock()
+
+	replace := len(specs) > 0
+	if !replace {
+		if sub == nil || len(sub.specs) == 0 {
+			return nil
+		}
+		specs = sub.specs
+	}
+
+	sets := map[string]*tradingchat.IndicatorSet{}
+	for _, symbol := range symbols {
+		if !replace && sub.sets[symbol] != nil {
+			continue
+		}
+		set, err := s.newIndicatorSet(ctx, symbol, specs)
+		if err != nil {
+			return connect.NewError(connect.CodeInvalidArgument, err)
+		}
+		sets[symbol] = set
+	}
+
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if sub == nil {
+		s.subscriptions[strm] = &subscription{specs: specs, sets: sets}
+		return nil
+	}
+	if replace {
+		sub.specs, sub.sets = specs, sets
+		return nil
+	}
+	for symbol, set := range sets {
+		sub.sets[symbol] = set
+	}
+	return nil
+}
+
+// newIndicatorSet warms indicators up with bars of the store and bars restored on start or kept by the aggregation, so they are ready right away
+func (s *Service) newIndicatorSet(ctx context.Context, symbol string, specs []tradingchat.IndicatorSpec) (*tradingchat.IndicatorSet, error) {
+	set, err := tradingchat.NewIndicatorSet(specs)
+	if err != nil {
+		return nil, err
+	}
+	if s.store == nil && s.restored == nil && s.aggr == nil {
+		return set, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, indicatorWarmTimeout)
+	defer cancel()
+	now := s.clock.Now()
+	for interval, n := range set.Warmup() {
+		start := now.Truncate(interval).Add(-time.Duration(n) * interval)
+		bars, err := s.warmupBars(ctx, symbol, interval, start, now)
+		if err != nil {
+			s.logger.Error(err, "unable to warm indicators up", "symbol", symbol, "interval", interval)
+			continue
+		}
+		set.Warm(interval, bars, now)
+	}
+	return set, nil
+}
+
+// unsubscribe drops indicators, transforms and activity bars of strm
+func (s *Service) unsubscribe(strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]) {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	if sub, ok := s.subscriptions[strm]; ok {
+		s.removeActivityBars(sub)
+		delete(s.subscriptions, strm)
+	}
+}
+
+// indicators updates indicators strm has on symbol with bar, callers hold s.rw
+func (s *Service) indicators(strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse], symbol string, bar tradingchat.OHLCBar) []*apiv1.Candlesticks1MStreamResponse_Indicator {
+	sub, ok := s.subscriptions[strm]
+	if !ok || sub.sets[symbol] == nil {
+		return nil
+	}
+	values, err := sub.sets[symbol].Update(bar)
+	if err != nil {
+		s.logger.Error(err, "unable to update indicators", "symbol", symbol, "bar", bar)
+		return nil
+	}
+	return toPBIndicators(values)
+}
+
+func toPBIndicators(values []tradingchat.IndicatorValue) []*apiv1.Candlesticks1MStreamResponse_Indicator {
+	res := make([]*apiv1.Candlesticks1MStreamResponse_Indicator, 0, len(values))
+	for _, v := range values {
+		res = append(res, &apiv1.Candlesticks1MStreamResponse_Indicator{
+			Name:     v.Name,
+			OpenTime: timestamppb.New(v.OpenTime),
+			Closed:   v.Closed,
+			Ready:    v.Ready,
+			Values:   v.Values,
+		})
+	}
+	return res
+}
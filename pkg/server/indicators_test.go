@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestIndicatorWarmup(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var open int64 = 1737734700
+	specs := []tradingchat.IndicatorSpec{{Kind: tradingchat.IndicatorSMA, Period: 3}}
+
+	// closes the 16:05 bar at 3
+	closeBar := func(t *testing.T, set *tradingchat.IndicatorSet) tradingchat.IndicatorValue {
+		t.Helper()
+		_, err := set.Update(tradingchat.OHLCBar{C: "3", T: open + 30})
+		assert.NoError(t, err)
+		values, err := set.Update(tradingchat.OHLCBar{C: "5", T: open + 60})
+		assert.NoError(t, err)
+		if !assert.Len(t, values, 1) {
+			return tradingchat.IndicatorValue{}
+		}
+		return values[0]
+	}
+
+	t.Run("indicators of a new subscription should be warmed up from the store", func(t *testing.T) {
+		// the store outlives restarts, the bars are all indicators start from, as their state isn't checkpointed
+		store := storage.NewMemory(10)
+		assert.NoError(t, store.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: open - 120}))
+		assert.NoError(t, store.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "2", L: "2", O: "2", C: "2", T: open - 60}))
+		s := &Service{logger: logger, clock: tradingchat.NewFakeClock(time.Unix(open+30, 0)), store: store}
+
+		set, err := s.newIndicatorSet(ctx, "BNBBTC", specs)
+		assert.NoError(t, err)
+		assert.Equal(t, tradingchat.IndicatorValue{
+			Name: "sma_3_1m", OpenTime: time.Unix(open, 0), Closed: true, Ready: true, Values: map[string]float64{"value": 2},
+		}, closeBar(t, set))
+	})
+
+	t.Run("indicators without a store should start cold", func(t *testing.T) {
+		s := &Service{logger: logger, clock: tradingchat.NewFakeClock(time.Unix(open+30, 0))}
+
+		set, err := s.newIndicatorSet(ctx, "BNBBTC", specs)
+		assert.NoError(t, err)
+		assert.False(t, closeBar(t, set).Ready)
+	})
+}
+
+func TestSubscribeIndicators(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	sma := []tradingchat.IndicatorSpec{{Kind: tradingchat.IndicatorSMA, Interval: tradingchat.Interval1M, Period: 3}}
+	ema := []tradingchat.IndicatorSpec{{Kind: tradingchat.IndicatorEMA, Interval: tradingchat.Interval1M, Period: 5}}
+
+	newService := func() *Service {
+		return &Service{
+			logger:        logger,
+			clock:         tradingchat.NewFakeClock(time.Unix(1737734700, 0)),
+			subscriptions: map[*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]]*subscription{},
+			rw:            &sync.RWMutex{},
+		}
+	}
+	// streams are only told apart by their address here
+	newStream := func() *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse] {
+		return &connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]{}
+	}
+
+	t.Run("requests without indicators should add none", func(t *testing.T) {
+		s, strm := newService(), newStream()
+		assert.NoError(t, s.subscribeIndicators(ctx, strm, []string{"BNBBTC"}, nil))
+		assert.Empty(t, s.subscriptions)
+	})
+
+	t.Run("later symbols should get indicators of earlier requests", func(t *testing.T) {
+		s, strm := newService(), newStream()
+		assert.NoError(t, s.subscribeIndicators(ctx, strm, []string{"BNBBTC"}, sma))
+		bnb := s.subscriptions[strm].sets["BNBBTC"]
+
+		assert.NoError(t, s.subscribeIndicators(ctx, strm, []string{"ETHBTC"}, nil))
+		sub := s.subscriptions[strm]
+		assert.Equal(t, sma, sub.specs)
+		assert.Same(t, bnb, sub.sets["BNBBTC"], "indicators of earlier symbols keep their state")
+		assert.NotNil(t, sub.sets["ETHBTC"])
+	})
+
+	t.Run("new indicators should replace the ones of every symbol", func(t *testing.T) {
+		s, strm := newService(), newStream()
+		assert.NoError(t, s.subscribeIndicators(ctx, strm, []string{"BNBBTC", "ETHBTC"}, sma))
+		bnb := s.subscriptions[strm].sets["BNBBTC"]
+
+		assert.NoError(t, s.subscribeIndicators(ctx, strm, []string{"BNBBTC"}, ema))
+		sub := s.subscriptions[strm]
+		assert.Equal(t, ema, sub.specs)
+		assert.NotSame(t, bnb, sub.sets["BNBBTC"])
+		assert.Nil(t, sub.sets["ETHBTC"], "symbols left out of the request lose their indicators")
+	})
+
+	t.Run("streams should keep their own indicators", func(t *testing.T) {
+		s, strm, other := newService(), newStream(), newStream()
+		assert.NoError(t, s.subscribeIndicators(ctx, strm, []string{"BNBBTC"}, sma))
+		assert.NoError(t, s.subscribeIndicators(ctx, other, []string{"BNBBTC"}, ema))
+		assert.Equal(t, sma, s.subscriptions[strm].specs)
+		assert.Equal(t, ema, s.subscriptions[other].specs)
+
+		s.unsubscribe(other)
+		assert.Len(t, s.subscriptions, 1)
+		assert.Equal(t, sma, s.subscriptions[strm].specs)
+	})
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+	"github.com/rickliujh/trading-chat-aggr/pkg/utils"
+)
+
+var (
+	ErrQuotesDisabled    = connect.NewError(connect.CodeUnimplemented, errors.New("quote bars are not enabled"))
+	ErrQuotesUnsupported = connect.NewError(connect.CodeUnimplemented, errors.New("storage doesn't keep quote bars"))
+	ErrQuoteSymbols      = connect.NewError(connect.CodeInvalidArgument, errors.New("quote bars are only built for symbols registered on start"))
+)
+
+// ServeQuotes saves quote bars of symbols into the store if persist is set, and relays them to the push goroutine
+// if push is set, which sends them to subscribers asked for them. Streams can't ask for quote bars until it's called
+func (s *Service) ServeQuotes(done <-chan struct{}, symbols []string, updates <-chan tradingchat.QuoteUpdate, push, persist bool) error {
+	store, ok := s.store.(storage.QuoteBarStore)
+	if persist && !ok {
+		return ErrQuotesUnsupported
+	}
+
+	s.rw.Lock()
+	s.quoteSymbols = symbols
+	s.rw.Unlock()
+
+	go func() {
+		for u := range utils.OrDone(done, updates) {
+			if persist {
+				ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+				err := store.SaveQuoteBar(ctx, u.Bar)
+				cancel()
+				if err != nil {
+					s.logger.Error(err, "failed to persist quote bar", "bar", u.Bar)
+				}
+			}
+			if !push {
+				continue
+			}
+			select {
+			case s.quoteCh <- u:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// subscribeQuotes sends quote bars of the symbols of strm to it if enable is set. Quote streams aren't subscribed
+// to symbols registered later on, so streams asking for quote bars are only allowed symbols they are built for
+func (s *Service) subscribeQuotes(strm *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse], symbols []string, enable bool) error {
+	s.rw.Lock()
+	defer s.rw.Unlock()
+	sub := s.subscriptions[strm]
+	if !enable && (sub == nil || !sub.quotes) {
+		return nil
+	}
+	if s.quoteSymbols == nil {
+		return ErrQuotesDisabled
+	}
+	for _, symbol := range symbols {
+		if !slices.Contains(s.quoteSymbols, symbol) {
+			return ErrQuoteSymbols
+		}
+	}
+	if sub == nil {
+		sub = &subscription{}
+		s.subscriptions[strm] = sub
+	}
+	sub.quotes = true
+	return nil
+}
+
+// sendQuoteBar sends u to subscribers of its symbol asked for quote bars
+func (s *Service) sendQuoteBar(u tradingchat.QuoteUpdate) {
+	var res *apiv1.Candlesticks1MStreamResponse
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	for _, to := range s.notifyList[u.Bar.Symbol] {
+		sub, ok := s.subscriptions[to]
+		if !ok || !sub.quotes {
+			continue
+		}
+		if res == nil {
+			res = &apiv1.Candlesticks1MStreamResponse{QuoteBar: toPBQuoteBar(u)}
+		}
+		to.Send(res)
+	}
+}
+
+// quoteHistory lists stored quote bars of symbol, they are kept by the minute and rolled up to interval
+// like trade bars, the leading bucket that opened before start is dropped
+func (s *Service) quoteHistory(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) (*connect.Response[apiv1.CandlesticksHistoryResponse], error) {
+	store, ok := s.store.(storage.QuoteBarStore)
+	if !ok {
+		return nil, ErrQuotesUnsupported
+	}
+	bars, err := store.ListQuoteBars(ctx, symbol, start.Truncate(interval), end)
+	if err != nil {
+		s.logger.Error(err, "failed to list quote bars", "symbol", symbol, "start", start, "end", end)
+		return nil, ErrHistoryUnavailable
+	}
+	if interval > tradingchat.Interval1M {
+		bars = tradingchat.RollupQuotes(bars, interval)
+	}
+	for len(bars) > 0 && time.Unix(bars[0].OpenTime, 0).Before(start) {
+		bars = bars[1:]
+	}
+
+	now := s.clock.Now()
+	res := &apiv1.CandlesticksHistoryResponse{
+		QuoteBars: make([]*apiv1.Candlesticks1MStreamResponse_QuoteBar, 0, len(bars)),
+	}
+	for _, bar := range bars {
+		closed := !time.Unix(bar.OpenTime, 0).Add(interval).After(now)
+		res.QuoteBars = append(res.QuoteBars, toPBQuoteBar(tradingchat.QuoteUpdate{Bar: bar, Closed: closed}))
+	}
+	return connect.NewResponse(res), nil
+}
+
+func toPBQuoteBar(u tradingchat.QuoteUpdate) *apiv1.Candlesticks1MStreamResponse_QuoteBar {
+	return &apiv1.Candlesticks1MStreamResponse_QuoteBar{
+		Symbol:     u.Bar.Symbol,
+		OpenTime:   timestamppb.New(time.Unix(u.Bar.OpenTime, 0)),
+		Bid:        toPBBar(u.Bar.Bid),
+		Ask:        toPBBar(u.Bar.Ask),
+		MeanSpread: u.Bar.MeanSpread,
+		Quotes:     u.Bar.Quotes,
+		Closed:     u.Closed,
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"sync"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestQuotes(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+
+	newService := func(store storage.BarStore) *Service {
+		return &Service{
+			logger:        logger,
+			store:         store,
+			subscriptions: map[*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]]*subscription{},
+			rw:            &sync.RWMutex{},
+		}
+	}
+	// streams are only told apart by their address here
+	newStream := func() *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse] {
+		return &connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]{}
+	}
+
+	t.Run("persisting quotes should need a store keeping them", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		// keeps 1 minute bars only
+		s := newService(struct{ storage.BarStore }{storage.NewMemory(10)})
+		assert.ErrorIs(t, s.ServeQuotes(done, []string{"BNBBTC"}, make(chan tradingchat.QuoteUpdate), false, true), ErrQuotesUnsupported)
+		assert.NoError(t, s.ServeQuotes(done, []string{"BNBBTC"}, make(chan tradingchat.QuoteUpdate), false, false))
+	})
+
+	t.Run("quotes should only be subscribed to for symbols they are built for", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		s := newService(nil)
+		assert.ErrorIs(t, s.subscribeQuotes(newStream(), []string{"BNBBTC"}, true), ErrQuotesDisabled)
+		assert.NoError(t, s.ServeQuotes(done, []string{"BNBBTC"}, make(chan tradingchat.QuoteUpdate), true, false))
+
+		assert.ErrorIs(t, s.subscribeQuotes(newStream(), []string{"BNBBTC", "ETHBTC"}, true), ErrQuoteSymbols)
+
+		strm := newStream()
+		assert.NoError(t, s.subscribeQuotes(strm, []string{"BNBBTC"}, true))
+		assert.True(t, s.subscriptions[strm].quotes)
+		assert.ErrorIs(t, s.subscribeQuotes(strm, []string{"ETHBTC"}, false), ErrQuoteSymbols, "symbols added later on are checked too")
+
+		assert.NoError(t, s.subscribeQuotes(newStream(), []string{"ETHBTC"}, false), "streams without quotes aren't")
+	})
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// closed bars restored before the bar in progress, as many as Heikin-Ashi bars are started from
+const restoreWindow = heikinAshiWarmup * tradingchat.Interval1M
+
+// restoreBars reads bars in progress of symbols from the trade tape or the bar store, so a restart in the middle
+// of a minute keeps its open, high and low. Bars closed within restoreWindow before are kept in s.restored, they
+// warm indicators and Heikin-Ashi bars up where the store misses them
+func (s *Service) restoreBars(symbols []string) map[string]tradingchat.OHLCBar {
+	reader, _ := s.trades.(storage.TradeReader)
+	if s.store == nil && reader == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	now := s.clock.Now()
+	minute := now.Truncate(tradingchat.Interval1M)
+	recent, err := storage.RecentBars(ctx, s.logger.WithName("restore"), s.store, reader, symbols, minute.Add(-restoreWindow), now)
+	if err != nil {
+		s.logger.Error(err, "unable to restore some recent bars")
+	}
+
+	bars := map[string]tradingchat.OHLCBar{}
+	restored := storage.NewMemory(heikinAshiWarmup)
+	for symbol, recentBars := range recent {
+		for _, bar := range recentBars {
+			if bar.OpenTime().Equal(minute) {
+				bars[symbol] = bar
+				continue
+			}
+			// never fails
+			_ = restored.SaveBar(ctx, symbol, bar)
+		}
+	}
+	s.restored = restored
+	return bars
+}
+
+// warmupBars lists bars of symbol at interval opened within [start, end) from the store, bars restored on start
+// and recent bars kept by the aggregation, which is checkpointed, stand in for the ones the store misses
+func (s *Service) warmupBars(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error) {
+	var bars []tradingchat.OHLCBar
+	if s.store != nil {
+		var err error
+		if bars, err = s.store.ListBars(ctx, symbol, interval, start, end); err != nil {
+			return nil, err
+		}
+	}
+
+	var fallback []tradingchat.OHLCBar
+	if s.restored != nil {
+		restored, _ := s.restored.ListBars(ctx, symbol, interval, start, end)
+		fallback = append(fallback, restored...)
+	}
+	if s.aggr != nil {
+		// fails for symbols no longer registered only
+		recent, _ := s.aggr.RecentBars(symbol, interval)
+		fallback = append(fallback, recent...)
+	}
+	if len(fallback) == 0 {
+		return bars, nil
+	}
+
+	openOf := func(bar tradingchat.OHLCBar) time.Time { return time.Unix(bar.T, 0).Truncate(interval) }
+	stored := map[time.Time]bool{}
+	for _, bar := range bars {
+		stored[openOf(bar)] = true
+	}
+	for _, bar := range fallback {
+		open := openOf(bar)
+		if !stored[open] && !open.Before(start) && open.Before(end) {
+			stored[open] = true
+			bars = append(bars, bar)
+		}
+	}
+	sort.Slice(bars, func(i, j int) bool { return bars[i].T < bars[j].T })
+	return bars, nil
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestRestoreBars(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var open int64 = 1737734700
+
+	tape, err := storage.NewFileTape(t.TempDir(), 100)
+	assert.NoError(t, err)
+	defer tape.Close()
+	assert.NoError(t, tape.SaveTrades(ctx, []*bconn.WsAggTradeEvent{
+		{Symbol: "BNBBTC", AggTradeID: 1, Price: "1", TradeTime: (open - 110) * 1000},
+		{Symbol: "BNBBTC", AggTradeID: 2, Price: "2", TradeTime: (open - 50) * 1000},
+		{Symbol: "BNBBTC", AggTradeID: 3, Price: "4", TradeTime: (open + 10) * 1000},
+	}))
+
+	s := &Service{logger: logger, clock: tradingchat.NewFakeClock(time.Unix(open+30, 0)), trades: tape}
+	seeds := s.restoreBars([]string{"BNBBTC"})
+
+	t.Run("bar in progress should seed the aggregation", func(t *testing.T) {
+		assert.Equal(t, map[string]tradingchat.OHLCBar{"BNBBTC": {H: "4", L: "4", O: "4", C: "4", T: open + 10}}, seeds)
+	})
+
+	t.Run("bars closed before the start should warm indicators up without a store", func(t *testing.T) {
+		set, err := s.newIndicatorSet(ctx, "BNBBTC", []tradingchat.IndicatorSpec{{Kind: tradingchat.IndicatorSMA, Period: 3}})
+		assert.NoError(t, err)
+		set.Update(seeds["BNBBTC"])
+		values, err := set.Update(tradingchat.OHLCBar{C: "6", T: open + 60})
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.IndicatorValue{
+			{Name: "sma_3_1m", OpenTime: time.Unix(open, 0), Closed: true, Ready: true, Values: map[string]float64{"value": 7.0 / 3}},
+		}, values)
+	})
+
+	t.Run("bars closed before the start should start Heikin-Ashi bars", func(t *testing.T) {
+		calc := s.newHeikinAshiCalc(ctx, "BNBBTC")
+		started := tradingchat.NewHeikinAshiCalc()
+		started.Update(tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: open - 110})
+		started.Update(tradingchat.OHLCBar{H: "2", L: "2", O: "2", C: "2", T: open - 50})
+		assert.Equal(t, started, calc)
+	})
+
+	t.Run("stored bars should take precedence over restored ones", func(t *testing.T) {
+		store := storage.NewMemory(10)
+		assert.NoError(t, store.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "3", L: "3", O: "3", C: "3", T: open - 60}))
+		withStore := &Service{logger: logger, clock: s.clock, store: store, restored: s.restored}
+
+		bars, err := withStore.warmupBars(ctx, "BNBBTC", tradingchat.Interval1M, time.Unix(open-180, 0), time.Unix(open, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.OHLCBar{
+			{H: "1", L: "1", O: "1", C: "1", T: open - 120},
+			{H: "3", L: "3", O: "3", C: "3", T: open - 60},
+		}, bars)
+	})
+
+	t.Run("bars of the checkpoint should warm indicators up without a store", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		cp := &tradingchat.Checkpoint{Version: tradingchat.CheckpointVersion, Symbols: map[string]tradingchat.SymbolState{"BNBBTC": {
+			Bar:    tradingchat.OHLCBar{H: "4", L: "4", O: "4", C: "4", T: open + 10},
+			Recent: tradingchat.RecentBars{"1m": {{H: "5", L: "5", O: "5", C: "5", T: open - 60}}},
+		}}}
+		aggr, _, _ := tradingchat.NewAggrStreamWithOptions(logger, done, make(chan *bconn.WsAggTradeEvent), []string{"BNBBTC"}, tradingchat.AggrOptions{Restore: cp, Clock: s.clock, Shards: 1})
+		withAggr := &Service{logger: logger, clock: s.clock, aggr: aggr}
+
+		set, err := withAggr.newIndicatorSet(ctx, "BNBBTC", []tradingchat.IndicatorSpec{{Kind: tradingchat.IndicatorSMA, Period: 2}})
+		assert.NoError(t, err)
+		set.Update(tradingchat.OHLCBar{C: "4", T: open + 10})
+		values, err := set.Update(tradingchat.OHLCBar{C: "6", T: open + 60})
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.IndicatorValue{
+			{Name: "sma_2_1m", OpenTime: time.Unix(open, 0), Closed: true, Ready: true, Values: map[string]float64{"value": 4.5}},
+		}, values)
+
+		bars, err := withAggr.warmupBars(ctx, "BNBBTC", tradingchat.Interval1M, time.Unix(open-180, 0), time.Unix(open, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.OHLCBar{{H: "5", L: "5", O: "5", C: "5", T: open - 60}}, bars, "the bar in progress isn't within the range")
+	})
+}
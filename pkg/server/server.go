@@ -9,12 +9,11 @@ import (
 
 	"connectrpc.com/connect"
 	"github.com/go-logr/logr"
-	"github.com/jackc/pgx/v5/pgtype"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
 	"github.com/rickliujh/trading-chat-aggr/pkg/api/v1/apiv1connect"
-	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/storage"
 	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
 	"github.com/rickliujh/trading-chat-aggr/pkg/utils"
 )
@@ -25,41 +24,74 @@ var (
 	ErrNotRevieved         = connect.NewError(connect.CodeUnknown, errors.New("unable to receive"))
 	ErrInvalidRequest      = connect.NewError(connect.CodeInvalidArgument, errors.New("invalid request id or symbols"))
 	ErrSymbolsNotSupported = connect.NewError(connect.CodeInvalidArgument, errors.New("some of symbols are not supported"))
+	ErrInvalidInterval     = connect.NewError(connect.CodeInvalidArgument, errors.New("interval is not supported"))
+	ErrInvalidTimeRange    = connect.NewError(connect.CodeInvalidArgument, errors.New("start of time range must be before its end"))
+	ErrHistoryDisabled     = connect.NewError(connect.CodeUnimplemented, errors.New("historical queries are not enabled"))
+	ErrHistoryUnavailable  = connect.NewError(connect.CodeUnavailable, errors.New("unable to query historical bars"))
 )
 
-func NewService(logger logr.Logger, db *sql.Queries, symbols []string, done <-chan struct{}, push, persist bool) (*Service, error) {
+var intervals = map[apiv1.Interval]time.Duration{
+	apiv1.Interval_INTERVAL_UNSPECIFIED: tradingchat.Interval1M,
+	apiv1.Interval_INTERVAL_1M:          tradingchat.Interval1M,
+	apiv1.Interval_INTERVAL_5M:          tradingchat.Interval5M,
+	apiv1.Interval_INTERVAL_1H:          tradingchat.Interval1H,
+	apiv1.Interval_INTERVAL_1D:          tradingchat.Interval1D,
+}
+
+// NewService creates the aggregator service fed by trades of source and aggregated with opts, whose clock also
+// clocks the service. Bars in progress are restored from store or trades unless opts restores a checkpoint, store is only needed when persist or
+// history is enabled, every received trade is recorded into trades unless it's nil. With onDemand, symbols
+// clients subscribe to are registered on the fly and removed again once their last subscriber leaves
+func NewService(logger logr.Logger, source tradingchat.TradeSource, opts tradingchat.AggrOptions, store storage.BarStore, trades storage.TradeStore, symbols []string, done <-chan struct{}, push, persist, history, onDemand bool) (*Service, error) {
+	if (persist || history) && store == nil {
+		return nil, errors.New("storage is required to persist bars or serve history")
+	}
+
 	logger.Info("registering symbols", "symbols", symbols)
-	stream, err := tradingchat.BinanceStreamEventGen(
-		logger.WithName("binance-stream"),
-		symbols,
-		func(err error) {
-			logger.Error(err, "binance-stream error")
-		},
-		done,
-	)
+	stream, err := source.Stream(done)
 	if err != nil {
-		panic("can't connect to biance-stream")
+		return nil, err
 	}
 
-	aggr, updateCh := tradingchat.NewAggrStream(logger.WithName("aggr"), done, stream, symbols)
-
-	regSymbols := make(map[string]bool, len(symbols))
-	for _, s := range symbols {
-		regSymbols[s] = true
+	if opts.Clock == nil {
+		opts.Clock = tradingchat.SystemClock
 	}
 
 	s := &Service{
-		logger:      logger,
-		db:          db,
-		regSymbols:  regSymbols,
-		aggr:        aggr,
-		notifyList:  map[string][]*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]{},
-		rw:          &sync.RWMutex{},
-		oncePush:    &sync.Once{},
-		oncePersist: &sync.Once{},
+		logger:        logger,
+		clock:         opts.Clock,
+		source:        source,
+		store:         store,
+		trades:        trades,
+		history:       history,
+		onDemand:      onDemand,
+		demanded:      map[string]bool{},
+		symbolsMu:     &sync.Mutex{},
+		notifyList:    map[string][]*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]{},
+		subscriptions: map[*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]]*subscription{},
+		rw:            &sync.RWMutex{},
+		oncePush:      &sync.Once{},
+		oncePersist:   &sync.Once{},
+		alertStreams:  map[string]*alertStream{},
+		alertsMu:      &sync.Mutex{},
+		bookSubs:      map[string][]chan *apiv1.OrderBookResponse{},
+		bookMu:        &sync.RWMutex{},
+		quoteCh:       make(chan tradingchat.QuoteUpdate, 500),
 	}
+	if push {
+		s.alerts = tradingchat.NewAlertBook(opts.Clock, alertOwnerTTL)
+	}
+
+	if trades != nil {
+		stream = s.recordTrades(done, stream)
+	}
+	opts.Seeds = s.restoreBars(symbols)
+	aggr, updateCh, gapCh := tradingchat.NewAggrStreamWithOptions(logger.WithName("aggr"), done, stream, symbols, opts)
+	s.aggr = aggr
+	activityCh := s.relayActivityBars(done, opts.ActivityBars, push, persist)
+	watchFeed(opts.Monitor)
 
-	logger.Info("function enables", "push", push, "persist", persist)
+	logger.Info("function enables", "push", push, "persist", persist, "history", history, "record_trades", trades != nil, "on_demand", onDemand)
 	if push && persist {
 		updateStrm1 := make(chan string, 500)
 		updateStrm2 := make(chan string, 500)
@@ -71,10 +103,10 @@ func NewService(logger logr.Logger, db *sql.Queries, symbols []string, done <-ch
 				updateStrm2 <- v
 			}
 		}()
-		s.push(done, updateStrm1)
+		s.push(done, updateStrm1, gapCh, activityCh, aggr.FeedStatuses(), s.quoteCh)
 		s.persist(done, updateStrm2)
 	} else if push {
-		s.push(done, updateCh)
+		s.push(done, updateCh, gapCh, activityCh, aggr.FeedStatuses(), s.quoteCh)
 	} else if persist {
 		s.persist(done, updateCh)
 	}
@@ -83,14 +115,35 @@ func NewService(logger logr.Logger, db *sql.Queries, symbols []string, done <-ch
 }
 
 type Service struct {
-	logger      logr.Logger
-	db          *sql.Queries
-	regSymbols  map[string]bool
-	aggr        tradingchat.Aggr
-	notifyList  map[string][]*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]
-	rw          *sync.RWMutex
-	oncePush    *sync.Once
-	oncePersist *sync.Once
+	logger     logr.Logger
+	clock      tradingchat.Clock
+	source     tradingchat.TradeSource
+	store      storage.BarStore
+	trades     storage.TradeStore
+	history    bool
+	onDemand   bool
+	demanded   map[string]bool // symbols registered on demand, guarded by symbolsMu
+	symbolsMu  *sync.Mutex
+	aggr       *tradingchat.Aggr
+	notifyList map[string][]*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]
+	// bars closed shortly before the start, nil unless they were restored, see restoreBars
+	restored *storage.Memory
+	// indicators of every stream, guarded by rw
+	subscriptions map[*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]]*subscription
+	rw            *sync.RWMutex
+	oncePush      *sync.Once
+	oncePersist   *sync.Once
+	// alerts of every request id, nil unless push is enabled, and the streams they are sent to guarded by alertsMu
+	alerts       *tradingchat.AlertBook
+	alertStreams map[string]*alertStream
+	alertsMu     *sync.Mutex
+	// symbols of book bars, nil unless ServeDepth was called, and channels of their subscribers guarded by bookMu
+	depthSymbols []string
+	bookSubs     map[string][]chan *apiv1.OrderBookResponse
+	bookMu       *sync.RWMutex
+	// symbols of quote bars, nil unless ServeQuotes was called, guarded by rw, and quote bars relayed to the push goroutine
+	quoteSymbols []string
+	quoteCh      chan tradingchat.QuoteUpdate
 }
 
 // Candlesticks1MStream implements apiv1connect.AggrHandler.
@@ -100,6 +153,7 @@ func (s *Service) Candlesticks1MStream(ctx context.Context, strm *connect.BidiSt
 	defer func() {
 		s.logger.V(2).Info("removing subscriber", "req_id", id, "symbols", symbols)
 		s.removeFromList(symbols, strm)
+		s.unsubscribe(strm)
 		s.logger.V(2).Info("client disconnected", "req_id", id, "symbols", symbols)
 	}()
 	for {
@@ -122,12 +176,28 @@ func (s *Service) Candlesticks1MStream(ctx context.Context, strm *connect.BidiSt
 			s.logger.Info("found invalid request disconnecting with client", "req_id", id, "req_id_new", reqID)
 			return ErrInvalidRequest
 		}
+		specs, err := toIndicatorSpecs(req.GetIndicators())
+		if err != nil {
+			s.logger.Info("client request invalid indicators", "req_id", reqID, "err", err)
+			return err
+		}
+		activitySpecs, err := toActivitySpecs(req.GetActivityBars())
+		if err != nil {
+			s.logger.Info("client request invalid activity bars", "req_id", reqID, "err", err)
+			return err
+		}
 		if !s.isSymbolRegistered(reqSbs) {
-			s.logger.Info("client request unregistered symbols", "symbols", reqSbs)
-			return ErrSymbolsNotSupported
+			if !s.onDemand {
+				s.logger.Info("client request unregistered symbols", "symbols", reqSbs)
+				return ErrSymbolsNotSupported
+			}
+			if _, err := s.addSymbols(reqSbs, true); err != nil {
+				return err
+			}
 		}
 
 		// track request id and subscribed symbols
+		isFirst := id == ""
 		id = reqID
 		var toBeAdd []string
 		for _, sNew := range reqSbs {
@@ -144,8 +214,26 @@ func (s *Service) Candlesticks1MStream(ctx context.Context, strm *connect.BidiSt
 		}
 		symbols = append(symbols, toBeAdd...)
 
+		indicatorSymbols := toBeAdd
+		if len(specs) > 0 {
+			indicatorSymbols = symbols
+		}
+		if err := s.subscribeIndicators(ctx, strm, indicatorSymbols, specs); err != nil {
+			return err
+		}
+		s.subscribeHeikinAshi(ctx, strm, toBeAdd, isFirst && req.GetHeikinAshi())
+		activitySymbols := toBeAdd
+		if len(activitySpecs) > 0 {
+			activitySymbols = symbols
+		}
+		if err := s.subscribeActivityBars(strm, activitySymbols, activitySpecs); err != nil {
+			return err
+		}
+		if err := s.subscribeQuotes(strm, toBeAdd, isFirst && req.GetQuotes()); err != nil {
+			return err
+		}
 		s.addToList(toBeAdd, strm)
-		s.logger.Info("user registered for OHLC 1m stream updates", "req_id", id, "symbols", symbols, "symbols-added", toBeAdd)
+		s.logger.Info("user registered for OHLC 1m stream updates", "req_id", id, "symbols", symbols, "symbols-added", toBeAdd, "indicators", len(specs))
 	}
 }
 
@@ -168,7 +256,17 @@ func (s *Service) removeFromList(symbols []string, strm *connect.BidiStream[apiv
 		}
 		s.notifyList[symbol] = sublist[:count+1]
 	}
+	var idle []string
+	for _, symbol := range symbols {
+		if len(s.notifyList[symbol]) == 0 {
+			idle = append(idle, symbol)
+		}
+	}
 	s.rw.Unlock()
+
+	if s.onDemand && len(idle) > 0 {
+		s.removeIdleSymbols(idle)
+	}
 }
 
 func (s *Service) addToList(symbols []string, to *connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]) {
@@ -180,36 +278,84 @@ func (s *Service) addToList(symbols []string, to *connect.BidiStream[apiv1.Candl
 	}
 	s.rw.Unlock()
 }
-func (s *Service) push(done <-chan struct{}, updateStream <-chan string) {
+
+// push sends updates of bars, gaps, activity bars, feed statuses and quote bars to subscribers, a stream is only
+// sent to by this goroutine
+func (s *Service) push(done <-chan struct{}, updateStream <-chan string, gapStream <-chan tradingchat.Gap, activityStream <-chan tradingchat.ActivityUpdate, statusStream <-chan tradingchat.FeedStatus, quoteStream <-chan tradingchat.QuoteUpdate) {
 	s.oncePush.Do(func() {
 		go func() {
-			for symbol := range utils.OrDone(done, updateStream) {
-				s.logger.V(4).Info("new update to push", symbol)
-				s.rw.RLock()
-				sublist := s.notifyList[symbol]
-
-				bar, err := s.aggr.OHLCBar(symbol)
-				if err != nil {
-					s.logger.Error(err, "registered symbol not exist in aggr stream", "symbol", symbol)
-				}
-
-				for _, to := range sublist {
-					to.Send(&apiv1.Candlesticks1MStreamResponse{
-						Update: &apiv1.Candlesticks1MStreamResponse_Bar{
-							High:      bar.H,
-							Low:       bar.L,
-							Open:      bar.O,
-							Close:     bar.C,
-							UpdatedAt: timestamppb.New(time.Unix(bar.T, 0)),
-						},
+			for {
+				select {
+				case <-done:
+					return
+				case symbol, ok := <-updateStream:
+					if !ok {
+						return
+					}
+					s.logger.V(4).Info("new update to push", symbol)
+					bar, err := s.aggr.OHLCBar(symbol)
+					if err != nil {
+						// removed since the update
+						s.logger.V(2).Info("symbol not exist in aggr stream", "symbol", symbol)
+						continue
+					}
+					s.sendBar(symbol, bar)
+					s.fireAlerts(symbol)
+				case gap, ok := <-gapStream:
+					if !ok {
+						gapStream = nil
+						continue
+					}
+					s.send(gap.Symbol, &apiv1.Candlesticks1MStreamResponse{
+						Gap: toPBGap(gap),
 					})
+				case u, ok := <-activityStream:
+					if !ok {
+						activityStream = nil
+						continue
+					}
+					s.sendActivityBar(u)
+				case st, ok := <-statusStream:
+					if !ok {
+						statusStream = nil
+						continue
+					}
+					s.sendStatus(st)
+				case u := <-quoteStream:
+					s.sendQuoteBar(u)
 				}
-				s.rw.RUnlock()
 			}
 		}()
 	})
 }
 
+// sendBar sends bar to every subscriber of symbol along with indicators the subscriber asked for,
+// bars are transformed into Heikin-Ashi bars for subscribers asked for them
+func (s *Service) sendBar(symbol string, bar tradingchat.OHLCBar) {
+	update := toPBBar(bar)
+	s.rw.RLock()
+	for _, to := range s.notifyList[symbol] {
+		res := &apiv1.Candlesticks1MStreamResponse{
+			Update:     update,
+			Indicators: s.indicators(to, symbol, bar),
+		}
+		if ha, ok := s.heikinAshiBar(to, symbol, bar); ok {
+			res.Update = toPBBar(ha)
+		}
+		to.Send(res)
+	}
+	s.rw.RUnlock()
+}
+
+// send sends res to every subscriber of symbol
+func (s *Service) send(symbol string, res *apiv1.Candlesticks1MStreamResponse) {
+	s.rw.RLock()
+	for _, to := range s.notifyList[symbol] {
+		to.Send(res)
+	}
+	s.rw.RUnlock()
+}
+
 func (s *Service) persist(done <-chan struct{}, updateStream <-chan string) {
 	s.oncePersist.Do(func() {
 		go func() {
@@ -218,18 +364,14 @@ func (s *Service) persist(done <-chan struct{}, updateStream <-chan string) {
 
 				bar, err := s.aggr.OHLCBar(symbol)
 				if err != nil {
-					s.logger.Error(err, "registered symbol not exist in aggr stream", "symbol", symbol)
+					// removed since the update
+					s.logger.V(2).Info("symbol not exist in aggr stream", "symbol", symbol)
+					continue
 				}
 
 				ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
-				defer cancel()
-
-				bar4db, err := toDBBar(bar)
-				if err != nil {
-					s.logger.Error(err, "failed to conver OHLCBar to db model", "bar", bar)
-					continue
-				}
-				_, err = s.db.CreateBar(ctx, bar4db)
+				err = s.store.SaveBar(ctx, symbol, bar)
+				cancel()
 				if err != nil {
 					s.logger.Error(err, "failed to persist to db", "bar", bar)
 					continue
@@ -239,41 +381,95 @@ func (s *Service) persist(done <-chan struct{}, updateStream <-chan string) {
 	})
 }
 
+// CandlesticksHistory implements apiv1connect.AggrHandler.
+func (s *Service) CandlesticksHistory(ctx context.Context, req *connect.Request[apiv1.CandlesticksHistoryRequest]) (*connect.Response[apiv1.CandlesticksHistoryResponse], error) {
+	if !s.history {
+		return nil, ErrHistoryDisabled
+	}
+
+	symbol := req.Msg.GetSymbol()
+	if !s.isSymbolRegistered([]string{symbol}) {
+		s.logger.Info("client request history of unregistered symbol", "symbol", symbol)
+		return nil, ErrSymbolsNotSupported
+	}
+	interval, ok := intervals[req.Msg.GetInterval()]
+	if !ok {
+		return nil, ErrInvalidInterval
+	}
+	start := req.Msg.GetStart().AsTime()
+	end := s.clock.Now()
+	if req.Msg.GetEnd() != nil {
+		end = req.Msg.GetEnd().AsTime()
+	}
+	if !start.Before(end) {
+		return nil, ErrInvalidTimeRange
+	}
+
+	if req.Msg.GetQuotes() {
+		if req.Msg.GetHeikinAshi() || req.Msg.GetActivityBar() != nil {
+			return nil, ErrInvalidRequest
+		}
+		return s.quoteHistory(ctx, symbol, interval, start, end)
+	}
+	if pb := req.Msg.GetActivityBar(); pb != nil {
+		if req.Msg.GetHeikinAshi() {
+			return nil, ErrInvalidRequest
+		}
+		specs, err := toActivitySpecs([]*apiv1.ActivityBarSpec{pb})
+		if err != nil {
+			return nil, err
+		}
+		return s.activityHistory(ctx, symbol, specs[0], start, end)
+	}
+
+	var bars []tradingchat.OHLCBar
+	var err error
+	if req.Msg.GetHeikinAshi() {
+		bars, err = s.heikinAshiHistory(ctx, symbol, interval, start, end)
+	} else {
+		bars, err = s.store.ListBars(ctx, symbol, interval, start, end)
+	}
+	if err != nil {
+		s.logger.Error(err, "failed to list bars", "symbol", symbol, "interval", interval, "start", start, "end", end)
+		return nil, ErrHistoryUnavailable
+	}
+
+	res := &apiv1.CandlesticksHistoryResponse{
+		Bars: make([]*apiv1.Candlesticks1MStreamResponse_Bar, 0, len(bars)),
+	}
+	for _, bar := range bars {
+		res.Bars = append(res.Bars, toPBBar(bar))
+	}
+	return connect.NewResponse(res), nil
+}
+
 func (s *Service) isSymbolRegistered(symbols []string) bool {
 	for _, sb := range symbols {
-		if _, ok := s.regSymbols[sb]; !ok {
+		if !s.aggr.Has(sb) {
 			return false
 		}
 	}
 	return true
 }
 
-func toDBBar(bar tradingchat.OHLCBar) (sql.CreateBarParams, error) {
-	var h pgtype.Numeric
-	if err := h.Scan(bar.H); err != nil {
-		return sql.CreateBarParams{}, err
-	}
-	var l pgtype.Numeric
-	if err := l.Scan(bar.L); err != nil {
-		return sql.CreateBarParams{}, err
+func toPBBar(bar tradingchat.OHLCBar) *apiv1.Candlesticks1MStreamResponse_Bar {
+	return &apiv1.Candlesticks1MStreamResponse_Bar{
+		High:       bar.H,
+		Low:        bar.L,
+		Open:       bar.O,
+		Close:      bar.C,
+		UpdatedAt:  timestamppb.New(time.Unix(bar.T, 0)),
+		Incomplete: bar.Incomplete,
 	}
-	var o pgtype.Numeric
-	if err := o.Scan(bar.O); err != nil {
-		return sql.CreateBarParams{}, err
-	}
-	var c pgtype.Numeric
-	if err := c.Scan(bar.C); err != nil {
-		return sql.CreateBarParams{}, err
-	}
-	var ts pgtype.Timestamp
-	if err := ts.Scan(time.Unix(bar.T, 0)); err != nil {
-		return sql.CreateBarParams{}, err
+}
+
+func toPBGap(gap tradingchat.Gap) *apiv1.Candlesticks1MStreamResponse_Gap {
+	return &apiv1.Candlesticks1MStreamResponse_Gap{
+		Symbol:    gap.Symbol,
+		FromId:    gap.FromID,
+		ToId:      gap.ToID,
+		Start:     timestamppb.New(time.Unix(gap.Start, 0)),
+		End:       timestamppb.New(time.Unix(gap.End, 0)),
+		Recovered: gap.Recovered,
 	}
-	return sql.CreateBarParams{
-		H:  h,
-		L:  l,
-		O:  o,
-		C:  c,
-		Ts: ts,
-	}, nil
 }
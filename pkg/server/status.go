@@ -0,0 +1,71 @@
+package server
+
+import (
+	"expvar"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	apiv1 "github.com/rickliujh/trading-chat-aggr/pkg/api/v1"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// feedMetrics counts changes of the health of the feed by kind, and lists stale symbols
+var feedMetrics = expvar.NewMap("feed")
+
+var feedStatuses = map[tradingchat.FeedStatusKind]apiv1.FeedStatus{
+	tradingchat.FeedStale:   apiv1.FeedStatus_FEED_STATUS_STALE,
+	tradingchat.FeedLive:    apiv1.FeedStatus_FEED_STATUS_LIVE,
+	tradingchat.FeedSpike:   apiv1.FeedStatus_FEED_STATUS_SPIKE,
+	tradingchat.FeedStalled: apiv1.FeedStatus_FEED_STATUS_STALLED,
+	tradingchat.FeedResumed: apiv1.FeedStatus_FEED_STATUS_RESUMED,
+}
+
+// watchFeed publishes stale symbols of monitor into the metrics
+func watchFeed(monitor *tradingchat.FeedMonitor) {
+	if monitor == nil {
+		return
+	}
+	feedMetrics.Set("stale_symbols", expvar.Func(func() any { return monitor.Stale() }))
+}
+
+// sendStatus counts st and sends it to subscribers of its symbol, or to every subscriber if it's about the whole feed
+func (s *Service) sendStatus(st tradingchat.FeedStatus) {
+	feedMetrics.Add(string(st.Kind), 1)
+	res := &apiv1.Candlesticks1MStreamResponse{Status: toPBStatus(st)}
+	if st.Symbol != "" {
+		s.send(st.Symbol, res)
+		return
+	}
+
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+	sent := map[*connect.BidiStream[apiv1.Candlesticks1MStreamRequest, apiv1.Candlesticks1MStreamResponse]]bool{}
+	for _, sublist := range s.notifyList {
+		for _, to := range sublist {
+			if !sent[to] {
+				sent[to] = true
+				to.Send(res)
+			}
+		}
+	}
+}
+
+func toPBStatus(st tradingchat.FeedStatus) *apiv1.Candlesticks1MStreamResponse_Status {
+	pb := &apiv1.Candlesticks1MStreamResponse_Status{
+		Symbol:    st.Symbol,
+		Kind:      feedStatuses[st.Kind],
+		At:        timestamppb.New(st.At),
+		LastTrade: timestamppb.New(st.LastTrade),
+		Price:     st.Price,
+		Deviation: st.Deviation,
+	}
+	if st.Silence > 0 {
+		pb.Silence = durationpb.New(st.Silence)
+	}
+	if st.Expected > 0 {
+		pb.Expected = durationpb.New(st.Expected)
+	}
+	return pb
+}
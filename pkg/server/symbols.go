@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+
+	"connectrpc.com/connect"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var (
+	ErrInvalidSymbol     = connect.NewError(connect.CodeInvalidArgument, errors.New("symbols must be 2 to 20 upper case letters or digits"))
+	ErrSymbolsFixed      = connect.NewError(connect.CodeFailedPrecondition, errors.New("symbols of the trade source can't be changed"))
+	ErrSubscribeFailed   = connect.NewError(connect.CodeUnavailable, errors.New("unable to subscribe to symbols"))
+	ErrUnsubscribeFailed = connect.NewError(connect.CodeUnavailable, errors.New("unable to unsubscribe from symbols"))
+
+	symbolRe = regexp.MustCompile(`^[A-Z0-9]{2,20}$`)
+)
+
+// addSymbols registers symbols on both the trade source and the aggregation, and returns the symbols
+// that weren't registered yet, symbols added onDemand are removed once nobody subscribes to them
+func (s *Service) addSymbols(symbols []string, onDemand bool) ([]string, error) {
+	for _, symbol := range symbols {
+		if !symbolRe.MatchString(symbol) {
+			return nil, ErrInvalidSymbol
+		}
+	}
+	source, ok := s.source.(tradingchat.DynamicSource)
+	if !ok {
+		return nil, ErrSymbolsFixed
+	}
+
+	s.symbolsMu.Lock()
+	defer s.symbolsMu.Unlock()
+	added := s.aggr.AddSymbols(symbols)
+	if len(added) == 0 {
+		return nil, nil
+	}
+	if err := source.Subscribe(added); err != nil {
+		s.logger.Error(err, "unable to subscribe", "symbols", added)
+		s.aggr.RemoveSymbols(added)
+		return nil, ErrSubscribeFailed
+	}
+	if onDemand {
+		for _, symbol := range added {
+			s.demanded[symbol] = true
+		}
+	}
+	s.logger.Info("symbols registered", "symbols", added, "on_demand", onDemand)
+	return added, nil
+}
+
+// removeSymbols unregisters symbols, subscribers of them stay connected and get updates again once
+// they are registered again. Legs of derived instruments are kept registered
+func (s *Service) removeSymbols(symbols []string) ([]string, error) {
+	source, ok := s.source.(tradingchat.DynamicSource)
+	if !ok {
+		return nil, ErrSymbolsFixed
+	}
+
+	s.symbolsMu.Lock()
+	defer s.symbolsMu.Unlock()
+	var legs []string
+	symbols = slices.DeleteFunc(slices.Clone(symbols), func(symbol string) bool {
+		if s.aggr.IsLeg(symbol) {
+			legs = append(legs, symbol)
+			return true
+		}
+		return false
+	})
+	if len(legs) > 0 {
+		s.logger.Info("legs of derived instruments kept", "symbols", legs)
+	}
+	if err := source.Unsubscribe(symbols); err != nil {
+		s.logger.Error(err, "unable to unsubscribe", "symbols", symbols)
+		return nil, ErrUnsubscribeFailed
+	}
+	removed := s.aggr.RemoveSymbols(symbols)
+	for _, symbol := range removed {
+		delete(s.demanded, symbol)
+	}
+	s.logger.Info("symbols unregistered", "symbols", removed)
+	return removed, nil
+}
+
+// removeIdleSymbols removes symbols registered on demand that nobody subscribes to anymore
+func (s *Service) removeIdleSymbols(symbols []string) {
+	s.symbolsMu.Lock()
+	var idle []string
+	for _, symbol := range symbols {
+		if s.demanded[symbol] {
+			idle = append(idle, symbol)
+		}
+	}
+	s.symbolsMu.Unlock()
+
+	if len(idle) == 0 {
+		return
+	}
+	// a client may have subscribed again meanwhile
+	s.rw.RLock()
+	var stillIdle []string
+	for _, symbol := range idle {
+		if len(s.notifyList[symbol]) == 0 {
+			stillIdle = append(stillIdle, symbol)
+		}
+	}
+	s.rw.RUnlock()
+	if _, err := s.removeSymbols(stillIdle); err != nil {
+		s.logger.Error(err, "unable to remove idle symbols", "symbols", stillIdle)
+	}
+}
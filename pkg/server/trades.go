@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+var ErrTradesDropped = errors.New("trades dropped before being recorded")
+
+const (
+	tradeBatchSize     = 1000
+	tradeFlushInterval = time.Second
+	// batches waiting for the trade store, later ones are dropped until it catches up
+	tradeBatchQueue = 10
+)
+
+// recordTrades relays the event stream to the aggregator while writing trades to the trade store
+// in batches, a batch is written once it's full or every flush interval. The relay never waits for
+// the store, batches are dropped and counted while it's behind
+func (s *Service) recordTrades(done <-chan struct{}, stream <-chan *bconn.WsAggTradeEvent) <-chan *bconn.WsAggTradeEvent {
+	relay := make(chan *bconn.WsAggTradeEvent)
+	batchCh := make(chan []*bconn.WsAggTradeEvent, tradeBatchQueue)
+	ticker := s.clock.NewTicker(tradeFlushInterval)
+
+	go func() {
+		for batch := range batchCh {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+			err := s.trades.SaveTrades(ctx, batch)
+			cancel()
+			if err != nil {
+				s.logger.Error(err, "failed to record trades", "count", len(batch))
+			}
+		}
+	}()
+
+	go func() {
+		defer close(relay)
+		defer close(batchCh)
+		defer ticker.Stop()
+
+		var batch []*bconn.WsAggTradeEvent
+		dropped := 0
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			select {
+			case batchCh <- batch:
+			default:
+				dropped += len(batch)
+				s.logger.Error(ErrTradesDropped, "trade store is behind", "count", len(batch), "dropped", dropped)
+			}
+			batch = nil
+		}
+		for {
+			select {
+			case <-done:
+				flush()
+				return
+			case <-ticker.C():
+				flush()
+			case e, ok := <-stream:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, e)
+				if len(batch) >= tradeBatchSize {
+					flush()
+				}
+				select {
+				case relay <- e:
+				case <-done:
+					flush()
+					return
+				}
+			}
+		}
+	}()
+
+	return relay
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// stuckTrades never finishes saving until it's released
+type stuckTrades struct {
+	release chan struct{}
+}
+
+func (s *stuckTrades) SaveTrades(ctx context.Context, _ []*bconn.WsAggTradeEvent) error {
+	<-s.release
+	return nil
+}
+
+func TestRecordTrades(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 1})
+
+	t.Run("relay should not wait for a trade store that is behind", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		store := &stuckTrades{release: make(chan struct{})}
+		defer close(store.release)
+		s := &Service{logger: logger, clock: tradingchat.NewFakeClock(time.Unix(1737734700, 0)), trades: store}
+
+		stream := make(chan *bconn.WsAggTradeEvent)
+		relay := s.recordTrades(done, stream)
+		// more full batches than are queued for the store
+		n := (tradeBatchQueue + 3) * tradeBatchSize
+		go func() {
+			defer close(stream)
+			for i := range n {
+				stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: int64(i + 1), Price: "1"}
+			}
+		}()
+
+		relayed := 0
+		timeout := time.After(5 * time.Second)
+	loop:
+		for relayed < n {
+			select {
+			case _, ok := <-relay:
+				if !ok {
+					break loop
+				}
+				relayed++
+			case <-timeout:
+				break loop
+			}
+		}
+		assert.Equal(t, n, relayed)
+	})
+}
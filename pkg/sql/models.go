@@ -8,11 +8,61 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ActivityBar struct {
+	Symbol    string
+	BarType   string
+	Threshold pgtype.Numeric
+	FirstID   int64
+	LastID    int64
+	OpenTime  int64
+	CloseTime int64
+	H         pgtype.Numeric
+	L         pgtype.Numeric
+	O         pgtype.Numeric
+	C         pgtype.Numeric
+	Volume    pgtype.Numeric
+	Notional  pgtype.Numeric
+	Trades    int64
+	Seq       int64
+}
+
+type Aggtrade struct {
+	Symbol       string
+	ID           int64
+	Price        pgtype.Numeric
+	Qty          pgtype.Numeric
+	IsBuyerMaker bool
+	TradeTime    int64
+}
+
+type Checkpoint struct {
+	Name    string
+	State   []byte
+	TakenAt int64
+}
+
 type Ohlc1m struct {
-	ID int64
-	H  pgtype.Numeric
-	L  pgtype.Numeric
-	O  pgtype.Numeric
-	C  pgtype.Numeric
-	Ts pgtype.Timestamp
+	ID     int64
+	H      pgtype.Numeric
+	L      pgtype.Numeric
+	O      pgtype.Numeric
+	C      pgtype.Numeric
+	Ts     pgtype.Timestamp
+	Symbol string
+}
+
+type QuoteBar struct {
+	Symbol     string
+	OpenTime   int64
+	UpdatedAt  int64
+	BidH       pgtype.Numeric
+	BidL       pgtype.Numeric
+	BidO       pgtype.Numeric
+	BidC       pgtype.Numeric
+	AskH       pgtype.Numeric
+	AskL       pgtype.Numeric
+	AskO       pgtype.Numeric
+	AskC       pgtype.Numeric
+	MeanSpread pgtype.Numeric
+	Quotes     int64
 }
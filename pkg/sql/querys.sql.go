@@ -17,7 +17,7 @@ INSERT INTO OHLC1M (
 ) VALUES (
   $1, $2, $3, $4, $5
 )
-RETURNING id, h, l, o, c, ts
+RETURNING id, h, l, o, c, ts, symbol
 `
 
 type CreateBarParams struct {
@@ -44,6 +44,7 @@ func (q *Queries) CreateBar(ctx context.Context, arg CreateBarParams) (Ohlc1m, e
 		&i.O,
 		&i.C,
 		&i.Ts,
+		&i.Symbol,
 	)
 	return i, err
 }
@@ -58,8 +59,112 @@ func (q *Queries) DeleteBar(ctx context.Context, id int64) error {
 	return err
 }
 
+const getCheckpoint = `-- name: GetCheckpoint :one
+SELECT name, state, taken_at FROM CHECKPOINTS
+WHERE name = $1
+`
+
+func (q *Queries) GetCheckpoint(ctx context.Context, name string) (Checkpoint, error) {
+	row := q.db.QueryRow(ctx, getCheckpoint, name)
+	var i Checkpoint
+	err := row.Scan(&i.Name, &i.State, &i.TakenAt)
+	return i, err
+}
+
+const insertTrades = `-- name: InsertTrades :exec
+INSERT INTO AGGTRADES (
+  symbol, id, price, qty, is_buyer_maker, trade_time
+)
+SELECT
+  unnest($1::varchar[]),
+  unnest($2::bigint[]),
+  unnest($3::text[])::numeric,
+  unnest($4::text[])::numeric,
+  unnest($5::boolean[]),
+  unnest($6::bigint[])
+ON CONFLICT DO NOTHING
+`
+
+type InsertTradesParams struct {
+	Symbols       []string
+	Ids           []int64
+	Prices        []string
+	Qtys          []string
+	IsBuyerMakers []bool
+	TradeTimes    []int64
+}
+
+func (q *Queries) InsertTrades(ctx context.Context, arg InsertTradesParams) error {
+	_, err := q.db.Exec(ctx, insertTrades,
+		arg.Symbols,
+		arg.Ids,
+		arg.Prices,
+		arg.Qtys,
+		arg.IsBuyerMakers,
+		arg.TradeTimes,
+	)
+	return err
+}
+
+const listActivityBars = `-- name: ListActivityBars :many
+SELECT symbol, bar_type, threshold, first_id, last_id, open_time, close_time, h, l, o, c, volume, notional, trades, seq FROM ACTIVITY_BARS
+WHERE symbol = $1 AND bar_type = $2 AND threshold = $3
+  AND close_time >= $4 AND close_time < $5
+ORDER BY first_id, seq
+`
+
+type ListActivityBarsParams struct {
+	Symbol    string
+	BarType   string
+	Threshold pgtype.Numeric
+	StartTime int64
+	EndTime   int64
+}
+
+func (q *Queries) ListActivityBars(ctx context.Context, arg ListActivityBarsParams) ([]ActivityBar, error) {
+	rows, err := q.db.Query(ctx, listActivityBars,
+		arg.Symbol,
+		arg.BarType,
+		arg.Threshold,
+		arg.StartTime,
+		arg.EndTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ActivityBar
+	for rows.Next() {
+		var i ActivityBar
+		if err := rows.Scan(
+			&i.Symbol,
+			&i.BarType,
+			&i.Threshold,
+			&i.FirstID,
+			&i.LastID,
+			&i.OpenTime,
+			&i.CloseTime,
+			&i.H,
+			&i.L,
+			&i.O,
+			&i.C,
+			&i.Volume,
+			&i.Notional,
+			&i.Trades,
+			&i.Seq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listBars = `-- name: ListBars :many
-SELECT id, h, l, o, c, ts FROM OHLC1M 
+SELECT id, h, l, o, c, ts, symbol FROM OHLC1M 
 ORDER BY ts
 `
 
@@ -79,6 +184,93 @@ func (q *Queries) ListBars(ctx context.Context) ([]Ohlc1m, error) {
 			&i.O,
 			&i.C,
 			&i.Ts,
+			&i.Symbol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBarsBySymbol = `-- name: ListBarsBySymbol :many
+SELECT id, h, l, o, c, ts, symbol FROM OHLC1M
+WHERE symbol = $1 AND ts >= $2 AND ts < $3
+ORDER BY ts
+`
+
+type ListBarsBySymbolParams struct {
+	Symbol  string
+	StartTs pgtype.Timestamp
+	EndTs   pgtype.Timestamp
+}
+
+func (q *Queries) ListBarsBySymbol(ctx context.Context, arg ListBarsBySymbolParams) ([]Ohlc1m, error) {
+	rows, err := q.db.Query(ctx, listBarsBySymbol, arg.Symbol, arg.StartTs, arg.EndTs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Ohlc1m
+	for rows.Next() {
+		var i Ohlc1m
+		if err := rows.Scan(
+			&i.ID,
+			&i.H,
+			&i.L,
+			&i.O,
+			&i.C,
+			&i.Ts,
+			&i.Symbol,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listQuoteBars = `-- name: ListQuoteBars :many
+SELECT symbol, open_time, updated_at, bid_h, bid_l, bid_o, bid_c, ask_h, ask_l, ask_o, ask_c, mean_spread, quotes FROM QUOTE_BARS
+WHERE symbol = $1 AND open_time >= $2 AND open_time < $3
+ORDER BY open_time
+`
+
+type ListQuoteBarsParams struct {
+	Symbol    string
+	StartTime int64
+	EndTime   int64
+}
+
+func (q *Queries) ListQuoteBars(ctx context.Context, arg ListQuoteBarsParams) ([]QuoteBar, error) {
+	rows, err := q.db.Query(ctx, listQuoteBars, arg.Symbol, arg.StartTime, arg.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QuoteBar
+	for rows.Next() {
+		var i QuoteBar
+		if err := rows.Scan(
+			&i.Symbol,
+			&i.OpenTime,
+			&i.UpdatedAt,
+			&i.BidH,
+			&i.BidL,
+			&i.BidO,
+			&i.BidC,
+			&i.AskH,
+			&i.AskL,
+			&i.AskO,
+			&i.AskC,
+			&i.MeanSpread,
+			&i.Quotes,
 		); err != nil {
 			return nil, err
 		}
@@ -90,6 +282,67 @@ func (q *Queries) ListBars(ctx context.Context) ([]Ohlc1m, error) {
 	return items, nil
 }
 
+const listTrades = `-- name: ListTrades :many
+SELECT symbol, id, price, qty, is_buyer_maker, trade_time FROM AGGTRADES
+WHERE symbol = $1 AND trade_time >= $2 AND trade_time < $3
+ORDER BY id
+`
+
+type ListTradesParams struct {
+	Symbol    string
+	StartTime int64
+	EndTime   int64
+}
+
+func (q *Queries) ListTrades(ctx context.Context, arg ListTradesParams) ([]Aggtrade, error) {
+	rows, err := q.db.Query(ctx, listTrades, arg.Symbol, arg.StartTime, arg.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Aggtrade
+	for rows.Next() {
+		var i Aggtrade
+		if err := rows.Scan(
+			&i.Symbol,
+			&i.ID,
+			&i.Price,
+			&i.Qty,
+			&i.IsBuyerMaker,
+			&i.TradeTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const saveCheckpoint = `-- name: SaveCheckpoint :exec
+INSERT INTO CHECKPOINTS (
+  name, state, taken_at
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (name) DO UPDATE
+  set state = EXCLUDED.state,
+ taken_at = EXCLUDED.taken_at
+`
+
+type SaveCheckpointParams struct {
+	Name    string
+	State   []byte
+	TakenAt int64
+}
+
+func (q *Queries) SaveCheckpoint(ctx context.Context, arg SaveCheckpointParams) error {
+	_, err := q.db.Exec(ctx, saveCheckpoint, arg.Name, arg.State, arg.TakenAt)
+	return err
+}
+
 const updateBar = `-- name: UpdateBar :exec
 UPDATE OHLC1M
   set h = $2,
@@ -117,3 +370,147 @@ func (q *Queries) UpdateBar(ctx context.Context, arg UpdateBarParams) error {
 	)
 	return err
 }
+
+const upsertActivityBar = `-- name: UpsertActivityBar :exec
+INSERT INTO ACTIVITY_BARS (
+  symbol, bar_type, threshold, first_id, last_id, open_time, close_time, h, l, o, c, volume, notional, trades, seq
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+)
+ON CONFLICT (symbol, bar_type, threshold, first_id, seq) DO UPDATE
+  set last_id = EXCLUDED.last_id,
+ close_time = EXCLUDED.close_time,
+ h = EXCLUDED.h,
+ l = EXCLUDED.l,
+ o = EXCLUDED.o,
+ c = EXCLUDED.c,
+ volume = EXCLUDED.volume,
+ notional = EXCLUDED.notional,
+ trades = EXCLUDED.trades
+`
+
+type UpsertActivityBarParams struct {
+	Symbol    string
+	BarType   string
+	Threshold pgtype.Numeric
+	FirstID   int64
+	LastID    int64
+	OpenTime  int64
+	CloseTime int64
+	H         pgtype.Numeric
+	L         pgtype.Numeric
+	O         pgtype.Numeric
+	C         pgtype.Numeric
+	Volume    pgtype.Numeric
+	Notional  pgtype.Numeric
+	Trades    int64
+	Seq       int64
+}
+
+func (q *Queries) UpsertActivityBar(ctx context.Context, arg UpsertActivityBarParams) error {
+	_, err := q.db.Exec(ctx, upsertActivityBar,
+		arg.Symbol,
+		arg.BarType,
+		arg.Threshold,
+		arg.FirstID,
+		arg.LastID,
+		arg.OpenTime,
+		arg.CloseTime,
+		arg.H,
+		arg.L,
+		arg.O,
+		arg.C,
+		arg.Volume,
+		arg.Notional,
+		arg.Trades,
+		arg.Seq,
+	)
+	return err
+}
+
+const upsertBar = `-- name: UpsertBar :exec
+INSERT INTO OHLC1M (
+  symbol, h, l, o, c, ts
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (symbol, ts) DO UPDATE
+  set h = EXCLUDED.h,
+ l = EXCLUDED.l,
+ o = EXCLUDED.o,
+ c = EXCLUDED.c
+`
+
+type UpsertBarParams struct {
+	Symbol string
+	H      pgtype.Numeric
+	L      pgtype.Numeric
+	O      pgtype.Numeric
+	C      pgtype.Numeric
+	Ts     pgtype.Timestamp
+}
+
+func (q *Queries) UpsertBar(ctx context.Context, arg UpsertBarParams) error {
+	_, err := q.db.Exec(ctx, upsertBar,
+		arg.Symbol,
+		arg.H,
+		arg.L,
+		arg.O,
+		arg.C,
+		arg.Ts,
+	)
+	return err
+}
+
+const upsertQuoteBar = `-- name: UpsertQuoteBar :exec
+INSERT INTO QUOTE_BARS (
+  symbol, open_time, updated_at, bid_h, bid_l, bid_o, bid_c, ask_h, ask_l, ask_o, ask_c, mean_spread, quotes
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (symbol, open_time) DO UPDATE
+  set updated_at = EXCLUDED.updated_at,
+ bid_h = EXCLUDED.bid_h,
+ bid_l = EXCLUDED.bid_l,
+ bid_c = EXCLUDED.bid_c,
+ ask_h = EXCLUDED.ask_h,
+ ask_l = EXCLUDED.ask_l,
+ ask_c = EXCLUDED.ask_c,
+ mean_spread = EXCLUDED.mean_spread,
+ quotes = EXCLUDED.quotes
+`
+
+type UpsertQuoteBarParams struct {
+	Symbol     string
+	OpenTime   int64
+	UpdatedAt  int64
+	BidH       pgtype.Numeric
+	BidL       pgtype.Numeric
+	BidO       pgtype.Numeric
+	BidC       pgtype.Numeric
+	AskH       pgtype.Numeric
+	AskL       pgtype.Numeric
+	AskO       pgtype.Numeric
+	AskC       pgtype.Numeric
+	MeanSpread pgtype.Numeric
+	Quotes     int64
+}
+
+func (q *Queries) UpsertQuoteBar(ctx context.Context, arg UpsertQuoteBarParams) error {
+	_, err := q.db.Exec(ctx, upsertQuoteBar,
+		arg.Symbol,
+		arg.OpenTime,
+		arg.UpdatedAt,
+		arg.BidH,
+		arg.BidL,
+		arg.BidO,
+		arg.BidC,
+		arg.AskH,
+		arg.AskL,
+		arg.AskO,
+		arg.AskC,
+		arg.MeanSpread,
+		arg.Quotes,
+	)
+	return err
+}
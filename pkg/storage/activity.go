@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var (
+	_ ActivityBarStore = (*Postgres)(nil)
+	_ ActivityBarStore = (*Memory)(nil)
+	_ ActivityBarStore = (*SQLite)(nil)
+	_ ActivityBarStore = (*ClickHouse)(nil)
+)
+
+// ActivityBarStore keeps closed activity bars, a bar is identified by its symbol, spec, first trade id and seq,
+// saving it again replaces it
+type ActivityBarStore interface {
+	SaveActivityBar(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, bar tradingchat.ActivityBar) error
+	// ListActivityBars returns bars closed within [start, end) ordered by their first trade and seq
+	ListActivityBars(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, start, end time.Time) ([]tradingchat.ActivityBar, error)
+}
+
+// SaveActivityBar implements ActivityBarStore.
+func (p *Postgres) SaveActivityBar(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, bar tradingchat.ActivityBar) error {
+	params := sql.UpsertActivityBarParams{
+		Symbol:    symbol,
+		BarType:   string(spec.Type),
+		FirstID:   bar.FirstID,
+		LastID:    bar.LastID,
+		OpenTime:  bar.OpenT,
+		CloseTime: bar.T,
+		Trades:    bar.Trades,
+		Seq:       bar.Seq,
+	}
+	for _, v := range []struct {
+		s   string
+		dst *pgtype.Numeric
+	}{
+		{formatFloat(spec.Threshold), &params.Threshold},
+		{bar.H, &params.H},
+		{bar.L, &params.L},
+		{bar.O, &params.O},
+		{bar.C, &params.C},
+		{formatFloat(bar.Volume), &params.Volume},
+		{formatFloat(bar.Notional), &params.Notional},
+	} {
+		if err := v.dst.Scan(v.s); err != nil {
+			return err
+		}
+	}
+	return p.q.UpsertActivityBar(ctx, params)
+}
+
+// ListActivityBars implements ActivityBarStore.
+func (p *Postgres) ListActivityBars(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, start, end time.Time) ([]tradingchat.ActivityBar, error) {
+	var threshold pgtype.Numeric
+	if err := threshold.Scan(formatFloat(spec.Threshold)); err != nil {
+		return nil, err
+	}
+	rows, err := p.q.ListActivityBars(ctx, sql.ListActivityBarsParams{
+		Symbol:    symbol,
+		BarType:   string(spec.Type),
+		Threshold: threshold,
+		StartTime: start.Unix(),
+		EndTime:   ceilUnix(end),
+	})
+	if err != nil {
+		return nil, err
+	}
+	bars := make([]tradingchat.ActivityBar, 0, len(rows))
+	for _, row := range rows {
+		bar := tradingchat.ActivityBar{
+			OHLCBar: tradingchat.OHLCBar{T: row.CloseTime},
+			OpenT:   row.OpenTime,
+			FirstID: row.FirstID,
+			Seq:     row.Seq,
+			LastID:  row.LastID,
+			Trades:  row.Trades,
+		}
+		for _, v := range []struct {
+			n   pgtype.Numeric
+			dst *string
+		}{
+			{row.H, &bar.H},
+			{row.L, &bar.L},
+			{row.O, &bar.O},
+			{row.C, &bar.C},
+		} {
+			s, err := numericString(v.n)
+			if err != nil {
+				return nil, err
+			}
+			*v.dst = s
+		}
+		for _, v := range []struct {
+			n   pgtype.Numeric
+			dst *float64
+		}{
+			{row.Volume, &bar.Volume},
+			{row.Notional, &bar.Notional},
+		} {
+			f, err := v.n.Float64Value()
+			if err != nil {
+				return nil, err
+			}
+			*v.dst = f.Float64
+		}
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
+
+// SaveActivityBar implements ActivityBarStore, the latest size bars of every symbol and spec are kept
+func (m *Memory) SaveActivityBar(_ context.Context, symbol string, spec tradingchat.ActivitySpec, bar tradingchat.ActivityBar) error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	key := symbol + "/" + spec.Name()
+	bars := m.activity[key]
+	if n := len(bars); n > 0 && bars[n-1].FirstID == bar.FirstID && bars[n-1].Seq == bar.Seq {
+		bars[n-1] = bar
+		return nil
+	}
+	bars = append(bars, bar)
+	if len(bars) > m.size {
+		bars = bars[len(bars)-m.size:]
+	}
+	m.activity[key] = bars
+	return nil
+}
+
+// ListActivityBars implements ActivityBarStore.
+func (m *Memory) ListActivityBars(_ context.Context, symbol string, spec tradingchat.ActivitySpec, start, end time.Time) ([]tradingchat.ActivityBar, error) {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	var bars []tradingchat.ActivityBar
+	for _, bar := range m.activity[symbol+"/"+spec.Name()] {
+		t := time.Unix(bar.T, 0)
+		if !t.Before(start) && t.Before(end) {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+// SaveActivityBar implements ActivityBarStore.
+func (s *SQLite) SaveActivityBar(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, bar tradingchat.ActivityBar) error {
+	_, err := s.db.ExecContext(ctx, upsertSQLiteActivityBar,
+		symbol, string(spec.Type), formatFloat(spec.Threshold), bar.FirstID, bar.LastID, bar.OpenT, bar.T,
+		bar.H, bar.L, bar.O, bar.C, bar.Volume, bar.Notional, bar.Trades, bar.Seq,
+	)
+	return err
+}
+
+// ListActivityBars implements ActivityBarStore.
+func (s *SQLite) ListActivityBars(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, start, end time.Time) ([]tradingchat.ActivityBar, error) {
+	rows, err := s.db.QueryContext(ctx, listSQLiteActivityBars, symbol, string(spec.Type), formatFloat(spec.Threshold), start.Unix(), ceilUnix(end))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bars []tradingchat.ActivityBar
+	for rows.Next() {
+		var bar tradingchat.ActivityBar
+		if err := rows.Scan(&bar.FirstID, &bar.Seq, &bar.LastID, &bar.OpenT, &bar.T, &bar.H, &bar.L, &bar.O, &bar.C, &bar.Volume, &bar.Notional, &bar.Trades); err != nil {
+			return nil, err
+		}
+		bars = append(bars, bar)
+	}
+	return bars, rows.Err()
+}
+
+type clickhouseActivityBar struct {
+	FirstID   int64   `ch:"first_id"`
+	Seq       int64   `ch:"seq"`
+	LastID    int64   `ch:"last_id"`
+	OpenTime  int64   `ch:"open_time"`
+	CloseTime int64   `ch:"close_time"`
+	H         string  `ch:"h"`
+	L         string  `ch:"l"`
+	O         string  `ch:"o"`
+	C         string  `ch:"c"`
+	Volume    float64 `ch:"volume"`
+	Notional  float64 `ch:"notional"`
+	Trades    int64   `ch:"trades"`
+}
+
+// SaveActivityBar implements ActivityBarStore.
+func (c *ClickHouse) SaveActivityBar(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, bar tradingchat.ActivityBar) error {
+	prices, err := decimals(bar.H, bar.L, bar.O, bar.C)
+	if err != nil {
+		return err
+	}
+	return c.insert(ctx, insertClickHouseActivityBar, func(batch driver.Batch) error {
+		return batch.Append(symbol, string(spec.Type), spec.Threshold, bar.FirstID, bar.LastID, bar.OpenT, bar.T,
+			prices[0], prices[1], prices[2], prices[3], bar.Volume, bar.Notional, bar.Trades, bar.Seq)
+	})
+}
+
+// ListActivityBars implements ActivityBarStore.
+func (c *ClickHouse) ListActivityBars(ctx context.Context, symbol string, spec tradingchat.ActivitySpec, start, end time.Time) ([]tradingchat.ActivityBar, error) {
+	var rows []clickhouseActivityBar
+	if err := c.conn.Select(ctx, &rows, listClickHouseActivityBars, symbol, string(spec.Type), spec.Threshold, start.Unix(), ceilUnix(end)); err != nil {
+		return nil, err
+	}
+	bars := make([]tradingchat.ActivityBar, 0, len(rows))
+	for _, row := range rows {
+		bars = append(bars, tradingchat.ActivityBar{
+			OHLCBar:  tradingchat.OHLCBar{H: row.H, L: row.L, O: row.O, C: row.C, T: row.CloseTime},
+			OpenT:    row.OpenTime,
+			FirstID:  row.FirstID,
+			Seq:      row.Seq,
+			LastID:   row.LastID,
+			Trades:   row.Trades,
+			Volume:   row.Volume,
+			Notional: row.Notional,
+		})
+	}
+	return bars, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
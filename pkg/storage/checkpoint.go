@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var (
+	_ tradingchat.CheckpointStore = (*FileCheckpoints)(nil)
+	_ tradingchat.CheckpointStore = (*PostgresCheckpoints)(nil)
+)
+
+// FileCheckpoints keeps the latest checkpoint in a json file, replaced atomically
+type FileCheckpoints struct {
+	path string
+}
+
+func NewFileCheckpoints(path string) *FileCheckpoints {
+	return &FileCheckpoints{path: path}
+}
+
+// SaveCheckpoint implements tradingchat.CheckpointStore.
+func (f *FileCheckpoints) SaveCheckpoint(_ context.Context, cp tradingchat.Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := errors.Join(tmp.Sync(), tmp.Close()); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// LoadCheckpoint implements tradingchat.CheckpointStore.
+func (f *FileCheckpoints) LoadCheckpoint(_ context.Context) (tradingchat.Checkpoint, bool, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return tradingchat.Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return tradingchat.Checkpoint{}, false, err
+	}
+	var cp tradingchat.Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return tradingchat.Checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
+
+// PostgresCheckpoints keeps the latest checkpoint in the CHECKPOINTS table under name,
+// replicas aggregating the same symbols should share it
+type PostgresCheckpoints struct {
+	q    *sql.Queries
+	name string
+}
+
+func NewPostgresCheckpoints(q *sql.Queries, name string) *PostgresCheckpoints {
+	return &PostgresCheckpoints{q: q, name: name}
+}
+
+// Checkpoints keeps checkpoints under name in the same database as bars
+func (p *Postgres) Checkpoints(name string) *PostgresCheckpoints {
+	return NewPostgresCheckpoints(p.q, name)
+}
+
+// SaveCheckpoint implements tradingchat.CheckpointStore.
+func (p *PostgresCheckpoints) SaveCheckpoint(ctx context.Context, cp tradingchat.Checkpoint) error {
+	state, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return p.q.SaveCheckpoint(ctx, sql.SaveCheckpointParams{
+		Name:    p.name,
+		State:   state,
+		TakenAt: cp.TakenAt,
+	})
+}
+
+// LoadCheckpoint implements tradingchat.CheckpointStore.
+func (p *PostgresCheckpoints) LoadCheckpoint(ctx context.Context) (tradingchat.Checkpoint, bool, error) {
+	row, err := p.q.GetCheckpoint(ctx, p.name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return tradingchat.Checkpoint{}, false, nil
+	}
+	if err != nil {
+		return tradingchat.Checkpoint{}, false, err
+	}
+	var cp tradingchat.Checkpoint
+	if err := json.Unmarshal(row.State, &cp); err != nil {
+		return tradingchat.Checkpoint{}, false, err
+	}
+	return cp, true, nil
+}
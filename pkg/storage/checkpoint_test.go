@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestFileCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileCheckpoints(filepath.Join(t.TempDir(), "state", "checkpoint.json"))
+
+	t.Run("missing checkpoint should not be an error", func(t *testing.T) {
+		_, ok, err := store.LoadCheckpoint(ctx)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("latest checkpoint should be loaded back", func(t *testing.T) {
+		for _, id := range []int64{1, 2} {
+			assert.NoError(t, store.SaveCheckpoint(ctx, tradingchat.Checkpoint{
+				Version: tradingchat.CheckpointVersion,
+				TakenAt: 1737734730,
+				Symbols: map[string]tradingchat.SymbolState{
+					"ETHBTC": {Bar: tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: 1737734720}, EndedAt: 1737734759, LastID: id},
+				},
+			}))
+		}
+
+		cp, ok, err := store.LoadCheckpoint(ctx)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(2), cp.Symbols["ETHBTC"].LastID)
+		assert.Equal(t, int64(1737734720), cp.Symbols["ETHBTC"].Bar.T)
+	})
+}
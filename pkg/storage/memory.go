@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var _ BarStore = (*Memory)(nil)
+
+// Memory is a BarStore that keeps the latest bars of each symbol in a ring buffer,
+// older bars are overwritten once the buffer is full.
+type Memory struct {
+	size     int
+	rings    map[string]*ring
+	activity map[string][]tradingchat.ActivityBar // closed activity bars by symbol and spec
+	quotes   map[string][]tradingchat.QuoteBar    // quote bars by symbol
+	rw       *sync.RWMutex
+}
+
+func NewMemory(size int) *Memory {
+	return &Memory{
+		size:     size,
+		rings:    map[string]*ring{},
+		activity: map[string][]tradingchat.ActivityBar{},
+		quotes:   map[string][]tradingchat.QuoteBar{},
+		rw:       &sync.RWMutex{},
+	}
+}
+
+// SaveBar implements BarStore.
+func (m *Memory) SaveBar(_ context.Context, symbol string, bar tradingchat.OHLCBar) error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	r, ok := m.rings[symbol]
+	if !ok {
+		r = &ring{bars: make([]tradingchat.OHLCBar, m.size)}
+		m.rings[symbol] = r
+	}
+	r.put(bar)
+	return nil
+}
+
+// ListBars implements BarStore.
+func (m *Memory) ListBars(_ context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error) {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	r, ok := m.rings[symbol]
+	if !ok {
+		return nil, nil
+	}
+	from := start.Truncate(interval)
+	var bars []tradingchat.OHLCBar
+	for _, bar := range r.list() {
+		t := bar.OpenTime()
+		if !t.Before(from) && t.Before(end) {
+			// bars are kept as they were saved, listed ones are timed by their open like in other stores
+			bar.T = t.Unix()
+			bars = append(bars, bar)
+		}
+	}
+	return rollupRange(bars, interval, start), nil
+}
+
+type ring struct {
+	bars  []tradingchat.OHLCBar
+	head  int // index of the next slot to write
+	count int
+}
+
+// put replaces the newest bar if both opened at the same minute, otherwise appends it
+func (r *ring) put(bar tradingchat.OHLCBar) {
+	if len(r.bars) == 0 {
+		return
+	}
+	if r.count > 0 {
+		last := (r.head - 1 + len(r.bars)) % len(r.bars)
+		if r.bars[last].OpenTime().Equal(bar.OpenTime()) {
+			r.bars[last] = bar
+			return
+		}
+	}
+	r.bars[r.head] = bar
+	r.head = (r.head + 1) % len(r.bars)
+	if r.count < len(r.bars) {
+		r.count++
+	}
+}
+
+// list returns bars from oldest to newest
+func (r *ring) list() []tradingchat.OHLCBar {
+	bars := make([]tradingchat.OHLCBar, 0, r.count)
+	for i := r.count; i > 0; i-- {
+		bars = append(bars, r.bars[(r.head-i+len(r.bars))%len(r.bars)])
+	}
+	return bars
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+
+	t.Run("bar of the same minute should be replaced", func(t *testing.T) {
+		m := NewMemory(3)
+		assert.NoError(t, m.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "0.11111", L: "0.11111", O: "0.11111", C: "0.11111", T: inittime + 1}))
+		assert.NoError(t, m.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "0.11121", L: "0.11111", O: "0.11111", C: "0.11121", T: inittime + 11}))
+
+		bars, err := m.ListBars(ctx, "BNBBTC", tradingchat.Interval1M, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{{H: "0.11121", L: "0.11111", O: "0.11111", C: "0.11121", T: inittime}},
+			bars,
+		)
+	})
+
+	t.Run("oldest bars should be overwritten when ring is full", func(t *testing.T) {
+		m := NewMemory(3)
+		for i := int64(0); i < 5; i++ {
+			assert.NoError(t, m.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{T: inittime + i*60}))
+		}
+
+		bars, err := m.ListBars(ctx, "ETHBTC", tradingchat.Interval1M, time.Unix(inittime, 0), time.Unix(inittime+5*60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{{T: inittime + 120}, {T: inittime + 180}, {T: inittime + 240}},
+			bars,
+		)
+	})
+
+	t.Run("bars outside of time range should be excluded", func(t *testing.T) {
+		m := NewMemory(10)
+		for i := int64(0); i < 5; i++ {
+			assert.NoError(t, m.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{T: inittime + i*60 + 30}))
+		}
+
+		bars, err := m.ListBars(ctx, "ETHBTC", tradingchat.Interval1M, time.Unix(inittime+60, 0), time.Unix(inittime+180, 0))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{{T: inittime + 60}, {T: inittime + 120}},
+			bars,
+		)
+
+		bars, err = m.ListBars(ctx, "NOEXIST", tradingchat.Interval1M, time.Unix(inittime, 0), time.Unix(inittime+180, 0))
+		assert.NoError(t, err)
+		assert.Empty(t, bars)
+	})
+
+	t.Run("bars should be rolled up to requested interval", func(t *testing.T) {
+		m := NewMemory(20)
+		for i := int64(0); i < 12; i++ {
+			assert.NoError(t, m.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: inittime + i*60}))
+		}
+
+		// 16:06 is in the middle of the 16:05 bucket, which should be left out
+		bars, err := m.ListBars(ctx, "ETHBTC", tradingchat.Interval5M, time.Unix(inittime+60, 0), time.Unix(inittime+12*60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{
+				{H: "1", L: "1", O: "1", C: "1", T: inittime + 300},
+				{H: "1", L: "1", O: "1", C: "1", T: inittime + 600},
+			},
+			bars,
+		)
+	})
+}
+
+func TestMemoryActivityBars(t *testing.T) {
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+	tick := tradingchat.ActivitySpec{Type: tradingchat.ActivityTick, Threshold: 100}
+	bar := func(firstID, closedAt int64) tradingchat.ActivityBar {
+		return tradingchat.ActivityBar{OHLCBar: tradingchat.OHLCBar{T: closedAt}, FirstID: firstID, Trades: 100}
+	}
+
+	t.Run("latest bars of every spec should be kept", func(t *testing.T) {
+		m := NewMemory(2)
+		for i := int64(0); i < 3; i++ {
+			assert.NoError(t, m.SaveActivityBar(ctx, "BNBBTC", tick, bar(i*100+1, inittime+i)))
+		}
+		// saved again
+		assert.NoError(t, m.SaveActivityBar(ctx, "BNBBTC", tick, bar(201, inittime+2)))
+		assert.NoError(t, m.SaveActivityBar(ctx, "BNBBTC", tradingchat.ActivitySpec{Type: tradingchat.ActivityTick, Threshold: 10}, bar(1, inittime)))
+
+		bars, err := m.ListActivityBars(ctx, "BNBBTC", tick, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.ActivityBar{bar(101, inittime+1), bar(201, inittime+2)}, bars)
+
+		bars, err = m.ListActivityBars(ctx, "BNBBTC", tick, time.Unix(inittime+2, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.ActivityBar{bar(201, inittime+2)}, bars)
+	})
+
+	t.Run("bars closed by the same trade should be told apart by seq", func(t *testing.T) {
+		m := NewMemory(10)
+		renko := tradingchat.ActivitySpec{Type: tradingchat.ActivityRenko, Threshold: 10}
+		first, second := bar(1, inittime), bar(1, inittime)
+		second.Seq = 1
+		assert.NoError(t, m.SaveActivityBar(ctx, "BNBBTC", renko, first))
+		assert.NoError(t, m.SaveActivityBar(ctx, "BNBBTC", renko, second))
+		assert.NoError(t, m.SaveActivityBar(ctx, "BNBBTC", renko, second), "saved again")
+
+		bars, err := m.ListActivityBars(ctx, "BNBBTC", renko, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.ActivityBar{first, second}, bars)
+	})
+}
+
+func TestMemoryQuoteBars(t *testing.T) {
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+	bar := func(openTime, quotes int64) tradingchat.QuoteBar {
+		return tradingchat.QuoteBar{Symbol: "BNBBTC", OpenTime: openTime, Quotes: quotes}
+	}
+
+	t.Run("latest bars of every symbol should be kept", func(t *testing.T) {
+		m := NewMemory(2)
+		for i := int64(0); i < 3; i++ {
+			assert.NoError(t, m.SaveQuoteBar(ctx, bar(inittime+i*60, 1)))
+		}
+		// saved again while in progress
+		assert.NoError(t, m.SaveQuoteBar(ctx, bar(inittime+120, 5)))
+		assert.NoError(t, m.SaveQuoteBar(ctx, tradingchat.QuoteBar{Symbol: "ETHBTC", OpenTime: inittime}))
+
+		bars, err := m.ListQuoteBars(ctx, "BNBBTC", time.Unix(inittime, 0), time.Unix(inittime+180, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.QuoteBar{bar(inittime+60, 1), bar(inittime+120, 5)}, bars)
+
+		bars, err = m.ListQuoteBars(ctx, "BNBBTC", time.Unix(inittime+60, 0), time.Unix(inittime+120, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.QuoteBar{bar(inittime+60, 1)}, bars)
+	})
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var (
+	_ BarStore    = (*Postgres)(nil)
+	_ TradeStore  = (*Postgres)(nil)
+	_ TradeReader = (*Postgres)(nil)
+)
+
+// Postgres is a BarStore backed by the OHLC1M table, trades are recorded into AGGTRADES
+type Postgres struct {
+	q *sql.Queries
+}
+
+func NewPostgres(q *sql.Queries) *Postgres {
+	return &Postgres{q: q}
+}
+
+// SaveBar implements BarStore.
+func (p *Postgres) SaveBar(ctx context.Context, symbol string, bar tradingchat.OHLCBar) error {
+	params, err := toDBBar(symbol, bar)
+	if err != nil {
+		return err
+	}
+	return p.q.UpsertBar(ctx, params)
+}
+
+// ListBars implements BarStore.
+func (p *Postgres) ListBars(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error) {
+	var startTs, endTs pgtype.Timestamp
+	if err := startTs.Scan(start.Truncate(interval).UTC()); err != nil {
+		return nil, err
+	}
+	if err := endTs.Scan(end.UTC()); err != nil {
+		return nil, err
+	}
+	rows, err := p.q.ListBarsBySymbol(ctx, sql.ListBarsBySymbolParams{
+		Symbol:  symbol,
+		StartTs: startTs,
+		EndTs:   endTs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bars := make([]tradingchat.OHLCBar, 0, len(rows))
+	for _, row := range rows {
+		bar, err := fromDBBar(row)
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, bar)
+	}
+	return rollupRange(bars, interval, start), nil
+}
+
+// SaveTrades implements TradeStore.
+func (p *Postgres) SaveTrades(ctx context.Context, trades []*bconn.WsAggTradeEvent) error {
+	params := sql.InsertTradesParams{
+		Symbols:       make([]string, 0, len(trades)),
+		Ids:           make([]int64, 0, len(trades)),
+		Prices:        make([]string, 0, len(trades)),
+		Qtys:          make([]string, 0, len(trades)),
+		IsBuyerMakers: make([]bool, 0, len(trades)),
+		TradeTimes:    make([]int64, 0, len(trades)),
+	}
+	for _, t := range trades {
+		params.Symbols = append(params.Symbols, t.Symbol)
+		params.Ids = append(params.Ids, t.AggTradeID)
+		params.Prices = append(params.Prices, t.Price)
+		params.Qtys = append(params.Qtys, t.Quantity)
+		params.IsBuyerMakers = append(params.IsBuyerMakers, t.IsBuyerMaker)
+		params.TradeTimes = append(params.TradeTimes, t.TradeTime)
+	}
+	return p.q.InsertTrades(ctx, params)
+}
+
+// ListTrades implements TradeReader.
+func (p *Postgres) ListTrades(ctx context.Context, symbol string, start, end time.Time) ([]*bconn.WsAggTradeEvent, error) {
+	rows, err := p.q.ListTrades(ctx, sql.ListTradesParams{
+		Symbol:    symbol,
+		StartTime: ceilUnixMilli(start),
+		EndTime:   ceilUnixMilli(end),
+	})
+	if err != nil {
+		return nil, err
+	}
+	trades := make([]*bconn.WsAggTradeEvent, 0, len(rows))
+	for _, row := range rows {
+		price, err := numericString(row.Price)
+		if err != nil {
+			return nil, err
+		}
+		qty, err := numericString(row.Qty)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, &bconn.WsAggTradeEvent{
+			Event:        "aggTrade",
+			Symbol:       row.Symbol,
+			AggTradeID:   row.ID,
+			Price:        price,
+			Quantity:     qty,
+			IsBuyerMaker: row.IsBuyerMaker,
+			TradeTime:    row.TradeTime,
+		})
+	}
+	return trades, nil
+}
+
+func toDBBar(symbol string, bar tradingchat.OHLCBar) (sql.UpsertBarParams, error) {
+	var h pgtype.Numeric
+	if err := h.Scan(bar.H); err != nil {
+		return sql.UpsertBarParams{}, err
+	}
+	var l pgtype.Numeric
+	if err := l.Scan(bar.L); err != nil {
+		return sql.UpsertBarParams{}, err
+	}
+	var o pgtype.Numeric
+	if err := o.Scan(bar.O); err != nil {
+		return sql.UpsertBarParams{}, err
+	}
+	var c pgtype.Numeric
+	if err := c.Scan(bar.C); err != nil {
+		return sql.UpsertBarParams{}, err
+	}
+	var ts pgtype.Timestamp
+	if err := ts.Scan(bar.OpenTime().UTC()); err != nil {
+		return sql.UpsertBarParams{}, err
+	}
+	return sql.UpsertBarParams{
+		Symbol: symbol,
+		H:      h,
+		L:      l,
+		O:      o,
+		C:      c,
+		Ts:     ts,
+	}, nil
+}
+
+func fromDBBar(row sql.Ohlc1m) (tradingchat.OHLCBar, error) {
+	bar := tradingchat.OHLCBar{T: row.Ts.Time.Unix()}
+	for _, v := range []struct {
+		n   pgtype.Numeric
+		dst *string
+	}{
+		{row.H, &bar.H},
+		{row.L, &bar.L},
+		{row.O, &bar.O},
+		{row.C, &bar.C},
+	} {
+		s, err := numericString(v.n)
+		if err != nil {
+			return tradingchat.OHLCBar{}, err
+		}
+		*v.dst = s
+	}
+	return bar, nil
+}
+
+func numericString(n pgtype.Numeric) (string, error) {
+	val, err := n.Value()
+	if err != nil {
+		return "", err
+	}
+	s, _ := val.(string)
+	return s, nil
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var (
+	_ QuoteBarStore = (*Postgres)(nil)
+	_ QuoteBarStore = (*Memory)(nil)
+	_ QuoteBarStore = (*SQLite)(nil)
+	_ QuoteBarStore = (*ClickHouse)(nil)
+)
+
+// QuoteBarStore keeps 1 minute quote bars, a bar is identified by its symbol and the minute it opened at,
+// saving a bar of the same minute again replaces it
+type QuoteBarStore interface {
+	SaveQuoteBar(ctx context.Context, bar tradingchat.QuoteBar) error
+	// ListQuoteBars returns bars opened within [start, end) ordered by time
+	ListQuoteBars(ctx context.Context, symbol string, start, end time.Time) ([]tradingchat.QuoteBar, error)
+}
+
+// SaveQuoteBar implements QuoteBarStore.
+func (p *Postgres) SaveQuoteBar(ctx context.Context, bar tradingchat.QuoteBar) error {
+	params := sql.UpsertQuoteBarParams{
+		Symbol:    bar.Symbol,
+		OpenTime:  bar.OpenTime,
+		UpdatedAt: max(bar.Bid.T, bar.Ask.T),
+		Quotes:    bar.Quotes,
+	}
+	for _, v := range []struct {
+		s   string
+		dst *pgtype.Numeric
+	}{
+		{bar.Bid.H, &params.BidH},
+		{bar.Bid.L, &params.BidL},
+		{bar.Bid.O, &params.BidO},
+		{bar.Bid.C, &params.BidC},
+		{bar.Ask.H, &params.AskH},
+		{bar.Ask.L, &params.AskL},
+		{bar.Ask.O, &params.AskO},
+		{bar.Ask.C, &params.AskC},
+		{formatFloat(bar.MeanSpread), &params.MeanSpread},
+	} {
+		if err := v.dst.Scan(v.s); err != nil {
+			return err
+		}
+	}
+	return p.q.UpsertQuoteBar(ctx, params)
+}
+
+// ListQuoteBars implements QuoteBarStore.
+func (p *Postgres) ListQuoteBars(ctx context.Context, symbol string, start, end time.Time) ([]tradingchat.QuoteBar, error) {
+	rows, err := p.q.ListQuoteBars(ctx, sql.ListQuoteBarsParams{
+		Symbol:    symbol,
+		StartTime: ceilUnix(start),
+		EndTime:   ceilUnix(end),
+	})
+	if err != nil {
+		return nil, err
+	}
+	bars := make([]tradingchat.QuoteBar, 0, len(rows))
+	for _, row := range rows {
+		bar := tradingchat.QuoteBar{
+			Symbol:   row.Symbol,
+			OpenTime: row.OpenTime,
+			Bid:      tradingchat.OHLCBar{T: row.UpdatedAt},
+			Ask:      tradingchat.OHLCBar{T: row.UpdatedAt},
+			Quotes:   row.Quotes,
+		}
+		for _, v := range []struct {
+			n   pgtype.Numeric
+			dst *string
+		}{
+			{row.BidH, &bar.Bid.H},
+			{row.BidL, &bar.Bid.L},
+			{row.BidO, &bar.Bid.O},
+			{row.BidC, &bar.Bid.C},
+			{row.AskH, &bar.Ask.H},
+			{row.AskL, &bar.Ask.L},
+			{row.AskO, &bar.Ask.O},
+			{row.AskC, &bar.Ask.C},
+		} {
+			s, err := numericString(v.n)
+			if err != nil {
+				return nil, err
+			}
+			*v.dst = s
+		}
+		f, err := row.MeanSpread.Float64Value()
+		if err != nil {
+			return nil, err
+		}
+		bar.MeanSpread = f.Float64
+		bars = append(bars, bar)
+	}
+	return bars, nil
+}
+
+// SaveQuoteBar implements QuoteBarStore, the latest size bars of every symbol are kept
+func (m *Memory) SaveQuoteBar(_ context.Context, bar tradingchat.QuoteBar) error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	bars := m.quotes[bar.Symbol]
+	if n := len(bars); n > 0 && bars[n-1].OpenTime == bar.OpenTime {
+		bars[n-1] = bar
+		return nil
+	}
+	bars = append(bars, bar)
+	if len(bars) > m.size {
+		bars = bars[len(bars)-m.size:]
+	}
+	m.quotes[bar.Symbol] = bars
+	return nil
+}
+
+// ListQuoteBars implements QuoteBarStore.
+func (m *Memory) ListQuoteBars(_ context.Context, symbol string, start, end time.Time) ([]tradingchat.QuoteBar, error) {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	var bars []tradingchat.QuoteBar
+	for _, bar := range m.quotes[symbol] {
+		t := time.Unix(bar.OpenTime, 0)
+		if !t.Before(start) && t.Before(end) {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+// SaveQuoteBar implements QuoteBarStore.
+func (s *SQLite) SaveQuoteBar(ctx context.Context, bar tradingchat.QuoteBar) error {
+	_, err := s.db.ExecContext(ctx, upsertSQLiteQuoteBar,
+		bar.Symbol, bar.OpenTime, max(bar.Bid.T, bar.Ask.T),
+		bar.Bid.H, bar.Bid.L, bar.Bid.O, bar.Bid.C, bar.Ask.H, bar.Ask.L, bar.Ask.O, bar.Ask.C,
+		bar.MeanSpread, bar.Quotes,
+	)
+	return err
+}
+
+// ListQuoteBars implements QuoteBarStore.
+func (s *SQLite) ListQuoteBars(ctx context.Context, symbol string, start, end time.Time) ([]tradingchat.QuoteBar, error) {
+	rows, err := s.db.QueryContext(ctx, listSQLiteQuoteBars, symbol, ceilUnix(start), ceilUnix(end))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bars []tradingchat.QuoteBar
+	for rows.Next() {
+		var bar tradingchat.QuoteBar
+		if err := rows.Scan(&bar.Symbol, &bar.OpenTime, &bar.Bid.T,
+			&bar.Bid.H, &bar.Bid.L, &bar.Bid.O, &bar.Bid.C, &bar.Ask.H, &bar.Ask.L, &bar.Ask.O, &bar.Ask.C,
+			&bar.MeanSpread, &bar.Quotes,
+		); err != nil {
+			return nil, err
+		}
+		bar.Ask.T = bar.Bid.T
+		bars = append(bars, bar)
+	}
+	return bars, rows.Err()
+}
+
+type clickhouseQuoteBar struct {
+	Symbol     string  `ch:"symbol"`
+	OpenTime   int64   `ch:"open_time"`
+	UpdatedAt  int64   `ch:"updated_at"`
+	BidH       string  `ch:"bid_h"`
+	BidL       string  `ch:"bid_l"`
+	BidO       string  `ch:"bid_o"`
+	BidC       string  `ch:"bid_c"`
+	AskH       string  `ch:"ask_h"`
+	AskL       string  `ch:"ask_l"`
+	AskO       string  `ch:"ask_o"`
+	AskC       string  `ch:"ask_c"`
+	MeanSpread float64 `ch:"mean_spread"`
+	Quotes     int64   `ch:"quotes"`
+}
+
+// SaveQuoteBar implements QuoteBarStore.
+func (c *ClickHouse) SaveQuoteBar(ctx context.Context, bar tradingchat.QuoteBar) error {
+	prices, err := decimals(bar.Bid.H, bar.Bid.L, bar.Bid.O, bar.Bid.C, bar.Ask.H, bar.Ask.L, bar.Ask.O, bar.Ask.C)
+	if err != nil {
+		return err
+	}
+	return c.insert(ctx, insertClickHouseQuoteBar, func(batch driver.Batch) error {
+		return batch.Append(bar.Symbol, bar.OpenTime, max(bar.Bid.T, bar.Ask.T),
+			prices[0], prices[1], prices[2], prices[3], prices[4], prices[5], prices[6], prices[7],
+			bar.MeanSpread, bar.Quotes)
+	})
+}
+
+// ListQuoteBars implements QuoteBarStore.
+func (c *ClickHouse) ListQuoteBars(ctx context.Context, symbol string, start, end time.Time) ([]tradingchat.QuoteBar, error) {
+	var rows []clickhouseQuoteBar
+	if err := c.conn.Select(ctx, &rows, listClickHouseQuoteBars, symbol, ceilUnix(start), ceilUnix(end)); err != nil {
+		return nil, err
+	}
+	bars := make([]tradingchat.QuoteBar, 0, len(rows))
+	for _, row := range rows {
+		bars = append(bars, tradingchat.QuoteBar{
+			Symbol:     row.Symbol,
+			OpenTime:   row.OpenTime,
+			Bid:        tradingchat.OHLCBar{H: row.BidH, L: row.BidL, O: row.BidO, C: row.BidC, T: row.UpdatedAt},
+			Ask:        tradingchat.OHLCBar{H: row.AskH, L: row.AskL, O: row.AskO, C: row.AskC, T: row.UpdatedAt},
+			MeanSpread: row.MeanSpread,
+			Quotes:     row.Quotes,
+		})
+	}
+	return bars, nil
+}
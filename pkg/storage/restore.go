@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+// CurrentBars restores the bar in progress at now of every symbol, rebuilt from the trade tape
+// if trades isn't nil, falling back to the latest bar saved in store. Symbols without trades
+// in the current minute are left out
+func CurrentBars(ctx context.Context, logger logr.Logger, store BarStore, trades TradeReader, symbols []string, now time.Time) (map[string]tradingchat.OHLCBar, error) {
+	minute := now.Truncate(tradingchat.Interval1M)
+	recent, err := RecentBars(ctx, logger, store, trades, symbols, minute, now)
+	bars := map[string]tradingchat.OHLCBar{}
+	for symbol, restored := range recent {
+		bars[symbol] = restored[len(restored)-1]
+	}
+	return bars, err
+}
+
+// RecentBars restores 1m bars of every symbol opened from start until now ordered by time, the last of which
+// may still be in progress. They are rebuilt from the trade tape if trades isn't nil, falling back to bars saved
+// in store. Symbols without trades since start are left out
+func RecentBars(ctx context.Context, logger logr.Logger, store BarStore, trades TradeReader, symbols []string, start, now time.Time) (map[string][]tradingchat.OHLCBar, error) {
+	start = start.Truncate(tradingchat.Interval1M)
+	end := now.Truncate(tradingchat.Interval1M).Add(tradingchat.Interval1M)
+	bars := map[string][]tradingchat.OHLCBar{}
+	var errs []error
+	for _, symbol := range symbols {
+		if trades != nil {
+			tape, err := trades.ListTrades(ctx, symbol, start, end)
+			if err != nil {
+				errs = append(errs, err)
+			} else if rebuilt, err := tradingchat.RebuildBars(logger, tape, tradingchat.Interval1M); err == nil && len(rebuilt) > 0 {
+				bars[symbol] = rebuilt
+				continue
+			}
+		}
+
+		if store == nil {
+			continue
+		}
+		saved, err := store.ListBars(ctx, symbol, tradingchat.Interval1M, start, end)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(saved) > 0 {
+			bars[symbol] = saved
+		}
+	}
+	return bars, errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestCurrentBars(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ctx := context.Background()
+	// 16:05:30 on Jan 24th 2025
+	now := time.Unix(1737734730, 0)
+
+	store := NewMemory(10)
+	assert.NoError(t, store.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{H: "2", L: "2", O: "2", C: "2", T: 1737734640}))
+	assert.NoError(t, store.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: 1737734640}))
+	assert.NoError(t, store.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "3", L: "1", O: "2", C: "1", T: 1737734710}))
+
+	tape, err := NewFileTape(t.TempDir(), 100)
+	assert.NoError(t, err)
+	defer tape.Close()
+	assert.NoError(t, tape.SaveTrades(ctx, []*bconn.WsAggTradeEvent{
+		{Symbol: "BNBBTC", AggTradeID: 1, Price: "1", TradeTime: 1737734699000},
+		{Symbol: "BNBBTC", AggTradeID: 2, Price: "4", TradeTime: 1737734701000},
+		{Symbol: "BNBBTC", AggTradeID: 3, Price: "2", TradeTime: 1737734720000},
+	}))
+
+	t.Run("bars of the current minute should be restored from store", func(t *testing.T) {
+		bars, err := CurrentBars(ctx, logger, store, nil, []string{"BNBBTC", "ETHBTC"}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]tradingchat.OHLCBar{
+			"BNBBTC": {H: "3", L: "1", O: "2", C: "1", T: 1737734700},
+		}, bars)
+	})
+
+	t.Run("trade tape should be preferred over store", func(t *testing.T) {
+		bars, err := CurrentBars(ctx, logger, store, tape, []string{"BNBBTC", "ETHBTC"}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]tradingchat.OHLCBar{
+			"BNBBTC": {H: "4", L: "2", O: "4", C: "2", T: 1737734720},
+		}, bars)
+	})
+
+	t.Run("bars closed since start should be restored along with the bar in progress", func(t *testing.T) {
+		bars, err := RecentBars(ctx, logger, store, tape, []string{"BNBBTC", "ETHBTC"}, now.Add(-2*time.Minute), now)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string][]tradingchat.OHLCBar{
+			"BNBBTC": {
+				{H: "1", L: "1", O: "1", C: "1", T: 1737734699},
+				{H: "4", L: "2", O: "4", C: "2", T: 1737734720},
+			},
+			"ETHBTC": {{H: "2", L: "2", O: "2", C: "2", T: 1737734640}},
+		}, bars)
+	})
+}
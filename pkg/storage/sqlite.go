@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	// pure-Go driver, registers itself as SQLiteDriver
+	_ "modernc.org/sqlite"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var _ BarStore = (*SQLite)(nil)
+
+// SQLiteDriver is the database/sql driver name registered by modernc.org/sqlite
+const SQLiteDriver = "sqlite"
+
+const (
+	// prices are kept as text so they read back exactly as they were written, same as NUMERIC in postgres
+	createSQLiteBars = `CREATE TABLE IF NOT EXISTS OHLC1M (
+  id     INTEGER PRIMARY KEY AUTOINCREMENT,
+  h      TEXT,
+  l      TEXT,
+  o      TEXT,
+  c      TEXT,
+  ts     INTEGER,
+  symbol TEXT NOT NULL DEFAULT ''
+)`
+	createSQLiteBarsIndex = `CREATE UNIQUE INDEX IF NOT EXISTS ohlc1m_symbol_ts_key ON OHLC1M (symbol, ts)`
+
+	upsertSQLiteBar = `INSERT INTO OHLC1M (
+  symbol, h, l, o, c, ts
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (symbol, ts) DO UPDATE
+  set h = excluded.h,
+ l = excluded.l,
+ o = excluded.o,
+ c = excluded.c`
+
+	listSQLiteBars = `SELECT h, l, o, c, ts FROM OHLC1M
+WHERE symbol = ? AND ts >= ? AND ts < ?
+ORDER BY ts`
+
+	// thresholds are kept as text formatted like the spec names them, so they compare exactly
+	createSQLiteActivityBars = `CREATE TABLE IF NOT EXISTS ACTIVITY_BARS (
+  symbol     TEXT NOT NULL,
+  bar_type   TEXT NOT NULL,
+  threshold  TEXT NOT NULL,
+  first_id   INTEGER NOT NULL,
+  last_id    INTEGER NOT NULL,
+  open_time  INTEGER NOT NULL,
+  close_time INTEGER NOT NULL,
+  h          TEXT NOT NULL,
+  l          TEXT NOT NULL,
+  o          TEXT NOT NULL,
+  c          TEXT NOT NULL,
+  volume     REAL NOT NULL,
+  notional   REAL NOT NULL,
+  trades     INTEGER NOT NULL,
+  seq        INTEGER NOT NULL,
+  PRIMARY KEY (symbol, bar_type, threshold, first_id, seq)
+)`
+	createSQLiteActivityBarsIndex = `CREATE INDEX IF NOT EXISTS activity_bars_close_time_idx ON ACTIVITY_BARS (symbol, bar_type, threshold, close_time)`
+
+	upsertSQLiteActivityBar = `INSERT INTO ACTIVITY_BARS (
+  symbol, bar_type, threshold, first_id, last_id, open_time, close_time, h, l, o, c, volume, notional, trades, seq
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (symbol, bar_type, threshold, first_id, seq) DO UPDATE
+  set last_id = excluded.last_id,
+ close_time = excluded.close_time,
+ h = excluded.h,
+ l = excluded.l,
+ o = excluded.o,
+ c = excluded.c,
+ volume = excluded.volume,
+ notional = excluded.notional,
+ trades = excluded.trades`
+
+	listSQLiteActivityBars = `SELECT first_id, seq, last_id, open_time, close_time, h, l, o, c, volume, notional, trades FROM ACTIVITY_BARS
+WHERE symbol = ? AND bar_type = ? AND threshold = ?
+  AND close_time >= ? AND close_time < ?
+ORDER BY first_id, seq`
+
+	createSQLiteQuoteBars = `CREATE TABLE IF NOT EXISTS QUOTE_BARS (
+  symbol      TEXT NOT NULL,
+  open_time   INTEGER NOT NULL,
+  updated_at  INTEGER NOT NULL,
+  bid_h       TEXT NOT NULL,
+  bid_l       TEXT NOT NULL,
+  bid_o       TEXT NOT NULL,
+  bid_c       TEXT NOT NULL,
+  ask_h       TEXT NOT NULL,
+  ask_l       TEXT NOT NULL,
+  ask_o       TEXT NOT NULL,
+  ask_c       TEXT NOT NULL,
+  mean_spread REAL NOT NULL,
+  quotes      INTEGER NOT NULL,
+  PRIMARY KEY (symbol, open_time)
+)`
+
+	upsertSQLiteQuoteBar = `INSERT INTO QUOTE_BARS (
+  symbol, open_time, updated_at, bid_h, bid_l, bid_o, bid_c, ask_h, ask_l, ask_o, ask_c, mean_spread, quotes
+) VALUES (
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+ON CONFLICT (symbol, open_time) DO UPDATE
+  set updated_at = excluded.updated_at,
+ bid_h = excluded.bid_h,
+ bid_l = excluded.bid_l,
+ bid_c = excluded.bid_c,
+ ask_h = excluded.ask_h,
+ ask_l = excluded.ask_l,
+ ask_c = excluded.ask_c,
+ mean_spread = excluded.mean_spread,
+ quotes = excluded.quotes`
+
+	listSQLiteQuoteBars = `SELECT symbol, open_time, updated_at, bid_h, bid_l, bid_o, bid_c, ask_h, ask_l, ask_o, ask_c, mean_spread, quotes FROM QUOTE_BARS
+WHERE symbol = ? AND open_time >= ? AND open_time < ?
+ORDER BY open_time`
+)
+
+// SQLite is a BarStore, an ActivityBarStore and a QuoteBarStore in a single SQLite file with the same tables and upsert semantics
+// as Postgres, ts holds unix seconds of the minute a bar opened at
+type SQLite struct {
+	db *sql.DB
+}
+
+func NewSQLite(db *sql.DB) *SQLite {
+	// sqlite allows a single writer, queueing writes here beats failing on a busy database
+	db.SetMaxOpenConns(1)
+	return &SQLite{db: db}
+}
+
+// Init creates tables if they don't exist yet
+func (s *SQLite) Init(ctx context.Context) error {
+	for _, stmt := range []string{createSQLiteBars, createSQLiteBarsIndex, createSQLiteActivityBars, createSQLiteActivityBarsIndex, createSQLiteQuoteBars} {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveBar implements BarStore.
+func (s *SQLite) SaveBar(ctx context.Context, symbol string, bar tradingchat.OHLCBar) error {
+	_, err := s.db.ExecContext(ctx, upsertSQLiteBar, symbol, bar.H, bar.L, bar.O, bar.C, bar.OpenTime().Unix())
+	return err
+}
+
+// ListBars implements BarStore.
+func (s *SQLite) ListBars(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error) {
+	rows, err := s.db.QueryContext(ctx, listSQLiteBars, symbol, start.Truncate(interval).Unix(), ceilUnix(end))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bars []tradingchat.OHLCBar
+	for rows.Next() {
+		var bar tradingchat.OHLCBar
+		if err := rows.Scan(&bar.H, &bar.L, &bar.O, &bar.C, &bar.T); err != nil {
+			return nil, err
+		}
+		bars = append(bars, bar)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rollupRange(bars, interval, start), nil
+}
+
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+func TestSQLite(t *testing.T) {
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+
+	newStore := func(t *testing.T) *SQLite {
+		db, err := sql.Open(SQLiteDriver, filepath.Join(t.TempDir(), "bars.db"))
+		assert.NoError(t, err)
+		s := NewSQLite(db)
+		t.Cleanup(func() { s.Close() })
+		assert.NoError(t, s.Init(ctx))
+		assert.NoError(t, s.Init(ctx), "init should be idempotent")
+		return s
+	}
+
+	t.Run("bar of the same minute should be upserted", func(t *testing.T) {
+		s := newStore(t)
+		assert.NoError(t, s.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{H: "0.11111", L: "0.11111", O: "0.11111", C: "0.11111", T: inittime + 1}))
+		assert.NoError(t, s.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{H: "0.11120", L: "0.11101", O: "0.11111", C: "0.11120", T: inittime + 11}))
+		assert.NoError(t, s.SaveBar(ctx, "BNBBTC", tradingchat.OHLCBar{H: "0.01000", L: "0.01000", O: "0.01000", C: "0.01000", T: inittime + 5}))
+
+		bars, err := s.ListBars(ctx, "ETHBTC", tradingchat.Interval1M, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{{H: "0.11120", L: "0.11101", O: "0.11111", C: "0.11120", T: inittime}},
+			bars,
+		)
+	})
+
+	t.Run("range should be half-open and rolled up to interval", func(t *testing.T) {
+		s := newStore(t)
+		for i := int64(0); i < 10; i++ {
+			assert.NoError(t, s.SaveBar(ctx, "ETHBTC", tradingchat.OHLCBar{H: "1", L: "1", O: "1", C: "1", T: inittime + i*60}))
+		}
+
+		bars, err := s.ListBars(ctx, "ETHBTC", tradingchat.Interval1M, time.Unix(inittime+60, 0), time.Unix(inittime+180, 0))
+		assert.NoError(t, err)
+		assert.Len(t, bars, 2)
+
+		bars, err = s.ListBars(ctx, "ETHBTC", tradingchat.Interval5M, time.Unix(inittime, 0), time.Unix(inittime+600, 0))
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]tradingchat.OHLCBar{
+				{H: "1", L: "1", O: "1", C: "1", T: inittime},
+				{H: "1", L: "1", O: "1", C: "1", T: inittime + 300},
+			},
+			bars,
+		)
+	})
+
+	t.Run("activity bars should be upserted and listed by close time", func(t *testing.T) {
+		s := newStore(t)
+		renko := tradingchat.ActivitySpec{Type: tradingchat.ActivityRenko, Threshold: 0.001}
+		up := tradingchat.ActivityBar{OHLCBar: tradingchat.OHLCBar{H: "0.112", L: "0.111", O: "0.111", C: "0.112", T: inittime + 5}, OpenT: inittime + 1, FirstID: 1, LastID: 4, Trades: 4, Volume: 1.5, Notional: 0.1675}
+		// the same trade closed the next brick too, opened by the trade it shares FirstID with
+		next := tradingchat.ActivityBar{OHLCBar: tradingchat.OHLCBar{H: "0.113", L: "0.112", O: "0.112", C: "0.113", T: inittime + 5}, OpenT: inittime + 5, FirstID: 1, Seq: 1, LastID: 4, Trades: 1, Volume: 0.5, Notional: 0.0565}
+		assert.NoError(t, s.SaveActivityBar(ctx, "ETHBTC", renko, next))
+		assert.NoError(t, s.SaveActivityBar(ctx, "ETHBTC", renko, tradingchat.ActivityBar{OHLCBar: tradingchat.OHLCBar{H: "0.112", L: "0.111", O: "0.111", C: "0.112", T: inittime + 4}, OpenT: inittime + 1, FirstID: 1, LastID: 3}))
+		assert.NoError(t, s.SaveActivityBar(ctx, "ETHBTC", renko, up), "saved again")
+		assert.NoError(t, s.SaveActivityBar(ctx, "ETHBTC", tradingchat.ActivitySpec{Type: tradingchat.ActivityRenko, Threshold: 0.01}, up))
+
+		bars, err := s.ListActivityBars(ctx, "ETHBTC", renko, time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.ActivityBar{up, next}, bars)
+
+		bars, err = s.ListActivityBars(ctx, "ETHBTC", renko, time.Unix(inittime+6, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Empty(t, bars)
+	})
+
+	t.Run("quote bar of the same minute should be upserted", func(t *testing.T) {
+		s := newStore(t)
+		bar := func(openTime, updatedAt, quotes int64) tradingchat.QuoteBar {
+			return tradingchat.QuoteBar{
+				Symbol:     "ETHBTC",
+				OpenTime:   openTime,
+				Bid:        tradingchat.OHLCBar{H: "0.0110", L: "0.0109", O: "0.0110", C: "0.0109", T: updatedAt},
+				Ask:        tradingchat.OHLCBar{H: "0.0112", L: "0.0110", O: "0.0112", C: "0.0110", T: updatedAt},
+				MeanSpread: 0.00015,
+				Quotes:     quotes,
+			}
+		}
+		assert.NoError(t, s.SaveQuoteBar(ctx, bar(inittime, inittime+10, 2)))
+		assert.NoError(t, s.SaveQuoteBar(ctx, bar(inittime, inittime+50, 5)))
+		assert.NoError(t, s.SaveQuoteBar(ctx, bar(inittime+60, inittime+61, 1)))
+
+		bars, err := s.ListQuoteBars(ctx, "ETHBTC", time.Unix(inittime, 0), time.Unix(inittime+60, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []tradingchat.QuoteBar{bar(inittime, inittime+50, 5)}, bars)
+	})
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var (
+	ErrUnsupportedScheme = errors.New("unsupported storage uri scheme")
+	ErrTradesUnsupported = errors.New("storage doesn't support recording trades")
+)
+
+// BarStore keeps 1 minute bars of every symbol, a bar is identified by its symbol
+// and the minute it opened at, saving a bar of the same minute again replaces it.
+type BarStore interface {
+	SaveBar(ctx context.Context, symbol string, bar tradingchat.OHLCBar) error
+	// ListBars returns bars of the interval opened within [start, end) ordered by time, T of listed bars is
+	// the minute they opened at whatever it was saved with. Intervals longer than 1 minute are rolled up
+	// from 1 minute bars and timed by the bucket they opened at
+	ListBars(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error)
+}
+
+// TradeStore records raw trades as they are received, a trade recorded twice is kept once
+type TradeStore interface {
+	SaveTrades(ctx context.Context, trades []*bconn.WsAggTradeEvent) error
+}
+
+// TradeReader reads recorded trades of a symbol traded within [start, end) back, ordered by trade id
+type TradeReader interface {
+	ListTrades(ctx context.Context, symbol string, start, end time.Time) ([]*bconn.WsAggTradeEvent, error)
+}
+
+// rollupRange rolls 1 minute bars listed from start truncated to interval up, timed by the bucket they opened
+// at like continuous aggregates, and drops the leading bucket that opened before start
+func rollupRange(bars []tradingchat.OHLCBar, interval time.Duration, start time.Time) []tradingchat.OHLCBar {
+	if interval <= tradingchat.Interval1M {
+		return bars
+	}
+	res := tradingchat.Rollup(bars, interval)
+	for len(res) > 0 && time.Unix(res[0].T, 0).Truncate(interval).Before(start) {
+		res = res[1:]
+	}
+	for i := range res {
+		res[i].T = time.Unix(res[i].T, 0).Truncate(interval).Unix()
+	}
+	return res
+}
+
+// ceilUnix rounds t up to whole seconds, so ranges over unix seconds stay half-open
+func ceilUnix(t time.Time) int64 {
+	if t.Nanosecond() > 0 {
+		return t.Unix() + 1
+	}
+	return t.Unix()
+}
+
+// ceilUnixMilli rounds t up to whole milliseconds, trade times are kept in milliseconds
+func ceilUnixMilli(t time.Time) int64 {
+	if t.Nanosecond()%int(time.Millisecond) > 0 {
+		return t.UnixMilli() + 1
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+var (
+	_ TradeStore  = (*FileTape)(nil)
+	_ TradeReader = (*FileTape)(nil)
+)
+
+const tapeSegmentExt = ".jsonl.gz"
+
+// FileTape records trades into gzipped json lines segment files, dir/SYMBOL/{first trade time in ms}-{first trade id}.jsonl.gz,
+// a segment is rotated after segmentSize trades. Segments are flushed after every write so that
+// everything saved is readable, even if the process dies before the segment is closed
+type FileTape struct {
+	dir         string
+	segmentSize int
+	segments    map[string]*tapeSegment
+	mu          *sync.Mutex
+}
+
+type tapeSegment struct {
+	f     *os.File
+	gz    *gzip.Writer
+	enc   *json.Encoder
+	count int
+}
+
+func NewFileTape(dir string, segmentSize int) (*FileTape, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileTape{
+		dir:         dir,
+		segmentSize: segmentSize,
+		segments:    map[string]*tapeSegment{},
+		mu:          &sync.Mutex{},
+	}, nil
+}
+
+// SaveTrades implements TradeStore.
+func (t *FileTape) SaveTrades(_ context.Context, trades []*bconn.WsAggTradeEvent) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	touched := map[string]*tapeSegment{}
+	for _, trade := range trades {
+		seg, err := t.segment(trade)
+		if err != nil {
+			return err
+		}
+		if err := seg.enc.Encode(trade); err != nil {
+			return err
+		}
+		seg.count++
+		touched[trade.Symbol] = seg
+
+		if seg.count >= t.segmentSize {
+			delete(touched, trade.Symbol)
+			if err := t.rotate(trade.Symbol); err != nil {
+				return err
+			}
+		}
+	}
+	for _, seg := range touched {
+		if err := seg.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// segment returns the open segment of the trade's symbol, opening a new one starting at the trade if there is none
+func (t *FileTape) segment(trade *bconn.WsAggTradeEvent) (*tapeSegment, error) {
+	if seg, ok := t.segments[trade.Symbol]; ok {
+		return seg, nil
+	}
+	dir := filepath.Join(t.dir, trade.Symbol)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("%013d-%012d%s", trade.TradeTime, trade.AggTradeID, tapeSegmentExt)
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	gz := gzip.NewWriter(f)
+	seg := &tapeSegment{f: f, gz: gz, enc: json.NewEncoder(gz)}
+	t.segments[trade.Symbol] = seg
+	return seg, nil
+}
+
+func (t *FileTape) rotate(symbol string) error {
+	seg, ok := t.segments[symbol]
+	if !ok {
+		return nil
+	}
+	delete(t.segments, symbol)
+	if err := seg.gz.Close(); err != nil {
+		seg.f.Close()
+		return err
+	}
+	return seg.f.Close()
+}
+
+// ListTrades implements TradeReader.
+func (t *FileTape) ListTrades(_ context.Context, symbol string, start, end time.Time) ([]*bconn.WsAggTradeEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entries, err := os.ReadDir(filepath.Join(t.dir, symbol))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type segFile struct {
+		name  string
+		first int64
+	}
+	var files []segFile
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), tapeSegmentExt) {
+			continue
+		}
+		var first, id int64
+		if _, err := fmt.Sscanf(e.Name(), "%d-%d", &first, &id); err != nil {
+			continue
+		}
+		files = append(files, segFile{name: e.Name(), first: first})
+	}
+	// zero padded names sort by time
+	sort.Slice(files, func(i, j int) bool { return files[i].name < files[j].name })
+
+	from, to := ceilUnixMilli(start), ceilUnixMilli(end)
+	var trades []*bconn.WsAggTradeEvent
+	for i, f := range files {
+		// a segment ends where the next one starts
+		if f.first >= to || (i+1 < len(files) && files[i+1].first < from) {
+			continue
+		}
+		seg, err := readTapeSegment(filepath.Join(t.dir, symbol, f.name))
+		if err != nil {
+			return nil, err
+		}
+		for _, trade := range seg {
+			if trade.TradeTime >= from && trade.TradeTime < to {
+				trades = append(trades, trade)
+			}
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].AggTradeID < trades[j].AggTradeID })
+	return slices.CompactFunc(trades, func(a, b *bconn.WsAggTradeEvent) bool {
+		return a.AggTradeID == b.AggTradeID
+	}), nil
+}
+
+// readTapeSegment reads all trades of a segment, the one being written has no gzip footer yet
+func readTapeSegment(path string) ([]*bconn.WsAggTradeEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var trades []*bconn.WsAggTradeEvent
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var trade bconn.WsAggTradeEvent
+		if err := json.Unmarshal(scanner.Bytes(), &trade); err != nil {
+			return nil, err
+		}
+		trades = append(trades, &trade)
+	}
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return trades, nil
+}
+
+// Close closes segments being written
+func (t *FileTape) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var errs []error
+	for symbol := range t.segments {
+		errs = append(errs, t.rotate(symbol))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileTape(t *testing.T) {
+	ctx := context.Background()
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+
+	trades := func(from, to int64) []*bconn.WsAggTradeEvent {
+		var res []*bconn.WsAggTradeEvent
+		for id := from; id < to; id++ {
+			res = append(res, &bconn.WsAggTradeEvent{
+				Symbol:     "ETHBTC",
+				AggTradeID: id,
+				Price:      "0.11111",
+				Quantity:   "1",
+				TradeTime:  (inittime + id*10) * 1000,
+			})
+		}
+		return res
+	}
+	ids := func(trades []*bconn.WsAggTradeEvent) []int64 {
+		var res []int64
+		for _, t := range trades {
+			res = append(res, t.AggTradeID)
+		}
+		return res
+	}
+
+	t.Run("segments should rotate and be read back in order", func(t *testing.T) {
+		dir := t.TempDir()
+		tape, err := NewFileTape(dir, 4)
+		assert.NoError(t, err)
+		assert.NoError(t, tape.SaveTrades(ctx, trades(0, 6)))
+		assert.NoError(t, tape.SaveTrades(ctx, trades(6, 10)))
+
+		segments, err := os.ReadDir(filepath.Join(dir, "ETHBTC"))
+		assert.NoError(t, err)
+		assert.Len(t, segments, 3)
+
+		// the last segment is still open and has no gzip footer yet
+		got, err := tape.ListTrades(ctx, "ETHBTC", time.Unix(inittime, 0), time.Unix(inittime+100, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, ids(got))
+		assert.Equal(t, trades(9, 10)[0], got[9])
+
+		assert.NoError(t, tape.Close())
+	})
+
+	t.Run("trades should be filtered by time and deduplicated", func(t *testing.T) {
+		tape, err := NewFileTape(t.TempDir(), 3)
+		assert.NoError(t, err)
+		defer tape.Close()
+		assert.NoError(t, tape.SaveTrades(ctx, trades(0, 8)))
+		// trades delivered again after a reconnect
+		assert.NoError(t, tape.SaveTrades(ctx, trades(6, 10)))
+
+		got, err := tape.ListTrades(ctx, "ETHBTC", time.Unix(inittime+35, 0), time.Unix(inittime+90, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{4, 5, 6, 7, 8}, ids(got))
+
+		got, err = tape.ListTrades(ctx, "BNBBTC", time.Unix(inittime, 0), time.Unix(inittime+90, 0))
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/rickliujh/trading-chat-aggr/pkg/sql"
+	"github.com/rickliujh/trading-chat-aggr/pkg/tradingchat"
+)
+
+var _ BarStore = (*Timescale)(nil)
+
+// continuous aggregates created by the timescale migrations, keyed by their bucket width
+var timescaleAggregates = map[time.Duration]string{
+	tradingchat.Interval5M: "OHLC5M",
+	tradingchat.Interval1H: "OHLC1H",
+	tradingchat.Interval1D: "OHLC1D",
+}
+
+// Timescale is the Postgres BarStore on a TimescaleDB hypertable, longer intervals
+// are read from continuous aggregates instead of being rolled up on every query
+type Timescale struct {
+	*Postgres
+	db sql.DBTX
+}
+
+func NewTimescale(db sql.DBTX) *Timescale {
+	return &Timescale{
+		Postgres: NewPostgres(sql.New(db)),
+		db:       db,
+	}
+}
+
+// ListBars implements BarStore.
+func (t *Timescale) ListBars(ctx context.Context, symbol string, interval time.Duration, start, end time.Time) ([]tradingchat.OHLCBar, error) {
+	view, ok := timescaleAggregates[interval]
+	if !ok {
+		return t.Postgres.ListBars(ctx, symbol, interval, start, end)
+	}
+
+	// buckets opened before start are left out, same as rolled up bars
+	var startTs, endTs pgtype.Timestamp
+	if err := startTs.Scan(start.UTC()); err != nil {
+		return nil, err
+	}
+	if err := endTs.Scan(end.UTC()); err != nil {
+		return nil, err
+	}
+	rows, err := t.db.Query(ctx,
+		`SELECT h, l, o, c, ts FROM `+view+` WHERE symbol = $1 AND ts >= $2 AND ts < $3 ORDER BY ts`,
+		symbol, startTs, endTs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bars []tradingchat.OHLCBar
+	for rows.Next() {
+		var i sql.Ohlc1m
+		if err := rows.Scan(&i.H, &i.L, &i.O, &i.C, &i.Ts); err != nil {
+			return nil, err
+		}
+		bar, err := fromDBBar(i)
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, bar)
+	}
+	return bars, rows.Err()
+}
@@ -0,0 +1,203 @@
+package tradingchat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+var ErrInvalidActivitySpec = errors.New("invalid activity bar")
+
+// ActivityType is what activity bars are sampled by
+type ActivityType string
+
+const (
+	ActivityTick   ActivityType = "tick"   // every Threshold trades
+	ActivityVolume ActivityType = "volume" // every Threshold units of base volume
+	ActivityDollar ActivityType = "dollar" // every Threshold of quote notional
+	ActivityRenko  ActivityType = "renko"  // bricks of Threshold price, see RenkoCalc
+	ActivityRange  ActivityType = "range"  // bars spanning Threshold price from high to low, see RangeCalc
+)
+
+// ActivitySpec configures bars closed by trading activity rather than time
+type ActivitySpec struct {
+	Type      ActivityType `json:"type"`
+	Threshold float64      `json:"threshold"`
+}
+
+// Name identifies bars of the spec, e.g. tick_1000 or dollar_1000000
+func (s ActivitySpec) Name() string {
+	return fmt.Sprintf("%s_%s", s.Type, strconv.FormatFloat(s.Threshold, 'f', -1, 64))
+}
+
+func (s ActivitySpec) Validate() error {
+	switch s.Type {
+	case ActivityTick:
+		if s.Threshold < 1 || s.Threshold != math.Trunc(s.Threshold) {
+			return fmt.Errorf("%w: tick bars need a whole number of trades", ErrInvalidActivitySpec)
+		}
+	case ActivityVolume, ActivityDollar, ActivityRenko, ActivityRange:
+		if !(s.Threshold > 0) || math.IsInf(s.Threshold, 1) {
+			return fmt.Errorf("%w: %s bars need a positive threshold", ErrInvalidActivitySpec, s.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidActivitySpec, s.Type)
+	}
+	return nil
+}
+
+// ParseActivitySpec parses specs like volume:50
+func ParseActivitySpec(s string) (ActivitySpec, error) {
+	typ, threshold, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return ActivitySpec{}, fmt.Errorf("%w: %q isn't type:threshold", ErrInvalidActivitySpec, s)
+	}
+	v, err := strconv.ParseFloat(threshold, 64)
+	if err != nil {
+		return ActivitySpec{}, fmt.Errorf("%w: %q isn't type:threshold", ErrInvalidActivitySpec, s)
+	}
+	spec := ActivitySpec{Type: ActivityType(typ), Threshold: v}
+	return spec, spec.Validate()
+}
+
+// ActivityBar is a bar closed by trading activity, T of the embedded bar is the time of its last trade. A trade
+// moving price by several Renko bricks or range bars closes bars sharing FirstID, told apart by Seq in order
+type ActivityBar struct {
+	OHLCBar
+	OpenT    int64   `json:"open_time"` // time of the first trade
+	FirstID  int64   `json:"first_id"`
+	Seq      int64   `json:"seq,omitempty"`
+	LastID   int64   `json:"last_id"`
+	Trades   int64   `json:"trades"`
+	Volume   float64 `json:"volume"`
+	Notional float64 `json:"notional"`
+}
+
+// ActivityUpdate is sent for every trade updating an activity bar of a symbol
+type ActivityUpdate struct {
+	Symbol string
+	Spec   ActivitySpec
+	Bar    ActivityBar
+	Closed bool
+}
+
+// ActivityBuilder builds bars of a spec from trades of a symbol
+type ActivityBuilder interface {
+	// Update adds e, and returns updates of bars it closed in order followed by the bar in progress if any
+	Update(e *bconn.WsAggTradeEvent) ([]ActivityUpdate, error)
+}
+
+// checkpointedBuilder is an ActivityBuilder whose state is checkpointed
+type checkpointedBuilder interface {
+	state() ActivityState
+	restore(state ActivityState)
+}
+
+func NewActivityBuilder(spec ActivitySpec) ActivityBuilder {
+	switch spec.Type {
+	case ActivityRenko:
+		return NewRenkoCalc(spec)
+	case ActivityRange:
+		return NewRangeCalc(spec)
+	default:
+		return NewActivityCalc(spec)
+	}
+}
+
+// ActivityCalc builds tick, volume and dollar bars, the trade reaching the threshold belongs to
+// the bar it closes, trades aren't split across bars
+type ActivityCalc struct {
+	spec ActivitySpec
+	bar  ActivityBar
+	open bool
+}
+
+func NewActivityCalc(spec ActivitySpec) *ActivityCalc {
+	return &ActivityCalc{spec: spec}
+}
+
+// Update implements ActivityBuilder.
+func (c *ActivityCalc) Update(e *bconn.WsAggTradeEvent) ([]ActivityUpdate, error) {
+	price, qty, err := parseTrade(e)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.open {
+		c.bar = openActivityBar(e)
+		c.open = true
+	}
+	c.bar.add(e, price, qty)
+
+	var measure float64
+	switch c.spec.Type {
+	case ActivityTick:
+		measure = float64(c.bar.Trades)
+	case ActivityVolume:
+		measure = c.bar.Volume
+	default:
+		measure = c.bar.Notional
+	}
+	closed := measure >= c.spec.Threshold
+	if closed {
+		c.open = false
+	}
+	return []ActivityUpdate{{Symbol: e.Symbol, Spec: c.spec, Bar: c.bar, Closed: closed}}, nil
+}
+
+func (c *ActivityCalc) state() ActivityState {
+	return ActivityState{Spec: c.spec, Bar: c.bar, Open: c.open}
+}
+
+func (c *ActivityCalc) restore(state ActivityState) {
+	c.bar, c.open = state.Bar, state.Open
+}
+
+// parseTrade parses price and quantity of e, trades without quantity have none
+func parseTrade(e *bconn.WsAggTradeEvent) (price, qty float64, err error) {
+	if price, err = strconv.ParseFloat(e.Price, 64); err != nil {
+		return 0, 0, err
+	}
+	if e.Quantity != "" {
+		if qty, err = strconv.ParseFloat(e.Quantity, 64); err != nil {
+			return 0, 0, err
+		}
+	}
+	return price, qty, nil
+}
+
+// openActivityBar opens a bar with e, which still has to be added
+func openActivityBar(e *bconn.WsAggTradeEvent) ActivityBar {
+	return ActivityBar{
+		OHLCBar: OHLCBar{H: e.Price, L: e.Price, O: e.Price, C: e.Price, T: tradeUnix(e)},
+		OpenT:   tradeUnix(e),
+		FirstID: e.AggTradeID,
+	}
+}
+
+// nextSeq is Seq of a bar opened by trade id right after prev closed
+func nextSeq(prev ActivityBar, id int64) int64 {
+	if prev.FirstID == id {
+		return prev.Seq + 1
+	}
+	return 0
+}
+
+func (b *ActivityBar) add(e *bconn.WsAggTradeEvent, price, qty float64) {
+	if priceLess(b.H, e.Price) {
+		b.H = e.Price
+	}
+	if priceLess(e.Price, b.L) {
+		b.L = e.Price
+	}
+	b.C = e.Price
+	b.T = tradeUnix(e)
+	b.LastID = e.AggTradeID
+	b.Trades++
+	b.Volume += qty
+	b.Notional += price * qty
+}
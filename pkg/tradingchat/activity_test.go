@@ -0,0 +1,122 @@
+package tradingchat
+
+import (
+	"testing"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityCalc(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	trades := []*bconn.WsAggTradeEvent{
+		{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.011", Quantity: "10", TradeTime: 1737734700000},
+		{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.012", Quantity: "30", TradeTime: 1737734701000},
+		{Symbol: "BNBBTC", AggTradeID: 3, Price: "0.010", Quantity: "20", TradeTime: 1737734702000},
+		{Symbol: "BNBBTC", AggTradeID: 4, Price: "0.011", Quantity: "50", TradeTime: 1737734703000},
+	}
+	run := func(spec ActivitySpec) []ActivityBar {
+		calc := NewActivityCalc(spec)
+		var closed []ActivityBar
+		for _, e := range trades {
+			updates, err := calc.Update(e)
+			assert.NoError(t, err)
+			for _, u := range updates {
+				if u.Closed {
+					closed = append(closed, u.Bar)
+				}
+			}
+		}
+		return closed
+	}
+
+	t.Run("tick bars should close every threshold trades", func(t *testing.T) {
+		bars := run(ActivitySpec{Type: ActivityTick, Threshold: 2})
+		assert.Len(t, bars, 2)
+		assert.Equal(t, OHLCBar{H: "0.012", L: "0.011", O: "0.011", C: "0.012", T: 1737734701}, bars[0].OHLCBar)
+		assert.Equal(t, OHLCBar{H: "0.011", L: "0.010", O: "0.010", C: "0.011", T: 1737734703}, bars[1].OHLCBar)
+		assert.Equal(t, int64(1737734702), bars[1].OpenT)
+		assert.Equal(t, []int64{3, 4, 2}, []int64{bars[1].FirstID, bars[1].LastID, bars[1].Trades})
+	})
+
+	t.Run("volume bars should keep the trade reaching the threshold", func(t *testing.T) {
+		bars := run(ActivitySpec{Type: ActivityVolume, Threshold: 35})
+		assert.Len(t, bars, 2)
+		assert.Equal(t, 40.0, bars[0].Volume)
+		assert.Equal(t, 70.0, bars[1].Volume)
+	})
+
+	t.Run("dollar bars should close every threshold of notional", func(t *testing.T) {
+		bars := run(ActivitySpec{Type: ActivityDollar, Threshold: 0.5})
+		assert.Len(t, bars, 2)
+		assert.InDelta(t, 0.11+0.36+0.2, bars[0].Notional, 1e-9)
+		assert.Equal(t, int64(3), bars[0].Trades)
+		assert.InDelta(t, 0.55, bars[1].Notional, 1e-9)
+	})
+
+	t.Run("specs should be parsed and validated", func(t *testing.T) {
+		spec, err := ParseActivitySpec("dollar:1000000")
+		assert.NoError(t, err)
+		assert.Equal(t, ActivitySpec{Type: ActivityDollar, Threshold: 1e6}, spec)
+		assert.Equal(t, "dollar_1000000", spec.Name())
+
+		for _, s := range []string{"tick:1.5", "volume:0", "volume", "kagi:10", "tick:x"} {
+			_, err := ParseActivitySpec(s)
+			assert.ErrorIs(t, err, ErrInvalidActivitySpec, s)
+		}
+	})
+}
+
+func TestAggrActivityBars(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+
+	t.Run("activity bars should share the trades of the aggregation", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		tick := ActivitySpec{Type: ActivityTick, Threshold: 2}
+		volume := ActivitySpec{Type: ActivityVolume, Threshold: 100}
+		ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{ActivityBars: []ActivitySpec{tick}})
+
+		assert.ErrorIs(t, ag.AddActivityBars("NOEXIST", volume), ErrNotSymbolRegistered)
+		assert.NoError(t, ag.AddActivityBars("BNBBTC", volume))
+		go func() {
+			defer close(stream)
+			for i := int64(1); i <= 4; i++ {
+				stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: i, Price: "0.011", Quantity: "30", TradeTime: (1737734700 + i) * 1000}
+			}
+		}()
+		go func() {
+			for range updateCh {
+			}
+		}()
+
+		closed := map[string]int{}
+		updates := 0
+		for u := range ag.ActivityBars() {
+			updates++
+			if u.Closed {
+				closed[u.Spec.Name()]++
+			}
+		}
+		assert.Equal(t, 8, updates)
+		assert.Equal(t, map[string]int{"tick_2": 2, "volume_100": 1}, closed)
+	})
+
+	t.Run("removed activity bars should stop unless built for every symbol", func(t *testing.T) {
+		tick := ActivitySpec{Type: ActivityTick, Threshold: 2}
+		ag := newAggr(logger, []string{"BNBBTC"}, 1, []ActivitySpec{tick})
+		volume := ActivitySpec{Type: ActivityVolume, Threshold: 100}
+		ag.AddActivityBars("BNBBTC", volume)
+		ag.AddActivityBars("BNBBTC", volume)
+		ag.AddActivityBars("BNBBTC", tick)
+
+		ag.RemoveActivityBars("BNBBTC", volume)
+		ag.RemoveActivityBars("BNBBTC", tick)
+		sa, _ := ag.lookup("BNBBTC")
+		assert.Len(t, sa.activity, 2)
+		ag.RemoveActivityBars("BNBBTC", volume)
+		assert.Len(t, sa.activity, 1)
+	})
+}
@@ -1,12 +1,19 @@
 package tradingchat
 
 import (
+	"context"
 	"errors"
+	"hash/fnv"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	bconn "github.com/binance/binance-connector-go"
 	"github.com/go-logr/logr"
-	"github.com/rickliujh/trading-chat-aggr/pkg/utils"
 )
 
 var (
@@ -14,49 +21,744 @@ var (
 	ErrNotSymbolRegistered = errors.New("symbol are not registered")
 )
 
-type Aggr map[string]*OHLCCalc
+const (
+	// time a recovery waits for missing trades of a gap
+	recoveryTimeout = 10 * time.Second
+	// larger gaps, e.g. after a long downtime, are left to backfill
+	maxRecoveryTrades = 10000
+	// trades queued for a shard before the stream is held back
+	shardQueueSize = 500
+)
+
+// Aggr holds the calculator of every registered symbol, symbols can be added and removed while aggregating.
+// Symbols are hashed onto shards, each shard is a goroutine that owns the calculators of its symbols and
+// aggregates their trades in order. It publishes a copy of the state of a symbol after every change,
+// so readers always get a consistent bar without waiting for the aggregation
+type Aggr struct {
+	logger     logr.Logger
+	rw         *sync.RWMutex // guards the symbols map, not the calculators
+	symbols    map[string]*symbolAggr
+	shards     []chan *bconn.WsAggTradeEvent
+	recoveries []chan recovery // trades of gaps recovered in the background, by shard
+	pinned     []ActivitySpec  // activity bars built for every symbol
+	activityCh chan ActivityUpdate
+	// derived instruments by symbols of their legs, only used by the goroutine dispatching trades
+	derived map[string][]derived
+	// registered derived instruments by symbols of their legs, guarded by rw
+	legs map[string][]string
+
+	monitor  *FeedMonitor // watches the trade stream if it's not nil
+	statusCh chan FeedStatus
+}
+
+type symbolAggr struct {
+	shard   int
+	calc    *OHLCCalc
+	ids     *tradeIDs
+	volume  float64    // base volume of the bar in progress
+	missing int        // gaps of the bar in progress not recovered yet
+	recent  RecentBars // closed bars of every history interval
+	state   atomic.Pointer[SymbolState]
+
+	mu       *sync.Mutex // guards activity, builders are added by other goroutines
+	activity map[ActivitySpec]*activityBuilder
+	pending  []ActivityUpdate // updates of activity bars not sent yet
+}
+
+type activityBuilder struct {
+	calc   ActivityBuilder
+	refs   int
+	pinned bool
+}
+
+// publish makes the state of the symbol visible to readers
+func (sa *symbolAggr) publish() {
+	sa.mu.Lock()
+	activity := sa.activityStates()
+	sa.mu.Unlock()
+	sa.state.Store(&SymbolState{
+		Bar:      sa.calc.bar,
+		EndedAt:  sa.calc.endedAt,
+		LastID:   sa.ids.lastID,
+		LastTime: sa.ids.lastTime,
+		Volume:   sa.volume,
+		Recent:   sa.recent,
+		Activity: activity,
+	})
+}
+
+// apply aggregates e, updates of activity bars are queued in pending
+func (sa *symbolAggr) apply(e *bconn.WsAggTradeEvent) {
+	endedAt, prev := sa.calc.endedAt, sa.calc.bar
+	sa.calc.update(e)
+	if sa.calc.endedAt != endedAt {
+		if prev.T > 0 {
+			sa.recent = sa.recent.push(prev)
+		}
+		sa.volume = 0
+		sa.missing = 0
+	}
+	if qty, err := strconv.ParseFloat(e.Quantity, 64); err == nil {
+		sa.volume += qty
+	}
+	sa.ids.track(e)
+
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	for spec, b := range sa.activity {
+		updates, err := b.calc.Update(e)
+		if err != nil {
+			sa.calc.logger.Error(err, "unable to update activity bar", "spec", spec, "event", e)
+			continue
+		}
+		sa.pending = append(sa.pending, updates...)
+	}
+}
+
+// markGap marks the bar in progress incomplete if missing trades of gap may have traded within it
+func (sa *symbolAggr) markGap(gap Gap) bool {
+	bar := sa.calc.Bar()
+	if bar.T == 0 || gap.Start > sa.calc.endedAt || gap.End < bar.OpenTime().Unix() {
+		return false
+	}
+	sa.calc.markIncomplete()
+	sa.missing++
+	return true
+}
+
+func newAggr(logger logr.Logger, symbols []string, shards int, pinned []ActivitySpec) *Aggr {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	ag := &Aggr{
+		logger:     logger,
+		rw:         &sync.RWMutex{},
+		symbols:    map[string]*symbolAggr{},
+		shards:     make([]chan *bconn.WsAggTradeEvent, shards),
+		recoveries: make([]chan recovery, shards),
+		pinned:     pinned,
+		activityCh: make(chan ActivityUpdate, 500),
+		derived:    map[string][]derived{},
+		legs:       map[string][]string{},
+		statusCh:   make(chan FeedStatus, 100),
+	}
+	for i := range ag.shards {
+		ag.shards[i] = make(chan *bconn.WsAggTradeEvent, shardQueueSize)
+		ag.recoveries[i] = make(chan recovery)
+	}
+	ag.AddSymbols(symbols)
+	return ag
+}
+
+// derived is an instrument whose trades are derived from trades of other registered symbols, its legs
+type derived interface {
+	symbol() string
+	legs() []string
+	// trade returns the trade of the instrument e makes if any
+	trade(e *bconn.WsAggTradeEvent) (*bconn.WsAggTradeEvent, bool)
+	// resume continues trade ids after lastID
+	resume(lastID int64)
+	// state returns the latest trades of legs to be checkpointed, restore loads them back
+	state() DerivedState
+	restore(state DerivedState) error
+}
+
+// addDerived registers synthetic instruments and composite indexes of opts whose legs are registered
+func (ag *Aggr) addDerived(logger logr.Logger, opts AggrOptions) []derived {
+	var candidates []derived
+	for _, spec := range opts.Synthetics {
+		if err := spec.Validate(); err != nil {
+			logger.Error(err, "synthetic instrument ignored", "spec", spec)
+			continue
+		}
+		candidates = append(candidates, newSynthetic(spec))
+	}
+	for _, spec := range opts.Composites {
+		if err := spec.Validate(); err != nil {
+			logger.Error(err, "composite index ignored", "spec", spec)
+			continue
+		}
+		candidates = append(candidates, newComposite(spec))
+	}
+
+	var added []derived
+	for _, d := range candidates {
+		legs := d.legs()
+		if slices.ContainsFunc(legs, func(symbol string) bool { return !ag.Has(symbol) }) {
+			logger.Error(ErrNotSymbolRegistered, "derived instrument ignored", "symbol", d.symbol(), "legs", legs)
+			continue
+		}
+		if len(ag.AddSymbols([]string{d.symbol()})) == 0 {
+			logger.Error(ErrNotHanlderFound, "derived instrument shadows a symbol", "symbol", d.symbol())
+			continue
+		}
+		ag.rw.Lock()
+		for _, symbol := range legs {
+			ag.derived[symbol] = append(ag.derived[symbol], d)
+			ag.legs[symbol] = append(ag.legs[symbol], d.symbol())
+		}
+		ag.rw.Unlock()
+		added = append(added, d)
+	}
+	return added
+}
+
+// shardOf hashes symbol onto a shard, trades of a symbol always go to the same shard
+func (ag *Aggr) shardOf(symbol string) int {
+	h := fnv.New32a()
+	h.Write([]byte(symbol))
+	return int(h.Sum32() % uint32(len(ag.shards)))
+}
+
+// AggrOptions are optional behaviours of the aggregation
+type AggrOptions struct {
+	// Recoverer fetches missing trades of gaps if it's not nil
+	Recoverer GapRecoverer
+	// Seeds are bars in progress of symbols restored from an earlier run
+	Seeds map[string]OHLCBar
+	// Restore is the checkpoint of an earlier run, it takes precedence over Seeds
+	Restore *Checkpoint
+	// Checkpoints saves the state of the aggregation every CheckpointEvery, and once more when it stops
+	Checkpoints     CheckpointStore
+	CheckpointEvery time.Duration
+	// Shards is the number of goroutines aggregating trades, GOMAXPROCS if it's 0
+	Shards int
+	// Clock drives bar closing and checkpoints, the wall clock if it's nil
+	Clock Clock
+	// ActivityBars are built for every symbol, on top of the ones added by AddActivityBars
+	ActivityBars []ActivitySpec
+	// Synthetics are aggregated like symbols from trades of their legs, which have to be among symbols
+	Synthetics []SyntheticSpec
+	// Composites are aggregated like symbols from trades of their members, which have to be among symbols
+	Composites []CompositeSpec
+	// Monitor watches liveness and price spikes of the trade stream if it's not nil, see FeedStatuses
+	Monitor *FeedMonitor
+}
 
-func NewAggrStream(logger logr.Logger, done <-chan struct{}, eventStream <-chan *bconn.WsAggTradeEvent, symbols []string) (Aggr, <-chan string) {
-	dict := Aggr{}
+func NewAggrStream(logger logr.Logger, done <-chan struct{}, eventStream <-chan *bconn.WsAggTradeEvent, symbols []string) (*Aggr, <-chan string) {
+	aggr, updateCh, _ := NewAggrStreamWithOptions(logger, done, eventStream, symbols, AggrOptions{})
+	return aggr, updateCh
+}
+
+// NewAggrStreamWithOptions aggregates like NewAggrStream, and reports gaps of aggregated trade ids of every symbol,
+// bars the missing trades may have traded within are marked incomplete. Missing trades are recovered in the background,
+// a gap is reported once its recovery ended, and the bar in progress is complete again if every missing trade was
+// recovered into it. Duplicated trades are dropped
+func NewAggrStreamWithOptions(logger logr.Logger, done <-chan struct{}, eventStream <-chan *bconn.WsAggTradeEvent, symbols []string, opts AggrOptions) (*Aggr, <-chan string, <-chan Gap) {
+	for _, spec := range opts.ActivityBars {
+		if err := spec.Validate(); err != nil {
+			logger.Error(err, "activity bars ignored", "spec", spec)
+			opts.ActivityBars = nil
+			break
+		}
+	}
+	ag := newAggr(logger, symbols, opts.Shards, opts.ActivityBars)
+	derived := ag.addDerived(logger, opts)
+	ag.monitor = opts.Monitor
+	clock := orSystemClock(opts.Clock)
 	updateCh := make(chan string, 500)
-	for _, symbol := range symbols {
-		dict[symbol] = NewOHLCCalc(logger.WithName(symbol))
+	gapCh := make(chan Gap, 100)
+	for symbol, bar := range opts.Seeds {
+		if sa, ok := ag.lookup(symbol); ok {
+			logger.Info("bar in progress restored", "symbol", symbol, "bar", bar)
+			sa.calc.seed(bar)
+			sa.publish()
+		}
+	}
+	restore(logger, ag, derived, opts.Restore, clock.Now())
+	for _, d := range derived {
+		if sa, ok := ag.lookup(d.symbol()); ok {
+			d.resume(sa.state.Load().LastID)
+		}
+	}
+	// trades of legs are deduplicated before deriving as well, duplicates would derive trades again before shards drop them
+	legIDs := map[string]*tradeIDs{}
+	for leg := range ag.derived {
+		if sa, ok := ag.lookup(leg); ok {
+			ids := *sa.ids
+			legIDs[leg] = &ids
+		}
+	}
+
+	// tickers are created before any goroutine starts, so a fake clock advanced right after returning hits them
+	var checkpoints *checkpointer
+	var checkpointTicker Ticker
+	if opts.Checkpoints != nil {
+		checkpoints = newCheckpointer(logger.WithName("checkpoint"), opts.Checkpoints)
+		if opts.CheckpointEvery > 0 {
+			checkpointTicker = clock.NewTicker(opts.CheckpointEvery)
+		}
+	}
+
+	var monitorTicker Ticker
+	if ag.monitor != nil {
+		monitorTicker = clock.NewTicker(ag.monitor.opts.CheckEvery)
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := range ag.shards {
+		minute := clock.NewTicker(time.Minute)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer minute.Stop()
+			ag.aggregate(logger.WithValues("shard", i), done, i, minute, opts.Recoverer, updateCh, gapCh)
+		}()
 	}
 
+	// dispatches trades to shards
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			for _, queue := range ag.shards {
+				close(queue)
+			}
+		}()
+
+		dispatch := func(e *bconn.WsAggTradeEvent) bool {
+			queue := ag.shards[ag.shardOf(e.Symbol)]
+			if len(queue) == cap(queue) {
+				logger.V(1).Info("shard queue is full", "symbol", e.Symbol, "depths", ag.QueueDepths())
+			}
+			select {
+			case <-done:
+				return false
+			case queue <- e:
+				return true
+			}
+		}
+
+		var checkpointCh, monitorCh <-chan time.Time
+		if checkpointTicker != nil {
+			defer checkpointTicker.Stop()
+			checkpointCh = checkpointTicker.C()
+		}
+		if monitorTicker != nil {
+			defer monitorTicker.Stop()
+			monitorCh = monitorTicker.C()
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case tick := <-checkpointCh:
+				checkpoints.save(snapshot(ag, derived, tick))
+			case tick := <-monitorCh:
+				ag.report(logger, ag.monitor.Check(tick))
+			case e, ok := <-eventStream:
+				if !ok {
+					return
+				}
+				if ag.monitor != nil {
+					ag.report(logger, ag.monitor.Observe(e, clock.Now()))
+				}
+				if !dispatch(e) {
+					return
+				}
+				ids, isLeg := legIDs[e.Symbol]
+				if !isLeg || ids.seen(e) {
+					continue
+				}
+				ids.track(e)
+				for _, d := range ag.derived[e.Symbol] {
+					if !ag.derives(e.Symbol, d.symbol()) {
+						continue
+					}
+					if t, ok := d.trade(e); ok && !dispatch(t) {
+						return
+					}
+				}
+			}
+		}
+	}()
+
 	go func() {
-		defer close(updateCh)
-		for e := range utils.OrDone(done, eventStream) {
-			logger.V(4).Info("aggregator received new event", "event", e)
+		wg.Wait()
+		if checkpoints != nil {
+			checkpoints.close(snapshot(ag, derived, clock.Now()))
+		}
+		close(updateCh)
+		close(gapCh)
+		close(ag.activityCh)
+		close(ag.statusCh)
+	}()
+
+	return ag, updateCh, gapCh
+}
+
+// recovery is the outcome of recovering the missing trades of gap
+type recovery struct {
+	gap    Gap
+	trades []*bconn.WsAggTradeEvent
+}
 
-			calc, ok := dict[e.Symbol]
+// aggregate is the loop of shard, it returns once done is closed or the queue of shard is drained after the stream ended
+// and every recovery it started has ended
+func (ag *Aggr) aggregate(logger logr.Logger, done <-chan struct{}, shard int, minute Ticker, recoverer GapRecoverer, updateCh chan<- string, gapCh chan<- Gap) {
+	queue := ag.shards[shard]
+	recovering := 0
+	reportGap := func(gap Gap) {
+		logger.Info("gap in trades detected", "gap", gap)
+		select {
+		case gapCh <- gap:
+		default:
+			logger.V(2).Info("gap channel is full, gap dropped", "gap", gap)
+		}
+	}
+	// sends pending updates of activity bars of sa, it returns false once done is closed
+	flush := func(sa *symbolAggr) bool {
+		for _, u := range sa.pending {
+			select {
+			case ag.activityCh <- u:
+			case <-done:
+				return false
+			}
+		}
+		sa.pending = sa.pending[:0]
+		return true
+	}
+
+	for {
+		var e *bconn.WsAggTradeEvent
+		select {
+		case <-done:
+			return
+		case tick := <-minute.C():
+			ag.rw.RLock()
+			for symbol, sa := range ag.symbols {
+				if sa.shard == shard && sa.calc.closedWithin(tick.Unix(), time.Minute) {
+					logger.V(2).Info("bar closed", "symbol", symbol, "bar", sa.calc.Bar())
+				}
+			}
+			ag.rw.RUnlock()
+			continue
+		case r := <-ag.recoveries[shard]:
+			recovering--
+			if sa, ok := ag.lookup(r.gap.Symbol); ok {
+				gap := recoverGap(sa, r)
+				sa.publish()
+				updateCh <- gap.Symbol
+				if !flush(sa) {
+					return
+				}
+				reportGap(gap)
+			}
+			if queue == nil && recovering == 0 {
+				return
+			}
+			continue
+		case ev, ok := <-queue:
 			if !ok {
-				logger.V(2).Error(ErrNotHanlderFound, "unsupported symbol", "symbol", e.Symbol, "event", e)
+				if recovering == 0 {
+					return
+				}
+				queue = nil
 				continue
 			}
+			e = ev
+		}
+		logger.V(4).Info("aggregator received new event", "event", e)
+
+		sa, ok := ag.lookup(e.Symbol)
+		if !ok {
+			logger.V(2).Error(ErrNotHanlderFound, "unsupported symbol", "symbol", e.Symbol, "event", e)
+			continue
+		}
+
+		calc, tracked := sa.calc, sa.ids
+		if tracked.seen(e) {
+			logger.V(2).Info("duplicated trade dropped", "symbol", e.Symbol, "id", e.AggTradeID)
+			continue
+		}
 
-			calc.update(e)
+		gap, hasGap := tracked.gap(e)
+		if hasGap && sa.markGap(gap) && tradeUnix(e) > calc.endedAt {
+			// e opens the next bar, readers get the one the gap started in marked before it's gone
+			sa.publish()
 			updateCh <- e.Symbol
 		}
-	}()
+		endedAt := calc.endedAt
+		sa.apply(e)
+		if hasGap && calc.endedAt != endedAt {
+			sa.markGap(gap)
+		}
+		sa.publish()
+		updateCh <- e.Symbol
+		if !flush(sa) {
+			return
+		}
 
-	go func() {
-		ticker := time.NewTicker(time.Minute)
-		defer ticker.Stop()
-		for tick := range utils.OrDone(done, ticker.C) {
-			for _, calc := range dict {
-				calc.tick(tick.Unix())
+		if hasGap {
+			if ag.recover(logger, done, shard, recoverer, gap) {
+				recovering++
+			} else {
+				reportGap(gap)
 			}
 		}
+	}
+}
+
+// recover fetches missing trades of gap from recoverer in the background and hands them to shard,
+// it returns false if the gap is left unrecovered
+func (ag *Aggr) recover(logger logr.Logger, done <-chan struct{}, shard int, recoverer GapRecoverer, gap Gap) bool {
+	if recoverer == nil {
+		return false
+	}
+	if gap.ToID-gap.FromID+1 > maxRecoveryTrades {
+		logger.Info("gap too large to recover", "gap", gap)
+		return false
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), recoveryTimeout)
+		trades, err := recoverer.Recover(ctx, gap)
+		cancel()
+		if err != nil {
+			logger.Error(err, "unable to recover gap", "gap", gap, "recovered", len(trades))
+		}
+		select {
+		case ag.recoveries[shard] <- recovery{gap: gap, trades: trades}:
+		case <-done:
+		}
 	}()
+	return true
+}
 
-	return dict, updateCh
+// recoverGap aggregates missing trades of r into the bar in progress, trades of minutes older than it can't be
+// recovered as their bars are closed. The gap is recovered if every missing trade was, and they all belong to it
+func recoverGap(sa *symbolAggr, r recovery) Gap {
+	gap, trades := r.gap, r.trades
+	sort.Slice(trades, func(i, j int) bool { return trades[i].AggTradeID < trades[j].AggTradeID })
+	open := sa.calc.Bar().OpenTime().Unix()
+	recovered, last := int64(0), int64(0)
+	for _, t := range trades {
+		// later trades were aggregated already, so ids of the gap are told apart from duplicates of the page here
+		if t.AggTradeID < gap.FromID || t.AggTradeID > gap.ToID || t.AggTradeID == last || tradeUnix(t) < open {
+			continue
+		}
+		last = t.AggTradeID
+		sa.apply(t)
+		recovered++
+	}
+	gap.Recovered = gap.Start >= open && recovered == gap.ToID-gap.FromID+1
+	if gap.Recovered {
+		sa.missing--
+		if sa.missing <= 0 {
+			sa.calc.bar.Incomplete = false
+		}
+	}
+	return gap
 }
 
-func (ag Aggr) OHLCBar(symbol string) (OHLCBar, error) {
-	calc, ok := ag[symbol]
+// OHLCBar returns a copy of the latest bar in progress of symbol, safe to call from any goroutine
+func (ag *Aggr) OHLCBar(symbol string) (OHLCBar, error) {
+	sa, ok := ag.lookup(symbol)
 	if !ok {
 		return OHLCBar{}, ErrNotSymbolRegistered
 	}
-	return calc.Bar(), nil
+	return sa.state.Load().Bar, nil
+}
+
+// State returns a copy of the latest state of symbol, safe to call from any goroutine
+func (ag *Aggr) State(symbol string) (SymbolState, error) {
+	sa, ok := ag.lookup(symbol)
+	if !ok {
+		return SymbolState{}, ErrNotSymbolRegistered
+	}
+	return *sa.state.Load(), nil
+}
+
+// RecentBars returns the latest bars of symbol at interval followed by the one in progress, safe to call from any
+// goroutine. Intervals other than history intervals are rolled up from the longest one dividing them
+func (ag *Aggr) RecentBars(symbol string, interval time.Duration) ([]OHLCBar, error) {
+	sa, ok := ag.lookup(symbol)
+	if !ok {
+		return nil, ErrNotSymbolRegistered
+	}
+	if interval < Interval1M || interval%Interval1M != 0 {
+		return nil, ErrInvalidInterval
+	}
+	base := Interval1M
+	for _, i := range recentIntervals {
+		if interval%i == 0 {
+			base = i
+		}
+	}
+	state := sa.state.Load()
+	bars := state.Recent[shortDuration(base)]
+	if state.Bar.T > 0 {
+		bars = rollInto(bars, state.Bar, base)
+	}
+	if base == interval {
+		return slices.Clone(bars), nil
+	}
+	res := Rollup(bars, interval)
+	for i := range res {
+		res[i].T = time.Unix(res[i].T, 0).Truncate(interval).Unix()
+	}
+	return res, nil
 }
 
+// QueueDepths returns the number of trades waiting in the queue of every shard
+func (ag *Aggr) QueueDepths() []int {
+	depths := make([]int, len(ag.shards))
+	for i, queue := range ag.shards {
+		depths[i] = len(queue)
+	}
+	return depths
+}
+
+// AddSymbols starts aggregating trades of symbols, and returns symbols that weren't registered yet
+func (ag *Aggr) AddSymbols(symbols []string) []string {
+	ag.rw.Lock()
+	defer ag.rw.Unlock()
+	var added []string
+	for _, symbol := range symbols {
+		if _, ok := ag.symbols[symbol]; ok {
+			continue
+		}
+		sa := &symbolAggr{
+			shard:    ag.shardOf(symbol),
+			calc:     NewOHLCCalc(ag.logger.WithName(symbol)),
+			ids:      &tradeIDs{},
+			mu:       &sync.Mutex{},
+			activity: map[ActivitySpec]*activityBuilder{},
+		}
+		for _, spec := range ag.pinned {
+			sa.activity[spec] = &activityBuilder{calc: NewActivityBuilder(spec), pinned: true}
+		}
+		sa.publish()
+		ag.symbols[symbol] = sa
+		added = append(added, symbol)
+	}
+	return added
+}
+
+// RemoveSymbols stops aggregating trades of symbols and drops their bars in progress, and returns symbols
+// that were registered. Legs of registered derived instruments are kept, they have to be removed first
+func (ag *Aggr) RemoveSymbols(symbols []string) []string {
+	ag.rw.Lock()
+	defer ag.rw.Unlock()
+	var removed []string
+	for _, symbol := range symbols {
+		if _, ok := ag.symbols[symbol]; !ok || len(ag.legs[symbol]) > 0 {
+			continue
+		}
+		delete(ag.symbols, symbol)
+		// legs stop deriving trades of a removed derived instrument
+		for leg, derived := range ag.legs {
+			if derived = slices.DeleteFunc(derived, func(s string) bool { return s == symbol }); len(derived) == 0 {
+				delete(ag.legs, leg)
+			} else {
+				ag.legs[leg] = derived
+			}
+		}
+		if ag.monitor != nil {
+			ag.monitor.Forget(symbol)
+		}
+		removed = append(removed, symbol)
+	}
+	return removed
+}
+
+// IsLeg tells if symbol is a leg of a registered derived instrument
+func (ag *Aggr) IsLeg(symbol string) bool {
+	ag.rw.RLock()
+	defer ag.rw.RUnlock()
+	return len(ag.legs[symbol]) > 0
+}
+
+// derives tells if trades of leg still derive trades of symbol
+func (ag *Aggr) derives(leg, symbol string) bool {
+	ag.rw.RLock()
+	defer ag.rw.RUnlock()
+	return slices.Contains(ag.legs[leg], symbol)
+}
+
+// FeedStatuses streams changes of the health of the feed found by the monitor of the options, statuses are dropped
+// unless it's drained, and it's closed once the aggregation stops
+func (ag *Aggr) FeedStatuses() <-chan FeedStatus {
+	return ag.statusCh
+}
+
+// report logs statuses and sends them to FeedStatuses
+func (ag *Aggr) report(logger logr.Logger, statuses []FeedStatus) {
+	for _, st := range statuses {
+		logger.Info("feed status changed", "symbol", st.Symbol, "status", st.Kind, "last_trade", st.LastTrade, "silence", st.Silence, "expected", st.Expected, "price", st.Price, "deviation", st.Deviation)
+		select {
+		case ag.statusCh <- st:
+		default:
+			logger.V(2).Info("status channel is full, status dropped", "status", st)
+		}
+	}
+}
+
+// ActivityBars streams updates of activity bars of every symbol, it has to be drained when activity bars
+// are built, and it's closed once the aggregation stops
+func (ag *Aggr) ActivityBars() <-chan ActivityUpdate {
+	return ag.activityCh
+}
+
+// AddActivityBars starts building bars of spec from trades of symbol, builders of the same spec are shared
+// and stop once every caller removed them
+func (ag *Aggr) AddActivityBars(symbol string, spec ActivitySpec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	sa, ok := ag.lookup(symbol)
+	if !ok {
+		return ErrNotSymbolRegistered
+	}
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	b, ok := sa.activity[spec]
+	if !ok {
+		b = &activityBuilder{calc: NewActivityBuilder(spec)}
+		sa.activity[spec] = b
+	}
+	b.refs++
+	return nil
+}
+
+// RemoveActivityBars undoes an AddActivityBars
+func (ag *Aggr) RemoveActivityBars(symbol string, spec ActivitySpec) {
+	sa, ok := ag.lookup(symbol)
+	if !ok {
+		return
+	}
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	b, ok := sa.activity[spec]
+	if !ok {
+		return
+	}
+	b.refs--
+	if b.refs <= 0 && !b.pinned {
+		delete(sa.activity, spec)
+	}
+}
+
+// Symbols lists registered symbols in order
+func (ag *Aggr) Symbols() []string {
+	ag.rw.RLock()
+	defer ag.rw.RUnlock()
+	symbols := make([]string, 0, len(ag.symbols))
+	for symbol := range ag.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// Has tells if symbol is registered
+func (ag *Aggr) Has(symbol string) bool {
+	_, ok := ag.lookup(symbol)
+	return ok
+}
+
+func (ag *Aggr) lookup(symbol string) (*symbolAggr, bool) {
+	ag.rw.RLock()
+	defer ag.rw.RUnlock()
+	sa, ok := ag.symbols[symbol]
+	return sa, ok
+}
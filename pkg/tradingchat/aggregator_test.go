@@ -1,9 +1,13 @@
 package tradingchat
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
 	"github.com/go-logr/logr/testr"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,12 +20,12 @@ func TestAggr(t *testing.T) {
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11111",
-				TradeTime: 1737734701,
+				TradeTime: 1737734701000,
 			},
 			{
 				Symbol:    "ETHBTC",
 				Price:     "0.11121",
-				TradeTime: 1737734711,
+				TradeTime: 1737734711000,
 			},
 		}
 		symbols := []string{}
@@ -53,7 +57,8 @@ func TestAggr(t *testing.T) {
 
 		<-done
 
-		assert.Equal(t, symbols, res, "symbols of events should be notified in updateCh")
+		// symbols on different shards are notified in any order
+		assert.ElementsMatch(t, symbols, res, "symbols of events should be notified in updateCh")
 
 		bar, err := ag.OHLCBar("ETHBTC")
 		assert.NoError(t, err, "should not throw error for existing symbol")
@@ -72,3 +77,283 @@ func TestAggr(t *testing.T) {
 		assert.ErrorIs(t, err, ErrNotSymbolRegistered, "should throw error when symbol not existed")
 	})
 }
+
+func TestAggrSeeds(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+
+	t.Run("restored bar should be continued by trades of the same minute", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		go func() {
+			defer close(stream)
+			// 16:05 on Jan 24th 2025
+			stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.11115", TradeTime: 1737734730000}
+		}()
+
+		ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{
+			Seeds: map[string]OHLCBar{
+				"BNBBTC": {H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11104", T: 1737734720},
+			},
+		})
+		for range updateCh {
+		}
+
+		bar, err := ag.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11115", T: 1737734730}, bar)
+	})
+}
+
+func TestAggrRollover(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	// 16:05 on Jan 24th 2025
+	var inittime int64 = 1737734700
+
+	t.Run("trades after the minute closed should open a new bar", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{Shards: 1})
+
+		// bars roll over by trade time, whatever the clock says
+		stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.11111", TradeTime: (inittime + 30) * 1000}
+		<-updateCh
+		stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.11121", TradeTime: (inittime + 70) * 1000}
+		<-updateCh
+		bar, err := ag.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11121", L: "0.11121", O: "0.11121", C: "0.11121", T: inittime + 70}, bar)
+	})
+
+	// the bar in progress of a checkpoint is continued only while the clock is in its minute
+	checkpoint := &Checkpoint{Version: CheckpointVersion, TakenAt: inittime + 30, Symbols: map[string]SymbolState{
+		"BNBBTC": {Bar: OHLCBar{H: "0.11131", L: "0.11101", O: "0.11111", C: "0.11121", T: inittime + 30}, EndedAt: inittime + 59, LastID: 1, LastTime: (inittime + 30) * 1000},
+	}}
+	restart := func(clock *FakeClock) OHLCBar {
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{Shards: 1, Clock: clock, Restore: checkpoint})
+		stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11141", TradeTime: (inittime + 40) * 1000}
+		<-updateCh
+		bar, err := ag.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		return bar
+	}
+
+	t.Run("restart within the minute should continue the bar", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(inittime+35, 0))
+		assert.Equal(t, OHLCBar{H: "0.11141", L: "0.11101", O: "0.11111", C: "0.11141", T: inittime + 40}, restart(clock))
+	})
+
+	t.Run("restart after the minute closed should open a new bar", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(inittime+35, 0))
+		clock.Advance(time.Minute)
+		assert.Equal(t, OHLCBar{H: "0.11141", L: "0.11141", O: "0.11141", C: "0.11141", T: inittime + 40}, restart(clock))
+	})
+}
+
+func TestAggrSymbols(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+
+	t.Run("symbols should be added and removed while aggregating", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		ag, updateCh := NewAggrStream(logger, done, stream, []string{"BNBBTC"})
+
+		assert.Equal(t, []string{"ETHBTC"}, ag.AddSymbols([]string{"ETHBTC", "BNBBTC"}))
+		assert.Equal(t, []string{"BNBBTC", "ETHBTC"}, ag.Symbols())
+
+		stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", Price: "0.03", TradeTime: 1737734701000}
+		assert.Equal(t, "ETHBTC", <-updateCh)
+		bar, err := ag.OHLCBar("ETHBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, "0.03", bar.O)
+
+		assert.Equal(t, []string{"ETHBTC"}, ag.RemoveSymbols([]string{"ETHBTC", "NOEXIST"}))
+		assert.False(t, ag.Has("ETHBTC"))
+		_, err = ag.OHLCBar("ETHBTC")
+		assert.ErrorIs(t, err, ErrNotSymbolRegistered)
+
+		// trades of removed symbols are ignored
+		stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", Price: "0.04", TradeTime: 1737734702000}
+		stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.11", TradeTime: 1737734702000}
+		assert.Equal(t, "BNBBTC", <-updateCh)
+	})
+}
+
+// run with -race, as `make test` does
+func TestAggrConcurrentReads(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 0})
+	symbols := []string{"BNBBTC", "ETHBTC", "LTCBTC", "XRPBTC"}
+	const trades = 2000
+
+	done := make(chan struct{})
+	stream := make(chan *bconn.WsAggTradeEvent)
+	ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, symbols, AggrOptions{
+		Checkpoints:     &memCheckpoints{},
+		CheckpointEvery: time.Millisecond,
+	})
+
+	go func() {
+		defer close(stream)
+		// 16:05 on Jan 24th 2025, prices zigzag
+		for i := 0; i < trades; i++ {
+			stream <- &bconn.WsAggTradeEvent{
+				Symbol:     symbols[i%len(symbols)],
+				AggTradeID: int64(i/len(symbols) + 1),
+				Price:      fmt.Sprintf("0.1%04d", (i*7919)%10000),
+				TradeTime:  (1737734700 + int64(i/100)) * 1000,
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var lastT int64
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				for _, symbol := range symbols {
+					bar, err := ag.OHLCBar(symbol)
+					if err != nil {
+						errs <- err
+						return
+					}
+					if bar.T == 0 {
+						continue
+					}
+					if bar.O < bar.L || bar.H < bar.C || bar.H < bar.L {
+						errs <- fmt.Errorf("inconsistent bar %+v", bar)
+						return
+					}
+					if symbol == symbols[0] {
+						if bar.T < lastT {
+							errs <- fmt.Errorf("bar went back in time %d < %d", bar.T, lastT)
+							return
+						}
+						lastT = bar.T
+					}
+				}
+				ag.Has("DOGEBTC")
+				ag.Symbols()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				ag.AddSymbols([]string{"DOGEBTC"})
+				ag.RemoveSymbols([]string{"DOGEBTC"})
+			}
+		}
+	}()
+
+	updates := 0
+	for range updateCh {
+		updates++
+		if updates == trades {
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	assert.Equal(t, trades, updates)
+}
+
+func TestAggrShards(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 0})
+
+	t.Run("trades of a symbol should be aggregated in order across shards", func(t *testing.T) {
+		symbols := []string{"BNBBTC", "ETHBTC", "LTCBTC", "XRPBTC", "ADABTC", "DOGEBTC"}
+		const perSymbol = 500
+
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		go func() {
+			defer close(stream)
+			for i := 1; i <= perSymbol; i++ {
+				for _, symbol := range symbols {
+					stream <- &bconn.WsAggTradeEvent{
+						Symbol:     symbol,
+						AggTradeID: int64(i),
+						Price:      fmt.Sprintf("0.1%04d", i),
+						TradeTime:  (1737734700 + int64(i/10)) * 1000,
+					}
+				}
+			}
+		}()
+
+		ag, updateCh, gapCh := NewAggrStreamWithOptions(logger, done, stream, symbols, AggrOptions{Shards: 4})
+		assert.Len(t, ag.QueueDepths(), 4)
+		updates := 0
+		for range updateCh {
+			updates++
+		}
+		assert.Equal(t, perSymbol*len(symbols), updates)
+		for gap := range gapCh {
+			t.Errorf("trades reordered, gap %+v", gap)
+		}
+		for _, symbol := range symbols {
+			bar, err := ag.OHLCBar(symbol)
+			assert.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("0.1%04d", perSymbol), bar.C, symbol)
+		}
+	})
+}
+
+// throughput only scales with shards given as many cores
+func BenchmarkAggrShards(b *testing.B) {
+	symbols := make([]string, 300)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%03dUSDT", i)
+	}
+	events := make([]*bconn.WsAggTradeEvent, 100000)
+	for i := range events {
+		events[i] = &bconn.WsAggTradeEvent{
+			Symbol:     symbols[i%len(symbols)],
+			AggTradeID: int64(i/len(symbols) + 1),
+			Price:      fmt.Sprintf("0.1%04d", (i*7919)%10000),
+			TradeTime:  1737734700000 + int64(i/len(symbols)),
+		}
+	}
+
+	for _, shards := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				done := make(chan struct{})
+				stream := make(chan *bconn.WsAggTradeEvent, 1000)
+				go func() {
+					defer close(stream)
+					for _, e := range events {
+						stream <- e
+					}
+				}()
+				_, updateCh, _ := NewAggrStreamWithOptions(logr.Discard(), done, stream, symbols, AggrOptions{Shards: shards})
+				for range updateCh {
+				}
+				close(done)
+			}
+			b.ReportMetric(float64(b.N*len(events))/b.Elapsed().Seconds(), "trades/s")
+		})
+	}
+}
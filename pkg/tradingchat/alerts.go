@@ -0,0 +1,320 @@
+package tradingchat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidAlert   = errors.New("invalid alert")
+	ErrAlertNotFound  = errors.New("alert not found")
+	ErrTooManyAlerts  = errors.New("too many alerts")
+	ErrAlertOwnerGone = errors.New("owner of alerts isn't attached")
+)
+
+const (
+	MaxAlertsPerOwner = 64
+	// closed bars the average volume of volume spikes is taken over unless an alert says otherwise
+	DefaultAlertLookback = 20
+	MaxAlertLookback     = 1440
+	// fired events held for an owner while it's detached
+	maxHeldAlertEvents = 100
+)
+
+// AlertKind is the condition an alert fires on, conditions are evaluated on every update of the 1 minute bar in progress
+type AlertKind string
+
+const (
+	AlertCrossAbove  AlertKind = "cross_above"  // close crosses Level upwards
+	AlertCrossBelow  AlertKind = "cross_below"  // close crosses Level downwards
+	AlertMove        AlertKind = "move"         // close is Level percent or more away from the open of its bar
+	AlertVolumeSpike AlertKind = "volume_spike" // volume of the bar is Level times the average of the last Lookback bars or more
+)
+
+type AlertSpec struct {
+	Symbol   string    `json:"symbol"`
+	Kind     AlertKind `json:"kind"`
+	Level    float64   `json:"level"`
+	Lookback int       `json:"lookback"` // volume spikes only, DefaultAlertLookback if it's 0
+}
+
+func (s AlertSpec) withDefaults() AlertSpec {
+	if s.Kind == AlertVolumeSpike && s.Lookback == 0 {
+		s.Lookback = DefaultAlertLookback
+	}
+	return s
+}
+
+func (s AlertSpec) Validate() error {
+	if s.Symbol == "" {
+		return fmt.Errorf("%w: symbol is missing", ErrInvalidAlert)
+	}
+	switch s.Kind {
+	case AlertCrossAbove, AlertCrossBelow, AlertMove:
+	case AlertVolumeSpike:
+		if s.Lookback < 1 || s.Lookback > MaxAlertLookback {
+			return fmt.Errorf("%w: lookback must be between 1 and %d", ErrInvalidAlert, MaxAlertLookback)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidAlert, s.Kind)
+	}
+	if !(s.Level > 0) || math.IsInf(s.Level, 1) {
+		return fmt.Errorf("%w: %s needs a positive level", ErrInvalidAlert, s.Kind)
+	}
+	return nil
+}
+
+// Alert is a condition registered by an owner, it stays until it's cancelled and fires at most once per bar
+type Alert struct {
+	ID        string    `json:"id"`
+	Spec      AlertSpec `json:"spec"`
+	CreatedAt time.Time `json:"created_at"`
+	Fired     int64     `json:"fired"`      // times it fired
+	LastFired time.Time `json:"last_fired"` // zero until it fired
+}
+
+// AlertEvent is an alert firing on a bar, Value is the close crossing, the move in percent or
+// the multiple of the average volume
+type AlertEvent struct {
+	Owner string
+	Alert Alert
+	Bar   OHLCBar
+	Value float64
+	At    time.Time
+}
+
+// AlertBook keeps alerts of owners, e.g. request ids of clients, and evaluates them against states of symbols.
+// Owners keep their alerts while detached, events fired meanwhile are held until they attach again,
+// owners detached for longer than a ttl are dropped
+type AlertBook struct {
+	clock Clock
+	ttl   time.Duration
+
+	mu      *sync.Mutex
+	seq     int64
+	owners  map[string]*alertOwner
+	symbols map[string]*alertSymbol
+}
+
+type alertOwner struct {
+	alerts     map[string]*alertState
+	held       []AlertEvent
+	detachedAt time.Time // zero while attached
+}
+
+type alertState struct {
+	alert    Alert
+	seq      int64
+	firedBar int64 // open time of the bar it last fired on
+}
+
+// alertSymbol is what conditions of a symbol need from earlier updates
+type alertSymbol struct {
+	hasClose bool
+	close    float64
+	open     int64     // open time of the bar in progress
+	volume   float64   // volume of the bar in progress
+	volumes  []float64 // volumes of the latest closed bars, oldest first, minutes without trades have no bar
+}
+
+func NewAlertBook(clock Clock, ttl time.Duration) *AlertBook {
+	return &AlertBook{
+		clock:   orSystemClock(clock),
+		ttl:     ttl,
+		mu:      &sync.Mutex{},
+		owners:  map[string]*alertOwner{},
+		symbols: map[string]*alertSymbol{},
+	}
+}
+
+// Attach marks owner as attached, and returns events held for it
+func (b *AlertBook) Attach(owner string) []AlertEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweep()
+	o, ok := b.owners[owner]
+	if !ok {
+		o = &alertOwner{alerts: map[string]*alertState{}}
+		b.owners[owner] = o
+	}
+	held := o.held
+	o.held, o.detachedAt = nil, time.Time{}
+	return held
+}
+
+// Detach marks owner as detached, its alerts are dropped once it's detached for longer than the ttl
+func (b *AlertBook) Detach(owner string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if o, ok := b.owners[owner]; ok {
+		o.detachedAt = b.clock.Now()
+	}
+	b.sweep()
+}
+
+// Hold keeps ev until its owner attaches again, the oldest events are dropped beyond maxHeldAlertEvents
+func (b *AlertBook) Hold(ev AlertEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.owners[ev.Owner]
+	if !ok {
+		return
+	}
+	o.held = append(o.held, ev)
+	if len(o.held) > maxHeldAlertEvents {
+		o.held = o.held[len(o.held)-maxHeldAlertEvents:]
+	}
+}
+
+// Add registers an alert of spec for an attached owner
+func (b *AlertBook) Add(owner string, spec AlertSpec) (Alert, error) {
+	spec = spec.withDefaults()
+	if err := spec.Validate(); err != nil {
+		return Alert{}, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.owners[owner]
+	if !ok {
+		return Alert{}, ErrAlertOwnerGone
+	}
+	if len(o.alerts) >= MaxAlertsPerOwner {
+		return Alert{}, fmt.Errorf("%w: at most %d alerts are allowed", ErrTooManyAlerts, MaxAlertsPerOwner)
+	}
+	b.seq++
+	alert := Alert{ID: "alert-" + strconv.FormatInt(b.seq, 10), Spec: spec, CreatedAt: b.clock.Now()}
+	o.alerts[alert.ID] = &alertState{alert: alert, seq: b.seq}
+	return alert, nil
+}
+
+// Cancel removes alert id of owner
+func (b *AlertBook) Cancel(owner, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.owners[owner]
+	if !ok || o.alerts[id] == nil {
+		return ErrAlertNotFound
+	}
+	delete(o.alerts, id)
+	return nil
+}
+
+// List returns alerts of owner in the order they were added
+func (b *AlertBook) List(owner string) []Alert {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	o, ok := b.owners[owner]
+	if !ok {
+		return nil
+	}
+	states := make([]*alertState, 0, len(o.alerts))
+	for _, a := range o.alerts {
+		states = append(states, a)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].seq < states[j].seq })
+	alerts := make([]Alert, 0, len(states))
+	for _, a := range states {
+		alerts = append(alerts, a.alert)
+	}
+	return alerts
+}
+
+// Update evaluates alerts of symbol against its latest state, and returns the events they fired
+func (b *AlertBook) Update(symbol string, st SymbolState) []AlertEvent {
+	if st.Bar.T == 0 {
+		return nil
+	}
+	open, err := strconv.ParseFloat(st.Bar.O, 64)
+	if err != nil {
+		return nil
+	}
+	closePrice, err := strconv.ParseFloat(st.Bar.C, 64)
+	if err != nil {
+		return nil
+	}
+	barOpen := st.Bar.OpenTime().Unix()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sym, ok := b.symbols[symbol]
+	if !ok {
+		sym = &alertSymbol{open: barOpen}
+		b.symbols[symbol] = sym
+	}
+	if barOpen > sym.open {
+		sym.volumes = append(sym.volumes, sym.volume)
+		if len(sym.volumes) > MaxAlertLookback {
+			sym.volumes = sym.volumes[len(sym.volumes)-MaxAlertLookback:]
+		}
+		sym.open = barOpen
+	}
+	if barOpen == sym.open {
+		sym.volume = st.Volume
+	}
+
+	now := b.clock.Now()
+	var events []AlertEvent
+	for owner, o := range b.owners {
+		for _, a := range o.alerts {
+			spec := a.alert.Spec
+			if spec.Symbol != symbol {
+				continue
+			}
+			value, fired := sym.evaluate(spec, open, closePrice)
+			if !fired || (spec.Kind != AlertCrossAbove && spec.Kind != AlertCrossBelow && a.firedBar == barOpen) {
+				continue
+			}
+			a.firedBar = barOpen
+			a.alert.Fired++
+			a.alert.LastFired = now
+			events = append(events, AlertEvent{Owner: owner, Alert: a.alert, Bar: st.Bar, Value: value, At: now})
+		}
+	}
+	sym.hasClose, sym.close = true, closePrice
+	return events
+}
+
+// evaluate tells if spec holds for the bar in progress, and the value it's met with
+func (sym *alertSymbol) evaluate(spec AlertSpec, open, closePrice float64) (float64, bool) {
+	switch spec.Kind {
+	case AlertCrossAbove:
+		return closePrice, sym.hasClose && sym.close < spec.Level && closePrice >= spec.Level
+	case AlertCrossBelow:
+		return closePrice, sym.hasClose && sym.close > spec.Level && closePrice <= spec.Level
+	case AlertMove:
+		if open == 0 {
+			return 0, false
+		}
+		move := math.Abs(closePrice-open) / open * 100
+		return move, move >= spec.Level
+	default:
+		if len(sym.volumes) < spec.Lookback {
+			return 0, false
+		}
+		var sum float64
+		for _, v := range sym.volumes[len(sym.volumes)-spec.Lookback:] {
+			sum += v
+		}
+		avg := sum / float64(spec.Lookback)
+		if avg == 0 {
+			return 0, false
+		}
+		multiple := sym.volume / avg
+		return multiple, multiple >= spec.Level
+	}
+}
+
+// sweep drops owners detached for longer than the ttl, callers hold b.mu
+func (b *AlertBook) sweep() {
+	now := b.clock.Now()
+	for owner, o := range b.owners {
+		if !o.detachedAt.IsZero() && now.Sub(o.detachedAt) > b.ttl {
+			delete(b.owners, owner)
+		}
+	}
+}
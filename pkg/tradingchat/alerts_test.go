@@ -0,0 +1,95 @@
+package tradingchat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlertBook(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+	state := func(o, c string, at int64, volume float64) SymbolState {
+		return SymbolState{Bar: OHLCBar{H: c, L: o, O: o, C: c, T: at}, Volume: volume}
+	}
+
+	t.Run("crossings should fire on every crossing", func(t *testing.T) {
+		book := NewAlertBook(NewFakeClock(time.Unix(open, 0)), time.Hour)
+		book.Attach("req")
+		alert, err := book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertCrossAbove, Level: 0.03})
+		assert.NoError(t, err)
+
+		assert.Empty(t, book.Update("ETHBTC", state("0.029", "0.029", open, 1)))
+		events := book.Update("ETHBTC", state("0.029", "0.031", open+1, 1))
+		assert.Len(t, events, 1)
+		assert.Equal(t, "req", events[0].Owner)
+		assert.Equal(t, alert.ID, events[0].Alert.ID)
+		assert.Equal(t, 0.031, events[0].Value)
+		assert.Empty(t, book.Update("ETHBTC", state("0.029", "0.032", open+2, 1)), "still above")
+
+		book.Update("ETHBTC", state("0.029", "0.029", open+3, 1))
+		assert.Len(t, book.Update("ETHBTC", state("0.029", "0.030", open+4, 1)), 1)
+		assert.Equal(t, int64(2), book.List("req")[0].Fired)
+	})
+
+	t.Run("moves should fire once per bar", func(t *testing.T) {
+		book := NewAlertBook(NewFakeClock(time.Unix(open, 0)), time.Hour)
+		book.Attach("req")
+		book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertMove, Level: 5})
+
+		assert.Empty(t, book.Update("ETHBTC", state("100", "104", open, 1)))
+		assert.Len(t, book.Update("ETHBTC", state("100", "94", open+1, 1)), 1)
+		assert.Empty(t, book.Update("ETHBTC", state("100", "93", open+2, 1)))
+		assert.Len(t, book.Update("ETHBTC", state("93", "99", open+60, 1)), 1, "a new bar")
+	})
+
+	t.Run("volume spikes should compare with the average of closed bars", func(t *testing.T) {
+		book := NewAlertBook(NewFakeClock(time.Unix(open, 0)), time.Hour)
+		book.Attach("req")
+		book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertVolumeSpike, Level: 3, Lookback: 2})
+
+		assert.Empty(t, book.Update("ETHBTC", state("1", "1", open, 10)))
+		assert.Empty(t, book.Update("ETHBTC", state("1", "1", open+60, 20)))
+		assert.Empty(t, book.Update("ETHBTC", state("1", "1", open+120, 40)), "40 is below 3 times 15")
+		events := book.Update("ETHBTC", state("1", "1", open+121, 45))
+		assert.Len(t, events, 1)
+		assert.Equal(t, 3.0, events[0].Value)
+	})
+
+	t.Run("alerts should survive detaching until the ttl", func(t *testing.T) {
+		clock := NewFakeClock(time.Unix(open, 0))
+		book := NewAlertBook(clock, time.Hour)
+		book.Attach("req")
+		book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertMove, Level: 1})
+		book.Detach("req")
+
+		for _, ev := range book.Update("ETHBTC", state("100", "110", open, 1)) {
+			book.Hold(ev)
+		}
+		held := book.Attach("req")
+		assert.Len(t, held, 1)
+		assert.Len(t, book.List("req"), 1)
+
+		book.Detach("req")
+		clock.Advance(2 * time.Hour)
+		book.Attach("other")
+		assert.Empty(t, book.List("req"))
+	})
+
+	t.Run("alerts should be cancelled and validated", func(t *testing.T) {
+		book := NewAlertBook(NewFakeClock(time.Unix(open, 0)), time.Hour)
+		_, err := book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertMove, Level: 1})
+		assert.ErrorIs(t, err, ErrAlertOwnerGone)
+
+		book.Attach("req")
+		_, err = book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertMove})
+		assert.ErrorIs(t, err, ErrInvalidAlert)
+		alert, _ := book.Add("req", AlertSpec{Symbol: "ETHBTC", Kind: AlertVolumeSpike, Level: 2})
+		assert.Equal(t, DefaultAlertLookback, alert.Spec.Lookback)
+
+		assert.NoError(t, book.Cancel("req", alert.ID))
+		assert.ErrorIs(t, book.Cancel("req", alert.ID), ErrAlertNotFound)
+		assert.Empty(t, book.List("req"))
+	})
+}
@@ -1,7 +1,6 @@
 package tradingchat
 
 import (
-	bconn "github.com/binance/binance-connector-go"
 	"github.com/go-logr/logr"
 )
 
@@ -9,41 +8,43 @@ const (
 	BinanceStreamURL = "wss://stream.binance.com:443"
 )
 
-func BinanceStreamEventGen(logger logr.Logger, symbols []string, errHandler func(error), done <-chan struct{}) (<-chan *bconn.WsAggTradeEvent, error) {
-	c := bconn.NewWebsocketStreamClient(true, BinanceStreamURL)
-	eventCh := make(chan *bconn.WsAggTradeEvent)
-
-	doneCh, stopCh, err := c.WsCombinedAggTradeServe(
-		symbols,
-		func(event *bconn.WsAggTradeEvent) {
-			logger.V(4).Info("incoming event", "event", event)
-			eventCh <- event
-		},
-		func(err error) {
-			logger.V(2).Error(err, "driver stopped")
-			errHandler(err)
-		},
-	)
+// streamConn is a connection of the binance connector
+type streamConn struct {
+	doneCh, stopCh chan struct{}
+}
 
-	if err != nil {
-		logger.V(2).Error(err, "starting driver failed")
-		return nil, err
+// stop stops the connection unless it ended already
+func (c streamConn) stop() {
+	// the driver reads stopCh itself on read errors, so it can't be closed
+	select {
+	case c.stopCh <- struct{}{}:
+	case <-c.doneCh:
 	}
+}
 
-	stop := func() { stopCh <- struct{}{} }
-
+// redialStream dials a stream that ended again until it succeeds, attempts back off like the ones of BinanceSource.
+// The connection is handed over on the returned channel, or stopped if done is closed first
+func redialStream(logger logr.Logger, clock Clock, done <-chan struct{}, dial func() (streamConn, error)) <-chan streamConn {
+	redialed := make(chan streamConn)
 	go func() {
-		defer close(eventCh)
-		for {
+		for delay := redialDelay; ; delay = min(2*delay, maxRedialDelay) {
 			select {
-			case <-doneCh:
-				return
 			case <-done:
-				stop()
 				return
+			case <-clock.After(delay):
 			}
+			conn, err := dial()
+			if err != nil {
+				logger.Error(err, "redialing stream failed", "retry_in", min(2*delay, maxRedialDelay))
+				continue
+			}
+			select {
+			case redialed <- conn:
+			case <-done:
+				conn.stop()
+			}
+			return
 		}
 	}()
-
-	return eventCh, nil
+	return redialed
 }
@@ -17,12 +17,22 @@ type OHLCBar struct {
 	O string `json:"open"`
 	C string `json:"close"`
 	T int64  `json:"time"` // Newest time of item
+	// Incomplete bars may miss trades that never arrived from the feed
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// OpenTime is the start of the minute the bar belongs to
+func (b OHLCBar) OpenTime() time.Time {
+	return time.Unix(b.T, 0).Truncate(Interval1M)
 }
 
 type OHLCCalc struct {
 	bar     OHLCBar
 	endedAt int64
 	logger  logr.Logger
+	// trade time in milliseconds and id of the trade the close is from, trades of the same second are told apart by them
+	closeTime int64
+	closeID   int64
 }
 
 func NewOHLCCalc(logger logr.Logger) *OHLCCalc {
@@ -41,19 +51,20 @@ func NewOHLCCalc(logger logr.Logger) *OHLCCalc {
 
 func (c *OHLCCalc) update(event *bconn.WsAggTradeEvent) {
 	price := event.Price
-	ts := event.TradeTime
+	ts := tradeUnix(event)
 
 	c.logger.V(4).Info("OHLCCalc before update", "OHLCCalc", c, "event", event)
 	if c.endedAt >= ts {
-		if c.bar.H < price {
+		if priceLess(c.bar.H, price) {
 			c.bar.H = price
 		}
-		if c.bar.L > price {
+		if priceLess(price, c.bar.L) {
 			c.bar.L = price
 		}
-		if c.bar.T < ts {
+		if c.closedBy(event) {
 			c.bar.C = price
 			c.bar.T = ts
+			c.closeTime, c.closeID = event.TradeTime, event.AggTradeID
 		}
 	} else {
 		c.bar.H = price
@@ -61,17 +72,49 @@ func (c *OHLCCalc) update(event *bconn.WsAggTradeEvent) {
 		c.bar.O = price
 		c.bar.C = price
 		c.bar.T = ts
+		c.bar.Incomplete = false
+		c.closeTime, c.closeID = event.TradeTime, event.AggTradeID
 		c.tick(ts)
 	}
 	c.logger.V(4).Info("OHLCCalc updated", "OHLCCalc", c, "event", event)
 }
 
+// closedBy tells if event traded after the trade the close is from
+func (c *OHLCCalc) closedBy(event *bconn.WsAggTradeEvent) bool {
+	if event.TradeTime != c.closeTime {
+		return event.TradeTime > c.closeTime
+	}
+	return event.AggTradeID > c.closeID
+}
+
 func (c *OHLCCalc) tick(newTick int64) {
 	newEndedAt := time.Unix(newTick, 0).Truncate(time.Minute).Add(59 * time.Second).Unix()
 	c.endedAt = newEndedAt
 	c.logger.V(4).Info("tick updated", "newtick", newTick, "old-endedAt", c.endedAt, "new_endedAt", newEndedAt)
 }
 
+// closedWithin tells if the bar in progress closed during the d before now, the next trade opens a new bar.
+// Trades arriving late still update it
+func (c *OHLCCalc) closedWithin(now int64, d time.Duration) bool {
+	return c.bar.T > 0 && now > c.endedAt && now-c.endedAt <= int64(d.Seconds())
+}
+
+// seed continues bar with trades of the same minute
+func (c *OHLCCalc) seed(bar OHLCBar) {
+	c.bar = bar
+	c.closeTime, c.closeID = bar.T*1000, 0
+	c.tick(bar.T)
+}
+
+func (c *OHLCCalc) markIncomplete() {
+	c.bar.Incomplete = true
+}
+
 func (c *OHLCCalc) Bar() OHLCBar {
 	return c.bar
 }
+
+// tradeUnix is the trade time of event in unix seconds, events carry milliseconds as binance sends them
+func tradeUnix(event *bconn.WsAggTradeEvent) int64 {
+	return event.TradeTime / 1000
+}
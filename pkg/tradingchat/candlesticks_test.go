@@ -18,38 +18,38 @@ func TestOHLCCalc(t *testing.T) {
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11111",
-				TradeTime: 1737734701,
+				TradeTime: 1737734701000,
 			},
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11121",
-				TradeTime: 1737734711,
+				TradeTime: 1737734711000,
 			},
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11109",
-				TradeTime: 1737734709,
+				TradeTime: 1737734709000,
 			},
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11131",
-				TradeTime: 1737734744,
+				TradeTime: 1737734744000,
 			},
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11104",
-				TradeTime: 1737734759,
+				TradeTime: 1737734759000,
 			},
 			{
 				Symbol:    "BNBBTC",
 				Price:     "0.11134",
-				TradeTime: 1737734731,
+				TradeTime: 1737734731000,
 			},
 		}
 		specialEvent := &bconn.WsAggTradeEvent{
 			Symbol:    "BNBBTC",
 			Price:     "0.11101",
-			TradeTime: 1737734760,
+			TradeTime: 1737734760000,
 		}
 
 		var inittime int64 = 1737734700
@@ -68,6 +68,8 @@ func TestOHLCCalc(t *testing.T) {
 				},
 				0,
 				logger,
+				0,
+				0,
 			},
 			calc,
 		)
@@ -86,6 +88,8 @@ func TestOHLCCalc(t *testing.T) {
 				},
 				beforeSpecialEvent,
 				logger,
+				1737734759000,
+				0,
 			},
 			calc,
 		)
@@ -98,15 +102,39 @@ func TestOHLCCalc(t *testing.T) {
 					L: specialEvent.Price,
 					O: specialEvent.Price,
 					C: specialEvent.Price,
-					T: specialEvent.TradeTime,
+					T: specialEvent.TradeTime / 1000,
 				},
 				afterSepcialEvent,
 				logger,
+				specialEvent.TradeTime,
+				0,
 			},
 			calc,
 		)
 	})
 
+	t.Run("trades of the same second should close the bar in trade order", func(t *testing.T) {
+		calc := NewOHLCCalc(logger)
+		calc.update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: 1737734759100})
+		calc.update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 3, Price: "0.11131", TradeTime: 1737734759700})
+		assert.Equal(t, OHLCBar{H: "0.11131", L: "0.11111", O: "0.11111", C: "0.11131", T: 1737734759}, calc.Bar())
+
+		// a trade arriving late doesn't close the bar, unless it traded last
+		calc.update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", TradeTime: 1737734759400})
+		assert.Equal(t, "0.11131", calc.Bar().C)
+		calc.update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 4, Price: "0.11101", TradeTime: 1737734759700})
+		assert.Equal(t, OHLCBar{H: "0.11131", L: "0.11101", O: "0.11111", C: "0.11101", T: 1737734759}, calc.Bar())
+	})
+
+	t.Run("high and low should compare prices by value", func(t *testing.T) {
+		calc := NewOHLCCalc(logger)
+		// "9.5" sorts after "10.25" as text, and "0.9" after "0.10"
+		for i, price := range []string{"9.5", "10.25", "0.9", "0.10"} {
+			calc.update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: int64(i + 1), Price: price, TradeTime: 1737734701000 + int64(i)*1000})
+		}
+		assert.Equal(t, OHLCBar{H: "10.25", L: "0.10", O: "9.5", C: "0.10", T: 1737734704}, calc.Bar())
+	})
+
 	t.Run("bar should be a copy of it", func(t *testing.T) {
 		calc := NewOHLCCalc(logger)
 		oldItem := calc.Bar()
@@ -122,7 +150,7 @@ func TestOHLCCalc(t *testing.T) {
 		specialEvent := &bconn.WsAggTradeEvent{
 			Symbol:    "BNBBTC",
 			Price:     "0.11101",
-			TradeTime: 1737734760,
+			TradeTime: 1737734760000,
 		}
 		calc.update(specialEvent)
 
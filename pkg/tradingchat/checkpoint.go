@@ -0,0 +1,223 @@
+package tradingchat
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+var ErrStateMismatch = errors.New("checkpointed state doesn't match the spec")
+
+// CheckpointVersion is bumped whenever the layout of Checkpoint changes, checkpoints of other versions are ignored
+const CheckpointVersion = 3
+
+// time a checkpoint is given to be saved
+const checkpointTimeout = 30 * time.Second
+
+// Checkpoint is the state of the aggregation of every symbol at TakenAt in unix seconds
+type Checkpoint struct {
+	Version int                    `json:"version"`
+	TakenAt int64                  `json:"taken_at"`
+	Symbols map[string]SymbolState `json:"symbols"`
+}
+
+// SymbolState is the state of the aggregation of a symbol
+type SymbolState struct {
+	Bar      OHLCBar `json:"bar"`              // bar in progress
+	EndedAt  int64   `json:"ended_at"`         // watermark the bar in progress closes at
+	LastID   int64   `json:"last_id"`          // last aggregated trade id
+	LastTime int64   `json:"last_time"`        // time of the last aggregated trade in milliseconds
+	Volume   float64 `json:"volume,omitempty"` // base volume of the bar in progress
+	// closed bars of every history interval, indicators and Heikin-Ashi bars are warmed up with them
+	Recent RecentBars `json:"recent,omitempty"`
+	// builders of activity bars built for every symbol, by name of their spec
+	Activity []ActivityState `json:"activity,omitempty"`
+	// latest trades of the legs of a derived instrument
+	Derived *DerivedState `json:"derived,omitempty"`
+}
+
+// ActivityState is the state of a builder of activity bars, Renko and range builders keep where the price stands
+type ActivityState struct {
+	Spec     ActivitySpec `json:"spec"`
+	Bar      ActivityBar  `json:"bar"` // bar in progress, or the trades since the last brick
+	Open     bool         `json:"open"`
+	Level    int64        `json:"level,omitempty"`
+	Dir      int          `json:"dir,omitempty"`
+	Started  bool         `json:"started,omitempty"`
+	High     float64      `json:"high,omitempty"`
+	Low      float64      `json:"low,omitempty"`
+	Decimals int          `json:"decimals,omitempty"`
+}
+
+// DerivedState is the state of a derived instrument, the latest trade of every leg in the order of its spec
+type DerivedState struct {
+	Legs     []LegState `json:"legs"`
+	Decimals int        `json:"decimals,omitempty"`
+}
+
+// LegState is the latest trade of a leg at Time in milliseconds, composite indexes weighing by volume keep fills too
+type LegState struct {
+	Price float64   `json:"price"`
+	Time  int64     `json:"time"`
+	Fills []LegFill `json:"fills,omitempty"`
+}
+
+type LegFill struct {
+	Time int64   `json:"time"`
+	Qty  float64 `json:"qty"`
+}
+
+// CheckpointStore keeps the latest checkpoint
+type CheckpointStore interface {
+	SaveCheckpoint(ctx context.Context, cp Checkpoint) error
+	// LoadCheckpoint returns false if no checkpoint was saved
+	LoadCheckpoint(ctx context.Context) (Checkpoint, bool, error)
+}
+
+// snapshot is made of the states published by shards, states of derived instruments are only safe to take
+// on the goroutine dispatching trades or once it stopped
+func snapshot(ag *Aggr, derived []derived, now time.Time) Checkpoint {
+	ag.rw.RLock()
+	defer ag.rw.RUnlock()
+	cp := Checkpoint{
+		Version: CheckpointVersion,
+		TakenAt: now.Unix(),
+		Symbols: make(map[string]SymbolState, len(ag.symbols)),
+	}
+	for symbol, sa := range ag.symbols {
+		cp.Symbols[symbol] = *sa.state.Load()
+	}
+	for _, d := range derived {
+		if state, ok := cp.Symbols[d.symbol()]; ok {
+			ds := d.state()
+			state.Derived = &ds
+			cp.Symbols[d.symbol()] = state
+		}
+	}
+	return cp
+}
+
+// restore loads state of symbols and derived instruments from cp, bars in progress are only restored if they are
+// still in progress at now, otherwise they are closed into recent bars
+func restore(logger logr.Logger, ag *Aggr, derived []derived, cp *Checkpoint, now time.Time) {
+	if cp == nil {
+		return
+	}
+	if cp.Version != CheckpointVersion {
+		logger.Info("checkpoint of another version ignored", "version", cp.Version)
+		return
+	}
+	minute := now.Truncate(Interval1M)
+	for symbol, state := range cp.Symbols {
+		sa, ok := ag.lookup(symbol)
+		if !ok {
+			continue
+		}
+		sa.ids.lastID, sa.ids.lastTime = state.LastID, state.LastTime
+		sa.recent = state.Recent
+		switch {
+		case state.Bar.T > 0 && state.Bar.OpenTime().Equal(minute):
+			sa.calc.bar, sa.calc.endedAt = state.Bar, state.EndedAt
+			sa.calc.closeTime, sa.calc.closeID = state.Bar.T*1000, 0
+			sa.volume = state.Volume
+		case state.Bar.T > 0:
+			sa.recent = sa.recent.push(state.Bar)
+		}
+		sa.restoreActivity(logger, symbol, state.Activity)
+		sa.publish()
+	}
+	for _, d := range derived {
+		state, ok := cp.Symbols[d.symbol()]
+		if !ok || state.Derived == nil {
+			continue
+		}
+		if err := d.restore(*state.Derived); err != nil {
+			logger.Error(err, "derived instrument starts over", "symbol", d.symbol())
+		}
+	}
+	logger.Info("aggregation state restored from checkpoint", "taken_at", time.Unix(cp.TakenAt, 0), "symbols", len(cp.Symbols))
+}
+
+// restoreActivity loads state of builders of activity bars built for every symbol, builders of other specs
+// start over
+func (sa *symbolAggr) restoreActivity(logger logr.Logger, symbol string, states []ActivityState) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+	for _, state := range states {
+		b, ok := sa.activity[state.Spec]
+		if !ok || !b.pinned {
+			logger.V(1).Info("activity bars of the checkpoint no longer built", "symbol", symbol, "spec", state.Spec)
+			continue
+		}
+		if cb, ok := b.calc.(checkpointedBuilder); ok {
+			cb.restore(state)
+		}
+	}
+}
+
+// activityStates returns state of builders of activity bars built for every symbol, callers hold sa.mu
+func (sa *symbolAggr) activityStates() []ActivityState {
+	var states []ActivityState
+	for _, b := range sa.activity {
+		if cb, ok := b.calc.(checkpointedBuilder); ok && b.pinned {
+			states = append(states, cb.state())
+		}
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Spec.Name() < states[j].Spec.Name() })
+	return states
+}
+
+// checkpointer saves checkpoints in the background, a checkpoint taken while the previous one
+// is still being saved replaces it
+type checkpointer struct {
+	logger logr.Logger
+	store  CheckpointStore
+	queue  chan Checkpoint
+	done   chan struct{}
+}
+
+func newCheckpointer(logger logr.Logger, store CheckpointStore) *checkpointer {
+	c := &checkpointer{
+		logger: logger,
+		store:  store,
+		queue:  make(chan Checkpoint, 1),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(c.done)
+		for cp := range c.queue {
+			ctx, cancel := context.WithTimeout(context.Background(), checkpointTimeout)
+			err := c.store.SaveCheckpoint(ctx, cp)
+			cancel()
+			if err != nil {
+				c.logger.Error(err, "unable to save checkpoint")
+				continue
+			}
+			c.logger.V(2).Info("checkpoint saved", "taken_at", cp.TakenAt)
+		}
+	}()
+	return c
+}
+
+func (c *checkpointer) save(cp Checkpoint) {
+	select {
+	case c.queue <- cp:
+	default:
+		// replace the pending one
+		select {
+		case <-c.queue:
+		default:
+		}
+		c.queue <- cp
+	}
+}
+
+// close saves the final checkpoint and waits for it
+func (c *checkpointer) close(cp Checkpoint) {
+	c.save(cp)
+	close(c.queue)
+	<-c.done
+}
@@ -0,0 +1,201 @@
+package tradingchat
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+type memCheckpoints struct {
+	mu    sync.Mutex
+	saved []Checkpoint
+}
+
+func (m *memCheckpoints) SaveCheckpoint(_ context.Context, cp Checkpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.saved = append(m.saved, cp)
+	return nil
+}
+
+func (m *memCheckpoints) LoadCheckpoint(_ context.Context) (Checkpoint, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.saved) == 0 {
+		return Checkpoint{}, false, nil
+	}
+	return m.saved[len(m.saved)-1], true, nil
+}
+
+func TestCheckpoints(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	// 16:05 on Jan 24th 2025, the clock is in the middle of the minute
+	now := int64(1737734700)
+	clock := NewFakeClock(time.Unix(now+30, 0))
+
+	run := func(events []*bconn.WsAggTradeEvent, opts AggrOptions, symbols ...string) (*Aggr, []Gap) {
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		go func() {
+			defer close(stream)
+			for _, e := range events {
+				stream <- e
+			}
+		}()
+		opts.Clock = clock
+		if len(symbols) == 0 {
+			symbols = []string{"BNBBTC"}
+		}
+		aggr, updateCh, gapCh := NewAggrStreamWithOptions(logger, done, stream, symbols, opts)
+		for range updateCh {
+		}
+		var gaps []Gap
+		for gap := range gapCh {
+			gaps = append(gaps, gap)
+		}
+		return aggr, gaps
+	}
+
+	store := &memCheckpoints{}
+
+	t.Run("state should be checkpointed when aggregation stops", func(t *testing.T) {
+		run([]*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: now * 1000},
+			{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", TradeTime: (now + 1) * 1000},
+		}, AggrOptions{Checkpoints: store, CheckpointEvery: time.Hour})
+
+		cp, ok, err := store.LoadCheckpoint(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, CheckpointVersion, cp.Version)
+		assert.Equal(t, SymbolState{
+			Bar:      OHLCBar{H: "0.11121", L: "0.11111", O: "0.11111", C: "0.11121", T: now + 1},
+			EndedAt:  now + 59,
+			LastID:   2,
+			LastTime: (now + 1) * 1000,
+		}, cp.Symbols["BNBBTC"])
+	})
+
+	t.Run("restored state should continue bar and trade ids", func(t *testing.T) {
+		cp, _, _ := store.LoadCheckpoint(context.Background())
+		aggr, gaps := run([]*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", TradeTime: (now + 1) * 1000},
+			{Symbol: "BNBBTC", AggTradeID: 4, Price: "0.11101", TradeTime: (now + 2) * 1000},
+		}, AggrOptions{Restore: &cp})
+
+		assert.Equal(t, []Gap{{Symbol: "BNBBTC", FromID: 3, ToID: 3, Start: now + 1, End: now + 2}}, gaps)
+		bar, err := aggr.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11121", L: "0.11101", O: "0.11111", C: "0.11101", T: now + 2, Incomplete: true}, bar)
+	})
+
+	t.Run("checkpoint should be taken periodically", func(t *testing.T) {
+		periodic := &memCheckpoints{}
+		done := make(chan struct{})
+		stream := make(chan *bconn.WsAggTradeEvent)
+		periodicClock := NewFakeClock(time.Unix(now, 0))
+		NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{Checkpoints: periodic, CheckpointEvery: time.Minute, Clock: periodicClock})
+
+		assert.Eventually(t, func() bool {
+			periodicClock.Advance(time.Minute)
+			periodic.mu.Lock()
+			defer periodic.mu.Unlock()
+			return len(periodic.saved) >= 2
+		}, time.Second, 10*time.Millisecond)
+		close(done)
+	})
+
+	t.Run("checkpoint of another version should be ignored", func(t *testing.T) {
+		aggr, gaps := run([]*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 9, Price: "0.11101", TradeTime: (now + 2) * 1000},
+		}, AggrOptions{Restore: &Checkpoint{Version: CheckpointVersion + 1, Symbols: map[string]SymbolState{"BNBBTC": {LastID: 2}}}})
+		assert.Empty(t, gaps)
+		bar, _ := aggr.OHLCBar("BNBBTC")
+		assert.Equal(t, "0.11101", bar.O)
+	})
+
+	t.Run("closed bars and activity bars in progress should be restored", func(t *testing.T) {
+		specs := []ActivitySpec{{Type: ActivityTick, Threshold: 3}, {Type: ActivityRenko, Threshold: 0.01}}
+		saved := &memCheckpoints{}
+		run([]*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.110", Quantity: "1", TradeTime: (now - 59) * 1000},
+			{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.112", Quantity: "1", TradeTime: (now + 1) * 1000},
+		}, AggrOptions{Checkpoints: saved, ActivityBars: specs})
+
+		cp, _, _ := saved.LoadCheckpoint(context.Background())
+		state := cp.Symbols["BNBBTC"]
+		assert.Equal(t, []OHLCBar{{H: "0.110", L: "0.110", O: "0.110", C: "0.110", T: now - 60}}, state.Recent["1m"])
+		assert.Len(t, state.Recent, 4)
+		if assert.Len(t, state.Activity, 2) {
+			assert.Equal(t, ActivityState{
+				Spec: specs[1],
+				Bar: ActivityBar{
+					OHLCBar: OHLCBar{H: "0.112", L: "0.110", O: "0.110", C: "0.112", T: now + 1},
+					OpenT:   now - 59, FirstID: 1, LastID: 2, Trades: 2, Volume: 2, Notional: 0.222,
+				},
+				Open: true, Level: 11, Started: true, Decimals: 3,
+			}, state.Activity[0], "bricks start at the level closest to the first trade")
+			assert.Equal(t, specs[0], state.Activity[1].Spec)
+			assert.Equal(t, int64(2), state.Activity[1].Bar.Trades)
+		}
+
+		aggr, _ := run([]*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 3, Price: "0.111", Quantity: "1", TradeTime: (now + 2) * 1000},
+		}, AggrOptions{Restore: &cp, ActivityBars: specs})
+
+		var closed []ActivityUpdate
+		for u := range aggr.ActivityBars() {
+			if u.Closed {
+				closed = append(closed, u)
+			}
+		}
+		if assert.Len(t, closed, 1, "the tick bar continues with the trades of the checkpoint") {
+			assert.Equal(t, specs[0], closed[0].Spec)
+			assert.Equal(t, int64(1), closed[0].Bar.FirstID)
+			assert.Equal(t, int64(3), closed[0].Bar.Trades)
+		}
+
+		bars, err := aggr.RecentBars("BNBBTC", Interval5M)
+		assert.NoError(t, err)
+		assert.Equal(t, []OHLCBar{
+			{H: "0.110", L: "0.110", O: "0.110", C: "0.110", T: now - 300},
+			{H: "0.112", L: "0.111", O: "0.112", C: "0.111", T: now},
+		}, bars, "closed bars roll up with the one in progress")
+	})
+
+	t.Run("bar no longer in progress should be restored as a closed bar", func(t *testing.T) {
+		aggr, _ := run(nil, AggrOptions{Restore: &Checkpoint{Version: CheckpointVersion, Symbols: map[string]SymbolState{
+			"BNBBTC": {Bar: OHLCBar{H: "2", L: "1", O: "1", C: "2", T: now - 50}, EndedAt: now - 1, LastID: 2},
+		}}})
+		bars, err := aggr.RecentBars("BNBBTC", Interval1M)
+		assert.NoError(t, err)
+		assert.Equal(t, []OHLCBar{{H: "2", L: "1", O: "1", C: "2", T: now - 60}}, bars)
+		_, err = aggr.RecentBars("BNBBTC", 90*time.Second)
+		assert.ErrorIs(t, err, ErrInvalidInterval)
+	})
+
+	t.Run("legs of derived instruments should be restored", func(t *testing.T) {
+		synthetics := []SyntheticSpec{{Symbol: "ETHBNB", Kind: SyntheticRatio, Legs: []SyntheticLeg{{Symbol: "ETHBTC", Weight: 1}, {Symbol: "BNBBTC", Weight: 1}}, Decimals: 2}}
+		saved := &memCheckpoints{}
+		run([]*bconn.WsAggTradeEvent{
+			{Symbol: "ETHBTC", AggTradeID: 1, Price: "0.03", TradeTime: (now + 1) * 1000},
+		}, AggrOptions{Checkpoints: saved, Synthetics: synthetics}, "ETHBTC", "BNBBTC")
+
+		cp, _, _ := saved.LoadCheckpoint(context.Background())
+		assert.Equal(t, &DerivedState{Legs: []LegState{{Price: 0.03, Time: (now + 1) * 1000}, {}}}, cp.Symbols["ETHBNB"].Derived)
+
+		// ETHBTC traded before the restart, so a trade of BNBBTC derives a trade right away
+		aggr, _ := run([]*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.01", TradeTime: (now + 2) * 1000},
+		}, AggrOptions{Restore: &cp, Synthetics: synthetics}, "ETHBTC", "BNBBTC")
+		bar, err := aggr.OHLCBar("ETHBNB")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "3.00", L: "3.00", O: "3.00", C: "3.00", T: now + 2}, bar)
+	})
+}
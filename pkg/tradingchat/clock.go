@@ -0,0 +1,157 @@
+package tradingchat
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	_ Clock = systemClock{}
+	_ Clock = (*FakeClock)(nil)
+)
+
+// Clock tells the time and schedules tickers and timeouts, so time can be driven by tests
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks on C until it's stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the wall clock
+var SystemClock Clock = systemClock{}
+
+// orSystemClock returns the wall clock if c is nil
+func orSystemClock(c Clock) Clock {
+	if c == nil {
+		return SystemClock
+	}
+	return c
+}
+
+type systemClock struct{}
+
+// Now implements Clock.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker implements Clock.
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+// After implements Clock.
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type systemTicker struct {
+	t *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTicker) Stop() {
+	t.t.Stop()
+}
+
+// FakeClock only moves when it's advanced, tickers and timeouts due by then fire during Advance.
+// Like time.Ticker, a tick is dropped if the previous one wasn't received yet
+type FakeClock struct {
+	mu     *sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		mu:  &sync.Mutex{},
+		now: now,
+	}
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	at     time.Time
+	period time.Duration // 0 for timeouts
+	c      chan time.Time
+	stop   bool
+}
+
+// Now implements Clock.
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// NewTicker implements Clock.
+func (f *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	return f.schedule(d, d)
+}
+
+// After implements Clock.
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	t := f.schedule(d, 0)
+	f.Advance(0)
+	return t.c
+}
+
+func (f *FakeClock) schedule(d, period time.Duration) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{
+		clock:  f,
+		at:     f.now.Add(d),
+		period: period,
+		c:      make(chan time.Time, 1),
+	}
+	f.timers = append(f.timers, t)
+	return t
+}
+
+// Advance moves the clock forward by d and fires tickers and timeouts due
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	timers := f.timers[:0]
+	for _, t := range f.timers {
+		for !t.stop && !t.at.After(f.now) {
+			select {
+			case t.c <- t.at:
+			default:
+			}
+			if t.period == 0 {
+				t.stop = true
+				break
+			}
+			t.at = t.at.Add(t.period)
+		}
+		if !t.stop {
+			timers = append(timers, t)
+		}
+	}
+	f.timers = timers
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.stop = true
+}
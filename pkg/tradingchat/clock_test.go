@@ -0,0 +1,43 @@
+package tradingchat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Unix(1737734700, 0)
+
+	t.Run("ticker should fire every period the clock is advanced by", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		ticker := clock.NewTicker(time.Minute)
+
+		clock.Advance(59 * time.Second)
+		assert.Empty(t, ticker.C())
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+		// ticks not received are dropped
+		clock.Advance(3 * time.Minute)
+		assert.Equal(t, start.Add(2*time.Minute), <-ticker.C())
+		assert.Empty(t, ticker.C())
+
+		ticker.Stop()
+		clock.Advance(time.Minute)
+		assert.Empty(t, ticker.C())
+		assert.Equal(t, start.Add(5*time.Minute), clock.Now())
+	})
+
+	t.Run("timeout should fire once when it's due", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		after := clock.After(time.Second)
+		clock.Advance(500 * time.Millisecond)
+		assert.Empty(t, after)
+		clock.Advance(time.Second)
+		assert.Equal(t, start.Add(time.Second), <-after)
+
+		assert.Equal(t, clock.Now(), <-clock.After(0))
+	})
+}
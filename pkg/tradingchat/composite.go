@@ -0,0 +1,231 @@
+package tradingchat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+var ErrInvalidComposite = errors.New("invalid composite index")
+
+const (
+	// members deviating more than this from the median are rejected unless a spec says otherwise
+	DefaultCompositeMaxDeviation = 0.02
+	// members that didn't trade for this long are excluded unless a spec says otherwise
+	DefaultCompositeStaleAfter = 30 * time.Second
+)
+
+// CompositeMethod is how prices of the members are combined into the index
+type CompositeMethod string
+
+const (
+	CompositeMedian CompositeMethod = "median" // median of the latest prices
+	CompositeVWAP   CompositeMethod = "vwap"   // latest prices weighted by volume traded within StaleAfter
+)
+
+// CompositeSpec defines a price index of an asset across venues, every member is the symbol of the asset
+// on a venue as the trade source names it. The index is aggregated into bars of Symbol like any symbol
+type CompositeSpec struct {
+	Symbol  string          `json:"symbol"`
+	Method  CompositeMethod `json:"method"`
+	Members []string        `json:"members"`
+	// MaxDeviation rejects members whose price is further than this fraction from the median of the members
+	MaxDeviation float64 `json:"max_deviation"`
+	// StaleAfter excludes members without a trade for this long
+	StaleAfter time.Duration `json:"stale_after"`
+}
+
+func (s CompositeSpec) Validate() error {
+	if s.Symbol == "" {
+		return fmt.Errorf("%w: symbol is missing", ErrInvalidComposite)
+	}
+	if s.Method != CompositeMedian && s.Method != CompositeVWAP {
+		return fmt.Errorf("%w: unknown method %q", ErrInvalidComposite, s.Method)
+	}
+	if len(s.Members) == 0 {
+		return fmt.Errorf("%w: %s has no members", ErrInvalidComposite, s.Symbol)
+	}
+	for i, m := range s.Members {
+		if m == "" || m == s.Symbol || slices.Contains(s.Members[:i], m) {
+			return fmt.Errorf("%w: %s has an invalid member %q", ErrInvalidComposite, s.Symbol, m)
+		}
+	}
+	if !(s.MaxDeviation > 0) || s.StaleAfter < time.Second {
+		return fmt.Errorf("%w: %s needs a positive deviation and staleness of a second at least", ErrInvalidComposite, s.Symbol)
+	}
+	return nil
+}
+
+// ParseCompositeSpec parses definitions like BTCIDX=median:BTCUSDT|BTCUSDC|BTCFDUSD, deviation and staleness
+// are the defaults
+func ParseCompositeSpec(s string) (CompositeSpec, error) {
+	symbol, def, ok := strings.Cut(strings.TrimSpace(s), "=")
+	method, members, ok2 := strings.Cut(def, ":")
+	if !ok || !ok2 {
+		return CompositeSpec{}, fmt.Errorf("%w: %q isn't symbol=method:member|member", ErrInvalidComposite, s)
+	}
+	spec := CompositeSpec{
+		Symbol:       strings.TrimSpace(symbol),
+		Method:       CompositeMethod(strings.TrimSpace(method)),
+		MaxDeviation: DefaultCompositeMaxDeviation,
+		StaleAfter:   DefaultCompositeStaleAfter,
+	}
+	for _, m := range strings.Split(members, "|") {
+		spec.Members = append(spec.Members, strings.TrimSpace(m))
+	}
+	return spec, spec.Validate()
+}
+
+var _ derived = (*composite)(nil)
+
+// composite derives trades of a composite index, each trade of a member is a trade of the index at its time
+type composite struct {
+	spec     CompositeSpec
+	members  []compositeMember
+	decimals int
+	lastID   int64
+}
+
+type compositeMember struct {
+	price float64
+	time  int64 // trade time in milliseconds, 0 until the member traded
+	fills []compositeFill
+}
+
+type compositeFill struct {
+	time int64
+	qty  float64
+}
+
+func newComposite(spec CompositeSpec) *composite {
+	return &composite{spec: spec, members: make([]compositeMember, len(spec.Members))}
+}
+
+func (c *composite) symbol() string      { return c.spec.Symbol }
+func (c *composite) legs() []string      { return c.spec.Members }
+func (c *composite) resume(lastID int64) { c.lastID = lastID }
+
+// trade implements derived. Members without a trade within StaleAfter before e are excluded, the remaining ones
+// deviating too far from their median are rejected, there is no index without any member left
+func (c *composite) trade(e *bconn.WsAggTradeEvent) (*bconn.WsAggTradeEvent, bool) {
+	i := slices.Index(c.spec.Members, e.Symbol)
+	if i < 0 || e.TradeTime < c.members[i].time {
+		return nil, false
+	}
+	price, qty, err := parseTrade(e)
+	if err != nil {
+		return nil, false
+	}
+	c.decimals = max(c.decimals, decimalsOf(e.Price))
+
+	stale := c.spec.StaleAfter.Milliseconds()
+	m := &c.members[i]
+	m.price, m.time = price, e.TradeTime
+	m.fills = append(m.fills, compositeFill{time: e.TradeTime, qty: qty})
+
+	var live []*compositeMember
+	for j := range c.members {
+		m := &c.members[j]
+		// fills older than the window no longer weigh
+		k := 0
+		for k < len(m.fills) && e.TradeTime-m.fills[k].time > stale {
+			k++
+		}
+		m.fills = m.fills[k:]
+		if m.time > 0 && e.TradeTime-m.time <= stale {
+			live = append(live, m)
+		}
+	}
+
+	prices := make([]float64, 0, len(live))
+	for _, m := range live {
+		prices = append(prices, m.price)
+	}
+	mid := median(prices)
+	var kept []*compositeMember
+	for _, m := range live {
+		if math.Abs(m.price-mid) <= mid*c.spec.MaxDeviation {
+			kept = append(kept, m)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, false
+	}
+
+	v, ok := c.index(kept)
+	if !ok {
+		return nil, false
+	}
+	c.lastID++
+	return &bconn.WsAggTradeEvent{
+		Event:      e.Event,
+		Symbol:     c.spec.Symbol,
+		AggTradeID: c.lastID,
+		Price:      strconv.FormatFloat(v, 'f', c.decimals, 64),
+		Quantity:   e.Quantity,
+		TradeTime:  e.TradeTime,
+	}, true
+}
+
+// index combines prices of kept members, VWAP falls back to the median without volume in the window
+func (c *composite) state() DerivedState {
+	legs := make([]LegState, len(c.members))
+	for i, m := range c.members {
+		legs[i] = LegState{Price: m.price, Time: m.time}
+		for _, f := range m.fills {
+			legs[i].Fills = append(legs[i].Fills, LegFill{Time: f.time, Qty: f.qty})
+		}
+	}
+	return DerivedState{Legs: legs, Decimals: c.decimals}
+}
+
+func (c *composite) restore(state DerivedState) error {
+	if len(state.Legs) != len(c.members) {
+		return ErrStateMismatch
+	}
+	for i, leg := range state.Legs {
+		m := compositeMember{price: leg.Price, time: leg.Time}
+		for _, f := range leg.Fills {
+			m.fills = append(m.fills, compositeFill{time: f.Time, qty: f.Qty})
+		}
+		c.members[i] = m
+	}
+	c.decimals = max(c.decimals, state.Decimals)
+	return nil
+}
+
+func (c *composite) index(kept []*compositeMember) (float64, bool) {
+	prices := make([]float64, 0, len(kept))
+	var notional, volume float64
+	for _, m := range kept {
+		prices = append(prices, m.price)
+		for _, f := range m.fills {
+			notional += m.price * f.qty
+			volume += f.qty
+		}
+	}
+	v := median(prices)
+	if c.spec.Method == CompositeVWAP && volume > 0 {
+		v = notional / volume
+	}
+	return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
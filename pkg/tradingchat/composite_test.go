@@ -0,0 +1,96 @@
+package tradingchat
+
+import (
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeSpec(t *testing.T) {
+	t.Run("definitions should be parsed with default deviation and staleness", func(t *testing.T) {
+		spec, err := ParseCompositeSpec("BTCIDX=median:BTCUSDT|BTCUSDC")
+		assert.NoError(t, err)
+		assert.Equal(t, CompositeSpec{
+			Symbol:       "BTCIDX",
+			Method:       CompositeMedian,
+			Members:      []string{"BTCUSDT", "BTCUSDC"},
+			MaxDeviation: DefaultCompositeMaxDeviation,
+			StaleAfter:   DefaultCompositeStaleAfter,
+		}, spec)
+	})
+
+	t.Run("invalid definitions should fail", func(t *testing.T) {
+		for _, s := range []string{"BTCIDX", "BTCIDX=BTCUSDT", "BTCIDX=mean:BTCUSDT", "BTCIDX=vwap:BTCUSDT|BTCUSDT", "BTCIDX=vwap:BTCIDX"} {
+			_, err := ParseCompositeSpec(s)
+			assert.ErrorIs(t, err, ErrInvalidComposite, s)
+		}
+	})
+}
+
+func TestComposite(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+	trade := func(c *composite, symbol, price, qty string, at int64) (string, bool) {
+		e, ok := c.trade(&bconn.WsAggTradeEvent{Symbol: symbol, Price: price, Quantity: qty, TradeTime: at * 1000})
+		if !ok {
+			return "", false
+		}
+		return e.Price, true
+	}
+
+	t.Run("median should reject outliers and stale members", func(t *testing.T) {
+		c := newComposite(CompositeSpec{Symbol: "IDX", Method: CompositeMedian, Members: []string{"A", "B", "C"}, MaxDeviation: 0.02, StaleAfter: 30 * time.Second})
+
+		price, _ := trade(c, "A", "100.00", "1", open)
+		assert.Equal(t, "100.00", price)
+		price, _ = trade(c, "B", "101.00", "1", open+1)
+		assert.Equal(t, "100.50", price)
+		price, _ = trade(c, "C", "150.00", "1", open+2)
+		assert.Equal(t, "100.50", price, "C deviates from the median")
+		price, _ = trade(c, "B", "102.00", "1", open+40)
+		assert.Equal(t, "102.00", price, "A and C are stale")
+	})
+
+	t.Run("vwap should weigh members by volume within the window", func(t *testing.T) {
+		c := newComposite(CompositeSpec{Symbol: "IDX", Method: CompositeVWAP, Members: []string{"A", "B"}, MaxDeviation: 0.02, StaleAfter: 30 * time.Second})
+
+		trade(c, "A", "100.00", "1", open)
+		price, _ := trade(c, "B", "102.00", "3", open+1)
+		assert.Equal(t, "101.50", price)
+	})
+
+	t.Run("members disagreeing with each other should give no index", func(t *testing.T) {
+		c := newComposite(CompositeSpec{Symbol: "IDX", Method: CompositeMedian, Members: []string{"A", "B"}, MaxDeviation: 0.01, StaleAfter: 30 * time.Second})
+
+		trade(c, "A", "100.00", "1", open)
+		_, ok := trade(c, "B", "110.00", "1", open+1)
+		assert.False(t, ok)
+	})
+}
+
+func TestAggrComposites(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	spec, _ := ParseCompositeSpec("BTCIDX=median:BTCUSDT|BTCUSDC")
+
+	done := make(chan struct{})
+	defer close(done)
+	stream := make(chan *bconn.WsAggTradeEvent)
+	ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"BTCUSDT", "BTCUSDC"}, AggrOptions{
+		Composites: []CompositeSpec{spec},
+	})
+
+	// 16:05 on Jan 24th 2025
+	stream <- &bconn.WsAggTradeEvent{Symbol: "BTCUSDT", AggTradeID: 1, Price: "100000.00", Quantity: "1", TradeTime: 1737734700000}
+	stream <- &bconn.WsAggTradeEvent{Symbol: "BTCUSDC", AggTradeID: 1, Price: "100100.00", Quantity: "1", TradeTime: 1737734701000}
+	updates := map[string]int{}
+	for updates["BTCIDX"] < 2 {
+		updates[<-updateCh]++
+	}
+
+	bar, err := ag.OHLCBar("BTCIDX")
+	assert.NoError(t, err)
+	assert.Equal(t, OHLCBar{H: "100050.00", L: "100000.00", O: "100000.00", C: "100050.00", T: 1737734701}, bar)
+}
@@ -0,0 +1,494 @@
+package tradingchat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
+)
+
+var (
+	ErrBookOutOfSync  = errors.New("depth update doesn't follow the local order book")
+	ErrSnapshotFailed = errors.New("unable to fetch depth snapshot")
+)
+
+const (
+	// levels of each side the imbalance of book bars is taken over unless DepthOptions say otherwise
+	DefaultBookLevels = 10
+	// levels of each side fetched in a snapshot
+	depthSnapshotLimit = 1000
+	// diff-depth updates buffered per symbol while its snapshot is fetched, the oldest are dropped beyond it
+	maxBufferedDepth = 1000
+	snapshotTimeout  = 10 * time.Second
+	// time between attempts to fetch a snapshot
+	snapshotRetryDelay = time.Second
+)
+
+// DepthSnapshot is the order book of a symbol as of update id LastUpdateID, best levels first
+type DepthSnapshot struct {
+	LastUpdateID int64
+	Bids         []bconn.Bid
+	Asks         []bconn.Ask
+}
+
+// DepthSnapshotter fetches order books diff-depth updates are applied on
+type DepthSnapshotter interface {
+	Snapshot(ctx context.Context, symbol string) (DepthSnapshot, error)
+}
+
+var _ DepthSnapshotter = (*RESTSnapshotter)(nil)
+
+// RESTSnapshotter fetches order books from the depth endpoint of binance REST API
+type RESTSnapshotter struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewRESTSnapshotter requests baseURL like https://api.binance.com
+func NewRESTSnapshotter(client *http.Client, baseURL string) *RESTSnapshotter {
+	return &RESTSnapshotter{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type restDepth struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// Snapshot implements DepthSnapshotter.
+func (r *RESTSnapshotter) Snapshot(ctx context.Context, symbol string) (DepthSnapshot, error) {
+	q := url.Values{}
+	q.Set("symbol", symbol)
+	q.Set("limit", strconv.Itoa(depthSnapshotLimit))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/api/v3/depth?"+q.Encode(), nil)
+	if err != nil {
+		return DepthSnapshot{}, err
+	}
+	res, err := r.client.Do(req)
+	if err != nil {
+		return DepthSnapshot{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return DepthSnapshot{}, fmt.Errorf("%w: status %d", ErrSnapshotFailed, res.StatusCode)
+	}
+	var depth restDepth
+	if err := json.NewDecoder(res.Body).Decode(&depth); err != nil {
+		return DepthSnapshot{}, err
+	}
+
+	snap := DepthSnapshot{LastUpdateID: depth.LastUpdateID}
+	for _, l := range depth.Bids {
+		snap.Bids = append(snap.Bids, bconn.Bid{Price: l[0], Quantity: l[1]})
+	}
+	for _, l := range depth.Asks {
+		snap.Asks = append(snap.Asks, bconn.Ask{Price: l[0], Quantity: l[1]})
+	}
+	return snap, nil
+}
+
+type bookLevel struct {
+	price float64
+	qty   float64
+	p     string // price as binance formats it
+}
+
+// orderBook is the local order book of a symbol, a snapshot kept up to date by diff-depth updates
+type orderBook struct {
+	lastUpdateID int64
+	synced       bool        // an update was applied on top of the snapshot
+	bids         []bookLevel // best first
+	asks         []bookLevel
+}
+
+func newOrderBook(snap DepthSnapshot) *orderBook {
+	b := &orderBook{lastUpdateID: snap.LastUpdateID}
+	for _, l := range snap.Bids {
+		b.bids = setLevel(b.bids, l, bidBetter)
+	}
+	for _, l := range snap.Asks {
+		b.asks = setLevel(b.asks, l, askBetter)
+	}
+	return b
+}
+
+func bidBetter(a, b float64) bool { return a > b }
+func askBetter(a, b float64) bool { return a < b }
+
+// apply applies e on the book, updates the book is already past are ignored. The first update applied on
+// the snapshot has to straddle its update id, every later one has to follow the previous update
+func (b *orderBook) apply(e *bconn.WsDepthEvent) error {
+	if e.LastUpdateID <= b.lastUpdateID {
+		return nil
+	}
+	if (b.synced && e.FirstUpdateID != b.lastUpdateID+1) || (!b.synced && e.FirstUpdateID > b.lastUpdateID+1) {
+		return fmt.Errorf("%w: update %d-%d after %d", ErrBookOutOfSync, e.FirstUpdateID, e.LastUpdateID, b.lastUpdateID)
+	}
+	for _, l := range e.Bids {
+		b.bids = setLevel(b.bids, l, bidBetter)
+	}
+	for _, l := range e.Asks {
+		b.asks = setLevel(b.asks, l, askBetter)
+	}
+	b.lastUpdateID, b.synced = e.LastUpdateID, true
+	return nil
+}
+
+// setLevel sets the quantity of a price level in levels ordered by better, a zero quantity removes the level
+func setLevel(levels []bookLevel, l bconn.PriceLevel, better func(a, b float64) bool) []bookLevel {
+	price, err := strconv.ParseFloat(l.Price, 64)
+	if err != nil {
+		return levels
+	}
+	qty, err := strconv.ParseFloat(l.Quantity, 64)
+	if err != nil {
+		return levels
+	}
+	i := sort.Search(len(levels), func(i int) bool { return !better(levels[i].price, price) })
+	exists := i < len(levels) && levels[i].price == price
+	switch {
+	case exists && qty == 0:
+		return append(levels[:i], levels[i+1:]...)
+	case exists:
+		levels[i].qty = qty
+		return levels
+	case qty == 0:
+		return levels
+	default:
+		levels = append(levels, bookLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = bookLevel{price: price, qty: qty, p: l.Price}
+		return levels
+	}
+}
+
+// imbalance is (bids - asks) / (bids + asks) of quantities of the top n levels of each side
+func (b *orderBook) imbalance(n int) float64 {
+	var bids, asks float64
+	for _, l := range b.bids[:min(n, len(b.bids))] {
+		bids += l.qty
+	}
+	for _, l := range b.asks[:min(n, len(b.asks))] {
+		asks += l.qty
+	}
+	if bids+asks == 0 {
+		return 0
+	}
+	return (bids - asks) / (bids + asks)
+}
+
+// BookBar sums up the order book of a symbol over an interval, the book is sampled whenever it changes
+// and once more at the open of the interval
+type BookBar struct {
+	Symbol     string  `json:"symbol"`
+	OpenTime   int64   `json:"open_time"` // unix seconds
+	Bid        OHLCBar `json:"bid"`       // best bid, T is the time of its latest sample
+	Ask        OHLCBar `json:"ask"`       // best ask
+	Mid        float64 `json:"mid"`       // at the close
+	Spread     float64 `json:"spread"`    // at the close
+	MeanSpread float64 `json:"mean_spread"`
+	Imbalance  float64 `json:"imbalance"` // of the top levels at the close, from 1 for only bids to -1 for only asks
+	Samples    int64   `json:"samples"`
+	Resyncs    int64   `json:"resyncs"` // times the book fell out of sync and was fetched again
+}
+
+func (bar *BookBar) sample(book *orderBook, levels int, now int64) {
+	if len(book.bids) == 0 || len(book.asks) == 0 {
+		return
+	}
+	bid, ask := book.bids[0], book.asks[0]
+	if bar.Samples == 0 {
+		bar.Bid = OHLCBar{O: bid.p, H: bid.p, L: bid.p, C: bid.p}
+		bar.Ask = OHLCBar{O: ask.p, H: ask.p, L: ask.p, C: ask.p}
+	}
+	updateQuote(&bar.Bid, bid.p, now)
+	updateQuote(&bar.Ask, ask.p, now)
+	bar.Mid = (bid.price + ask.price) / 2
+	bar.Spread = ask.price - bid.price
+	bar.MeanSpread += (bar.Spread - bar.MeanSpread) / float64(bar.Samples+1)
+	bar.Imbalance = book.imbalance(levels)
+	bar.Samples++
+}
+
+func updateQuote(bar *OHLCBar, price string, now int64) {
+	if priceLess(bar.H, price) {
+		bar.H = price
+	}
+	if priceLess(price, bar.L) {
+		bar.L = price
+	}
+	bar.C, bar.T = price, now
+}
+
+// DepthOptions tune DepthAggr, zero values fall back to the defaults
+type DepthOptions struct {
+	// Levels of each side the imbalance is taken over, DefaultBookLevels if it's 0
+	Levels int
+	// Interval of book bars, a minute if it's 0
+	Interval time.Duration
+	// StreamURL is the websocket endpoint of diff-depth streams, BinanceStreamURL if it's empty
+	StreamURL string
+	// Clock aligns and closes book bars, the wall clock if it's nil
+	Clock Clock
+}
+
+func (o DepthOptions) withDefaults() DepthOptions {
+	if o.Levels <= 0 {
+		o.Levels = DefaultBookLevels
+	}
+	if o.Interval <= 0 {
+		o.Interval = Interval1M
+	}
+	if o.StreamURL == "" {
+		o.StreamURL = BinanceStreamURL
+	}
+	o.Clock = orSystemClock(o.Clock)
+	return o
+}
+
+// DepthAggr keeps a local order book of every symbol from binance diff-depth updates, and sums them up
+// into book bars. Updates are buffered until a snapshot of the book is fetched, and the book is fetched
+// again whenever an update doesn't follow the previous one. Books are dropped when the stream ends by itself,
+// no bars are built until it's redialed and they are fetched again
+type DepthAggr struct {
+	logger    logr.Logger
+	symbols   []string
+	snapshots DepthSnapshotter
+	opts      DepthOptions
+	// serve opens a connection streaming diff-depth updates of symbols, swapped in tests
+	serve func(symbols []string, handler func(*bconn.WsDepthEvent), errHandler func(error)) (doneCh, stopCh chan struct{}, err error)
+}
+
+// depthSymbol is the state of a symbol, only used by the goroutine of Stream
+type depthSymbol struct {
+	book     *orderBook // nil while out of sync
+	buffer   []*bconn.WsDepthEvent
+	fetching bool
+	bar      *BookBar // nil until the book is synced
+}
+
+type depthSnapshot struct {
+	symbol string
+	snap   DepthSnapshot
+}
+
+func NewDepthAggr(logger logr.Logger, symbols []string, snapshots DepthSnapshotter, opts DepthOptions) *DepthAggr {
+	opts = opts.withDefaults()
+	return &DepthAggr{
+		logger:    logger,
+		symbols:   symbols,
+		snapshots: snapshots,
+		opts:      opts,
+		serve: func(symbols []string, handler func(*bconn.WsDepthEvent), errHandler func(error)) (chan struct{}, chan struct{}, error) {
+			return bconn.NewWebsocketStreamClient(true, opts.StreamURL).WsCombinedDepthServe(symbols, handler, errHandler)
+		},
+	}
+}
+
+// Symbols are the symbols whose order books are kept
+func (d *DepthAggr) Symbols() []string {
+	return d.symbols
+}
+
+// Stream streams diff-depth updates of the symbols and returns book bars as they close, the stream
+// is closed once done is closed
+func (d *DepthAggr) Stream(done <-chan struct{}) (<-chan BookBar, error) {
+	events := make(chan *bconn.WsDepthEvent, 100)
+	dial := func() (streamConn, error) {
+		doneCh, stopCh, err := d.serve(
+			d.symbols,
+			func(e *bconn.WsDepthEvent) {
+				select {
+				case events <- e:
+				case <-done:
+				}
+			},
+			func(err error) {
+				d.logger.Error(err, "depth stream error")
+			},
+		)
+		if err != nil {
+			return streamConn{}, err
+		}
+		d.logger.Info("subscribed", "symbols", d.symbols)
+		return streamConn{doneCh: doneCh, stopCh: stopCh}, nil
+	}
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	barCh := make(chan BookBar, 100)
+	snapCh := make(chan depthSnapshot)
+	// the ticker is created before the goroutine starts, so a fake clock advanced right after returning hits it
+	ticker := d.opts.Clock.NewTicker(time.Second)
+	go func() {
+		defer close(barCh)
+		defer ticker.Stop()
+		defer func() { conn.stop() }()
+
+		states := map[string]*depthSymbol{}
+		for _, symbol := range d.symbols {
+			states[symbol] = &depthSymbol{}
+		}
+		emit := func(bar BookBar) bool {
+			select {
+			case barCh <- bar:
+				return true
+			case <-done:
+				return false
+			}
+		}
+		streamDone := conn.doneCh
+		var redialed <-chan streamConn
+		for {
+			select {
+			case <-done:
+				return
+			case <-streamDone:
+				d.logger.Error(ErrConnectionLost, "depth stream ended, books are dropped until it's redialed", "symbols", d.symbols)
+				streamDone = nil
+				for _, st := range states {
+					st.book, st.buffer = nil, nil
+					if st.bar != nil {
+						st.bar.Resyncs++
+					}
+				}
+				redialed = redialStream(d.logger.WithValues("symbols", d.symbols), d.opts.Clock, done, dial)
+			case conn = <-redialed:
+				streamDone, redialed = conn.doneCh, nil
+			case now := <-ticker.C():
+				for symbol, st := range states {
+					if bar, ok := d.roll(symbol, st, now); ok && !emit(bar) {
+						return
+					}
+				}
+			case e := <-events:
+				st, ok := states[e.Symbol]
+				if !ok {
+					d.logger.V(2).Error(ErrNotHanlderFound, "unsupported symbol", "symbol", e.Symbol)
+					continue
+				}
+				if bar, ok := d.roll(e.Symbol, st, d.opts.Clock.Now()); ok && !emit(bar) {
+					return
+				}
+				d.update(done, e, st, snapCh)
+			case s := <-snapCh:
+				st := states[s.symbol]
+				if streamDone == nil {
+					// fetched before the stream ended, updates after it are gone
+					st.fetching = false
+					continue
+				}
+				if bar, ok := d.roll(s.symbol, st, d.opts.Clock.Now()); ok && !emit(bar) {
+					return
+				}
+				d.resync(done, s, st, snapCh)
+			}
+		}
+	}()
+	return barCh, nil
+}
+
+// update applies e on the book of its symbol, or buffers it until the book is synced
+func (d *DepthAggr) update(done <-chan struct{}, e *bconn.WsDepthEvent, st *depthSymbol, snapCh chan<- depthSnapshot) {
+	d.logger.V(4).Info("incoming depth update", "symbol", e.Symbol, "first_id", e.FirstUpdateID, "last_id", e.LastUpdateID)
+	if st.book == nil {
+		st.buffer = append(st.buffer, e)
+		if len(st.buffer) > maxBufferedDepth {
+			st.buffer = st.buffer[len(st.buffer)-maxBufferedDepth:]
+		}
+		d.fetch(done, e.Symbol, st, snapCh)
+		return
+	}
+	if err := st.book.apply(e); err != nil {
+		d.logger.Info("order book out of sync, fetching it again", "symbol", e.Symbol, "err", err)
+		st.book, st.buffer = nil, []*bconn.WsDepthEvent{e}
+		if st.bar != nil {
+			st.bar.Resyncs++
+		}
+		d.fetch(done, e.Symbol, st, snapCh)
+		return
+	}
+	st.bar.sample(st.book, d.opts.Levels, d.opts.Clock.Now().Unix())
+}
+
+// resync rebuilds the book of a symbol from s and the updates buffered meanwhile, the snapshot is fetched
+// again if the buffered updates don't follow it
+func (d *DepthAggr) resync(done <-chan struct{}, s depthSnapshot, st *depthSymbol, snapCh chan<- depthSnapshot) {
+	st.fetching = false
+	book := newOrderBook(s.snap)
+	for i, e := range st.buffer {
+		if err := book.apply(e); err != nil {
+			d.logger.Info("snapshot doesn't match buffered depth updates, fetching it again", "symbol", s.symbol, "err", err)
+			st.buffer = st.buffer[i:]
+			d.fetch(done, s.symbol, st, snapCh)
+			return
+		}
+	}
+	d.logger.V(2).Info("order book synced", "symbol", s.symbol, "last_update_id", book.lastUpdateID, "buffered", len(st.buffer))
+	st.book, st.buffer = book, nil
+	now := d.opts.Clock.Now()
+	if st.bar == nil {
+		st.bar = &BookBar{Symbol: s.symbol, OpenTime: now.Truncate(d.opts.Interval).Unix()}
+	}
+	st.bar.sample(book, d.opts.Levels, now.Unix())
+}
+
+// roll closes the bar of a symbol once its interval is over at now, the next bar opens with the book as of now
+func (d *DepthAggr) roll(symbol string, st *depthSymbol, now time.Time) (BookBar, bool) {
+	if st.bar == nil || now.Unix() < st.bar.OpenTime+int64(d.opts.Interval.Seconds()) {
+		return BookBar{}, false
+	}
+	closed := *st.bar
+	st.bar = &BookBar{Symbol: symbol, OpenTime: now.Truncate(d.opts.Interval).Unix()}
+	if st.book != nil {
+		st.bar.sample(st.book, d.opts.Levels, now.Unix())
+	}
+	if closed.Samples == 0 {
+		return BookBar{}, false
+	}
+	d.logger.V(2).Info("book bar closed", "bar", closed)
+	return closed, true
+}
+
+// fetch fetches the snapshot of symbol in the background unless it's being fetched, attempts are repeated
+// until one succeeds
+func (d *DepthAggr) fetch(done <-chan struct{}, symbol string, st *depthSymbol, snapCh chan<- depthSnapshot) {
+	if st.fetching {
+		return
+	}
+	st.fetching = true
+	go func() {
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+			snap, err := d.snapshots.Snapshot(ctx, symbol)
+			cancel()
+			if err == nil {
+				select {
+				case snapCh <- depthSnapshot{symbol: symbol, snap: snap}:
+				case <-done:
+				}
+				return
+			}
+			d.logger.Error(err, "unable to fetch depth snapshot", "symbol", symbol)
+			select {
+			case <-d.opts.Clock.After(snapshotRetryDelay):
+			case <-done:
+				return
+			}
+		}
+	}()
+}
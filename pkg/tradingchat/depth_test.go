@@ -0,0 +1,344 @@
+package tradingchat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func levels(prices ...string) []bconn.PriceLevel {
+	var ls []bconn.PriceLevel
+	for i := 0; i+1 < len(prices); i += 2 {
+		ls = append(ls, bconn.PriceLevel{Price: prices[i], Quantity: prices[i+1]})
+	}
+	return ls
+}
+
+func depthUpdate(first, last int64, bids, asks []bconn.PriceLevel) *bconn.WsDepthEvent {
+	return &bconn.WsDepthEvent{Symbol: "BNBBTC", FirstUpdateID: first, LastUpdateID: last, Bids: bids, Asks: asks}
+}
+
+func bestPrices(ls []bookLevel) []string {
+	var prices []string
+	for _, l := range ls {
+		prices = append(prices, l.p)
+	}
+	return prices
+}
+
+func TestOrderBook(t *testing.T) {
+	snap := DepthSnapshot{
+		LastUpdateID: 100,
+		Bids:         levels("0.0110", "5", "0.0109", "3"),
+		Asks:         levels("0.0112", "4", "0.0111", "2"),
+	}
+
+	t.Run("snapshot should be ordered best first", func(t *testing.T) {
+		book := newOrderBook(snap)
+		assert.Equal(t, []string{"0.0110", "0.0109"}, bestPrices(book.bids))
+		assert.Equal(t, []string{"0.0111", "0.0112"}, bestPrices(book.asks))
+		assert.InDelta(t, 2.0/14, book.imbalance(10), 1e-9)
+		assert.InDelta(t, 3.0/7, book.imbalance(1), 1e-9)
+	})
+
+	t.Run("updates the snapshot contains already should be ignored", func(t *testing.T) {
+		book := newOrderBook(snap)
+		assert.NoError(t, book.apply(depthUpdate(95, 100, levels("0.0110", "0"), nil)))
+		assert.Equal(t, []string{"0.0110", "0.0109"}, bestPrices(book.bids))
+		assert.False(t, book.synced)
+	})
+
+	t.Run("first update should straddle the snapshot", func(t *testing.T) {
+		book := newOrderBook(snap)
+		assert.ErrorIs(t, book.apply(depthUpdate(102, 103, nil, nil)), ErrBookOutOfSync)
+		assert.NoError(t, book.apply(depthUpdate(99, 101, nil, nil)))
+		assert.True(t, book.synced)
+	})
+
+	t.Run("levels should be set, added and removed", func(t *testing.T) {
+		book := newOrderBook(snap)
+		assert.NoError(t, book.apply(depthUpdate(101, 101,
+			levels("0.0110", "1", "0.01105", "2", "0.0108", "0"),
+			levels("0.0111", "0", "0.0113", "6"),
+		)))
+		assert.Equal(t, []string{"0.01105", "0.0110", "0.0109"}, bestPrices(book.bids))
+		assert.Equal(t, []string{"0.0112", "0.0113"}, bestPrices(book.asks))
+		assert.Equal(t, 1.0, book.bids[1].qty)
+	})
+
+	t.Run("later updates should follow the previous one", func(t *testing.T) {
+		book := newOrderBook(snap)
+		assert.NoError(t, book.apply(depthUpdate(99, 101, nil, nil)))
+		assert.ErrorIs(t, book.apply(depthUpdate(103, 104, nil, nil)), ErrBookOutOfSync)
+		assert.NoError(t, book.apply(depthUpdate(102, 102, nil, nil)))
+		assert.Equal(t, int64(102), book.lastUpdateID)
+	})
+}
+
+// fakeSnapshotter returns its snapshots in order, one per call
+type fakeSnapshotter struct {
+	mu    sync.Mutex
+	snaps []DepthSnapshot
+}
+
+func (f *fakeSnapshotter) Snapshot(_ context.Context, _ string) (DepthSnapshot, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	snap := f.snaps[0]
+	f.snaps = f.snaps[1:]
+	return snap, nil
+}
+
+func TestDepthAggrResync(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	clock := NewFakeClock(time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC))
+	snaps := &fakeSnapshotter{snaps: []DepthSnapshot{
+		{LastUpdateID: 101, Bids: levels("0.0110", "5"), Asks: levels("0.0112", "5")},
+		// older than the update out of sync
+		{LastUpdateID: 105, Bids: levels("0.0110", "5"), Asks: levels("0.0112", "5")},
+		{LastUpdateID: 112, Bids: levels("0.0108", "3"), Asks: levels("0.0113", "1")},
+	}}
+	d := NewDepthAggr(logger, []string{"BNBBTC"}, snaps, DepthOptions{Clock: clock, Levels: 1})
+	done := make(chan struct{})
+	defer close(done)
+	snapCh := make(chan depthSnapshot)
+	st := &depthSymbol{}
+
+	d.update(done, depthUpdate(99, 100, levels("0.0120", "1"), nil), st, snapCh)
+	d.update(done, depthUpdate(101, 102, nil, levels("0.0111", "1")), st, snapCh)
+	assert.Nil(t, st.book)
+	assert.Len(t, st.buffer, 2)
+	assert.True(t, st.fetching)
+
+	d.resync(done, <-snapCh, st, snapCh)
+	if assert.NotNil(t, st.book) {
+		assert.Equal(t, []string{"0.0110"}, bestPrices(st.book.bids), "updates before the snapshot are dropped")
+		assert.Equal(t, []string{"0.0111", "0.0112"}, bestPrices(st.book.asks))
+	}
+	assert.Empty(t, st.buffer)
+
+	d.update(done, depthUpdate(103, 103, levels("0.0109", "1", "0.0110", "0"), nil), st, snapCh)
+	d.update(done, depthUpdate(110, 111, levels("0.0108", "3"), nil), st, snapCh)
+	assert.Nil(t, st.book)
+	assert.Len(t, st.buffer, 1)
+
+	d.resync(done, <-snapCh, st, snapCh)
+	assert.Nil(t, st.book, "snapshot older than the buffered update is fetched again")
+	assert.True(t, st.fetching)
+	d.resync(done, <-snapCh, st, snapCh)
+	assert.NotNil(t, st.book)
+
+	_, ok := d.roll("BNBBTC", st, clock.Now())
+	assert.False(t, ok)
+	clock.Advance(time.Minute)
+	bar, ok := d.roll("BNBBTC", st, clock.Now())
+	assert.True(t, ok)
+	assert.Equal(t, BookBar{
+		Symbol:     "BNBBTC",
+		OpenTime:   1737734700,
+		Bid:        OHLCBar{O: "0.0110", H: "0.0110", L: "0.0108", C: "0.0108", T: 1737734700},
+		Ask:        OHLCBar{O: "0.0111", H: "0.0113", L: "0.0111", C: "0.0113", T: 1737734700},
+		Mid:        bar.Mid,
+		Spread:     bar.Spread,
+		MeanSpread: bar.MeanSpread,
+		Imbalance:  0.5,
+		Samples:    3,
+		Resyncs:    1,
+	}, bar)
+	assert.InDelta(t, 0.01105, bar.Mid, 1e-9)
+	assert.InDelta(t, 0.0005, bar.Spread, 1e-9)
+	assert.InDelta(t, (0.0001+0.0002+0.0005)/3, bar.MeanSpread, 1e-9)
+
+	assert.Equal(t, int64(1737734760), st.bar.OpenTime)
+	assert.Equal(t, int64(1), st.bar.Samples, "next bar opens with the book")
+}
+
+// depthStandIn serves snapshots of BNBBTC and a combined diff-depth stream sending updates
+// once a snapshot was served, until quit is closed
+func depthStandIn(t *testing.T, snap restDepth, updates []map[string]any, quit <-chan struct{}) *httptest.Server {
+	served := make(chan struct{})
+	once := &sync.Once{}
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/depth", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "BNBBTC", r.URL.Query().Get("symbol"))
+		json.NewEncoder(w).Encode(snap)
+		once.Do(func() { close(served) })
+	})
+	mux.HandleFunc("/stream", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bnbbtc@depth", r.URL.Query().Get("streams"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		for i, u := range updates {
+			u["e"], u["E"], u["s"] = "depthUpdate", 1737734701000, "BNBBTC"
+			conn.WriteJSON(map[string]any{"stream": "bnbbtc@depth", "data": u})
+			if i == 0 {
+				<-served
+			}
+		}
+		<-quit
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestDepthAggrStandIn(t *testing.T) {
+	quit := make(chan struct{})
+	srv := depthStandIn(t,
+		restDepth{LastUpdateID: 100, Bids: [][2]string{{"0.0110", "5"}}, Asks: [][2]string{{"0.0112", "5"}}},
+		[]map[string]any{
+			{"U": 95, "u": 99, "b": [][2]string{}, "a": [][2]string{}},
+			{"U": 100, "u": 101, "b": [][2]string{{"0.0111", "5"}}, "a": [][2]string{}},
+			{"U": 102, "u": 102, "b": [][2]string{}, "a": [][2]string{{"0.0112", "0"}, {"0.0113", "15"}}},
+		},
+		quit,
+	)
+	defer srv.Close()
+
+	clock := NewFakeClock(time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC))
+	d := NewDepthAggr(logr.Discard(), []string{"BNBBTC"}, NewRESTSnapshotter(srv.Client(), srv.URL), DepthOptions{
+		StreamURL: "ws" + strings.TrimPrefix(srv.URL, "http"),
+		Clock:     clock,
+	})
+	// the connector races on its own state when stopped while reading, so the stand-in hangs up first
+	serve, connDone := d.serve, make(chan chan struct{}, 1)
+	d.serve = func(symbols []string, handler func(*bconn.WsDepthEvent), errHandler func(error)) (chan struct{}, chan struct{}, error) {
+		doneCh, stopCh, err := serve(symbols, handler, errHandler)
+		connDone <- doneCh
+		return doneCh, stopCh, err
+	}
+	done := make(chan struct{})
+	defer close(done)
+	bars, err := d.Stream(done)
+	assert.NoError(t, err)
+
+	// the book is sampled at the open of every bar, so bars eventually close with the book after every update
+	var bar BookBar
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Minute)
+		select {
+		case bar = <-bars:
+			return bar.Bid.C == "0.0111" && bar.Ask.C == "0.0113"
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, "BNBBTC", bar.Symbol)
+	assert.InDelta(t, 0.0112, bar.Mid, 1e-9)
+	assert.InDelta(t, 0.0002, bar.Spread, 1e-9)
+	assert.InDelta(t, -0.2, bar.Imbalance, 1e-9)
+
+	close(quit)
+	select {
+	case <-<-connDone:
+	case <-time.After(5 * time.Second):
+		t.Error("stream didn't stop after the stand-in hung up")
+	}
+}
+
+func TestDepthAggrRedial(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	clock := NewFakeClock(time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC))
+	snaps := &fakeSnapshotter{snaps: []DepthSnapshot{
+		{LastUpdateID: 101, Bids: levels("0.0110", "5"), Asks: levels("0.0112", "5")},
+		{LastUpdateID: 201, Bids: levels("0.0100", "5"), Asks: levels("0.0102", "5")},
+	}}
+	d := NewDepthAggr(logger, []string{"BNBBTC"}, snaps, DepthOptions{Clock: clock, Levels: 1})
+
+	var mu sync.Mutex
+	var handlers []func(*bconn.WsDepthEvent)
+	var kills []chan struct{}
+	failing := false
+	d.serve = func(_ []string, handler func(*bconn.WsDepthEvent), _ func(error)) (chan struct{}, chan struct{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failing {
+			return nil, nil, ErrConnectionLost
+		}
+		doneCh, stopCh, kill := make(chan struct{}), make(chan struct{}), make(chan struct{})
+		handlers, kills = append(handlers, handler), append(kills, kill)
+		go func() {
+			select {
+			case <-stopCh:
+			case <-kill:
+			}
+			close(doneCh)
+		}()
+		return doneCh, stopCh, nil
+	}
+	conn := func(i int) (func(*bconn.WsDepthEvent), chan struct{}, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		if i >= len(handlers) {
+			return nil, nil, false
+		}
+		return handlers[i], kills[i], true
+	}
+	setFailing := func(f bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		failing = f
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	bars, err := d.Stream(done)
+	assert.NoError(t, err)
+	// advances the clock a minute at a time until a bar matches
+	barUntil := func(match func(BookBar) bool) BookBar {
+		var bar BookBar
+		assert.Eventually(t, func() bool {
+			clock.Advance(time.Minute)
+			select {
+			case bar = <-bars:
+				return match(bar)
+			case <-time.After(10 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second, time.Millisecond)
+		return bar
+	}
+
+	handler, kill, _ := conn(0)
+	handler(depthUpdate(101, 102, nil, levels("0.0111", "1")))
+	bar := barUntil(func(bar BookBar) bool { return bar.Samples > 0 })
+	assert.Equal(t, "0.0111", bar.Ask.C)
+
+	setFailing(true)
+	close(kill)
+	bar = barUntil(func(bar BookBar) bool { return bar.Resyncs == 1 })
+	assert.Equal(t, "0.0111", bar.Ask.C, "the bar in progress closes with the book it had")
+	for range 3 {
+		clock.Advance(time.Minute)
+		select {
+		case bar := <-bars:
+			t.Fatalf("bar built while the stream is down: %+v", bar)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	setFailing(false)
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		_, _, ok := conn(1)
+		return ok
+	}, 5*time.Second, time.Millisecond, "stream is not redialed")
+	handler, _, _ = conn(1)
+	handler(depthUpdate(201, 202, levels("0.0101", "1"), nil))
+	bar = barUntil(func(bar BookBar) bool { return bar.Samples > 0 })
+	assert.Equal(t, "0.0101", bar.Bid.C, "book is fetched again")
+	assert.Equal(t, "0.0102", bar.Ask.C)
+}
@@ -0,0 +1,144 @@
+package tradingchat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+const recoveryPageSize = 1000
+
+var ErrRecoveryFailed = errors.New("unable to recover missing trades")
+
+// Gap is a range of aggregated trade ids [FromID, ToID] of a symbol that never arrived,
+// missing trades were traded between Start and End in unix seconds
+type Gap struct {
+	Symbol    string `json:"symbol"`
+	FromID    int64  `json:"from_id"`
+	ToID      int64  `json:"to_id"`
+	Start     int64  `json:"start"`
+	End       int64  `json:"end"`
+	Recovered bool   `json:"recovered"` // every missing trade was recovered into the bar in progress
+}
+
+// GapRecoverer fetches missing trades of a gap, trades may be returned partially
+type GapRecoverer interface {
+	Recover(ctx context.Context, gap Gap) ([]*bconn.WsAggTradeEvent, error)
+}
+
+// tradeIDs tracks the last aggregated trade id of a symbol, trades without id are not tracked
+type tradeIDs struct {
+	lastID   int64
+	lastTime int64 // milliseconds
+}
+
+func (t *tradeIDs) track(event *bconn.WsAggTradeEvent) {
+	if event.AggTradeID > t.lastID {
+		t.lastID = event.AggTradeID
+		t.lastTime = event.TradeTime
+	}
+}
+
+// seen tells if a trade of event's id was aggregated already
+func (t *tradeIDs) seen(event *bconn.WsAggTradeEvent) bool {
+	return event.AggTradeID > 0 && event.AggTradeID <= t.lastID
+}
+
+// gap reports trades missing between the last trade aggregated and event, aggregated trade ids are contiguous per symbol
+func (t *tradeIDs) gap(event *bconn.WsAggTradeEvent) (Gap, bool) {
+	if t.lastID == 0 || event.AggTradeID <= t.lastID+1 {
+		return Gap{}, false
+	}
+	return Gap{
+		Symbol: event.Symbol,
+		FromID: t.lastID + 1,
+		ToID:   event.AggTradeID - 1,
+		Start:  t.lastTime / 1000,
+		End:    tradeUnix(event),
+	}, true
+}
+
+var _ GapRecoverer = (*RESTRecoverer)(nil)
+
+// RESTRecoverer fetches missing trades from the aggTrades endpoint of binance REST API
+type RESTRecoverer struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewRESTRecoverer requests baseURL like https://api.binance.com
+func NewRESTRecoverer(client *http.Client, baseURL string) *RESTRecoverer {
+	return &RESTRecoverer{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type restAggTrade struct {
+	ID           int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	FirstID      int64  `json:"f"`
+	LastID       int64  `json:"l"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+}
+
+// Recover implements GapRecoverer.
+func (r *RESTRecoverer) Recover(ctx context.Context, gap Gap) ([]*bconn.WsAggTradeEvent, error) {
+	var trades []*bconn.WsAggTradeEvent
+	for from := gap.FromID; from <= gap.ToID; {
+		q := url.Values{}
+		q.Set("symbol", gap.Symbol)
+		q.Set("fromId", strconv.FormatInt(from, 10))
+		q.Set("limit", strconv.FormatInt(min(recoveryPageSize, gap.ToID-from+1), 10))
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/api/v3/aggTrades?"+q.Encode(), nil)
+		if err != nil {
+			return trades, err
+		}
+		res, err := r.client.Do(req)
+		if err != nil {
+			return trades, err
+		}
+		var page []restAggTrade
+		if res.StatusCode != http.StatusOK {
+			res.Body.Close()
+			return trades, fmt.Errorf("%w: status %d", ErrRecoveryFailed, res.StatusCode)
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return trades, err
+		}
+		if len(page) == 0 {
+			return trades, nil
+		}
+
+		for _, t := range page {
+			if t.ID > gap.ToID {
+				return trades, nil
+			}
+			trades = append(trades, &bconn.WsAggTradeEvent{
+				Event:                 "aggTrade",
+				Time:                  t.TradeTime,
+				Symbol:                gap.Symbol,
+				AggTradeID:            t.ID,
+				Price:                 t.Price,
+				Quantity:              t.Quantity,
+				FirstBreakdownTradeID: t.FirstID,
+				LastBreakdownTradeID:  t.LastID,
+				TradeTime:             t.TradeTime,
+				IsBuyerMaker:          t.IsBuyerMaker,
+			})
+		}
+		from = page[len(page)-1].ID + 1
+	}
+	return trades, nil
+}
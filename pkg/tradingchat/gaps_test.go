@@ -0,0 +1,200 @@
+package tradingchat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+// binanceStandIn serves aggTrades of ids in trades from fromId, limit trades a page at most
+func binanceStandIn(t *testing.T, trades []restAggTrade) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/aggTrades", r.URL.Path)
+		assert.Equal(t, "BNBBTC", r.URL.Query().Get("symbol"))
+		from, _ := strconv.ParseInt(r.URL.Query().Get("fromId"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		page := []restAggTrade{}
+		for _, tr := range trades {
+			if tr.ID >= from && len(page) < limit && len(page) < 2 {
+				page = append(page, tr)
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+}
+
+func runAggr(t *testing.T, events []*bconn.WsAggTradeEvent, recoverer GapRecoverer) (*Aggr, []Gap) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	done := make(chan struct{})
+	defer close(done)
+
+	stream := make(chan *bconn.WsAggTradeEvent)
+	go func() {
+		defer close(stream)
+		for _, e := range events {
+			stream <- e
+		}
+	}()
+
+	aggr, updateCh, gapCh := NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{Recoverer: recoverer})
+	for range updateCh {
+	}
+	var gaps []Gap
+	for gap := range gapCh {
+		gaps = append(gaps, gap)
+	}
+	return aggr, gaps
+}
+
+func TestRESTRecoverer(t *testing.T) {
+	srv := binanceStandIn(t, []restAggTrade{
+		{ID: 2, Price: "0.11121", TradeTime: 1737734711000},
+		{ID: 3, Price: "0.11104", TradeTime: 1737734759000},
+		{ID: 4, Price: "0.11101", TradeTime: 1737734760000},
+		{ID: 5, Price: "0.11131", TradeTime: 1737734761000},
+	})
+	defer srv.Close()
+
+	t.Run("missing trades should be fetched page by page", func(t *testing.T) {
+		trades, err := NewRESTRecoverer(srv.Client(), srv.URL+"/").Recover(context.Background(), Gap{Symbol: "BNBBTC", FromID: 2, ToID: 4})
+		assert.NoError(t, err)
+		ids := []int64{}
+		for _, tr := range trades {
+			ids = append(ids, tr.AggTradeID)
+		}
+		assert.Equal(t, []int64{2, 3, 4}, ids)
+		assert.Equal(t, int64(1737734759000), trades[1].TradeTime)
+		assert.Equal(t, "BNBBTC", trades[1].Symbol)
+	})
+
+	t.Run("error status should fail recovery", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer down.Close()
+		_, err := NewRESTRecoverer(down.Client(), down.URL).Recover(context.Background(), Gap{Symbol: "BNBBTC", FromID: 2, ToID: 4})
+		assert.ErrorIs(t, err, ErrRecoveryFailed)
+	})
+}
+
+func TestAggrGaps(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	events := []*bconn.WsAggTradeEvent{
+		{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: 1737734701000},
+		{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: 1737734701000},
+		{Symbol: "BNBBTC", AggTradeID: 4, Price: "0.11115", TradeTime: 1737734740000},
+	}
+
+	t.Run("missing ids should be reported and bar marked incomplete", func(t *testing.T) {
+		aggr, gaps := runAggr(t, events, nil)
+		assert.Equal(t, []Gap{{Symbol: "BNBBTC", FromID: 2, ToID: 3, Start: 1737734701, End: 1737734740}}, gaps)
+
+		bar, err := aggr.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11115", L: "0.11111", O: "0.11111", C: "0.11115", T: 1737734740, Incomplete: true}, bar)
+	})
+
+	t.Run("recovered trades should complete the bar", func(t *testing.T) {
+		srv := binanceStandIn(t, []restAggTrade{
+			{ID: 2, Price: "0.11121", TradeTime: 1737734711000},
+			{ID: 3, Price: "0.11104", TradeTime: 1737734720000},
+		})
+		defer srv.Close()
+
+		aggr, gaps := runAggr(t, events, NewRESTRecoverer(srv.Client(), srv.URL))
+		assert.Equal(t, []Gap{{Symbol: "BNBBTC", FromID: 2, ToID: 3, Start: 1737734701, End: 1737734740, Recovered: true}}, gaps)
+
+		bar, err := aggr.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11115", T: 1737734740}, bar)
+	})
+
+	t.Run("partially recovered gap should leave bar incomplete", func(t *testing.T) {
+		srv := binanceStandIn(t, []restAggTrade{
+			{ID: 2, Price: "0.11121", TradeTime: 1737734711000},
+			{ID: 3, Price: "0.11104", TradeTime: 1737734762000},
+		})
+		defer srv.Close()
+
+		aggr, gaps := runAggr(t, []*bconn.WsAggTradeEvent{
+			{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: 1737734701000},
+			{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11101", TradeTime: 1737734761000},
+			{Symbol: "BNBBTC", AggTradeID: 5, Price: "0.11131", TradeTime: 1737734765000},
+		}, NewRESTRecoverer(srv.Client(), srv.URL))
+		assert.Len(t, gaps, 1)
+		assert.False(t, gaps[0].Recovered)
+
+		bar, err := aggr.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11131", L: "0.11101", O: "0.11101", C: "0.11131", T: 1737734765, Incomplete: true}, bar)
+	})
+	t.Run("recovery should not hold back trades after the gap", func(t *testing.T) {
+		logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+		done := make(chan struct{})
+		defer close(done)
+		stream := make(chan *bconn.WsAggTradeEvent)
+		var aggr *Aggr
+		recoverer := recoverFunc(func(ctx context.Context, gap Gap) ([]*bconn.WsAggTradeEvent, error) {
+			// trades after the gap are aggregated while it's being recovered
+			assert.Eventually(t, func() bool {
+				st, _ := aggr.State("BNBBTC")
+				return st.LastID == 5
+			}, time.Second, time.Millisecond)
+			return []*bconn.WsAggTradeEvent{
+				{Symbol: "BNBBTC", AggTradeID: 3, Price: "0.11104", TradeTime: 1737734720000},
+				{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", TradeTime: 1737734711000},
+				{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", TradeTime: 1737734711000},
+			}, nil
+		})
+		aggr, updateCh, gapCh := NewAggrStreamWithOptions(logger, done, stream, []string{"BNBBTC"}, AggrOptions{Recoverer: recoverer})
+		go func() {
+			defer close(stream)
+			for _, e := range append(events, &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 5, Price: "0.11113", TradeTime: 1737734745000}) {
+				stream <- e
+			}
+		}()
+		for range updateCh {
+		}
+		var gaps []Gap
+		for gap := range gapCh {
+			gaps = append(gaps, gap)
+		}
+		assert.Equal(t, []Gap{{Symbol: "BNBBTC", FromID: 2, ToID: 3, Start: 1737734701, End: 1737734740, Recovered: true}}, gaps)
+
+		bar, err := aggr.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11113", T: 1737734745}, bar, "recovered trades don't take over the close")
+	})
+
+	t.Run("bars the missing trades may have traded within should be marked", func(t *testing.T) {
+		ag := newAggr(testr.New(t), []string{"BNBBTC"}, 1, nil)
+		sa, _ := ag.lookup("BNBBTC")
+		sa.apply(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: 1737734701000})
+		gap := Gap{Symbol: "BNBBTC", FromID: 2, ToID: 3, Start: 1737734701, End: 1737734765}
+
+		assert.True(t, sa.markGap(gap), "the gap started in the bar in progress")
+		assert.True(t, sa.calc.Bar().Incomplete)
+		sa.apply(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 4, Price: "0.11115", TradeTime: 1737734765000})
+		assert.False(t, sa.calc.Bar().Incomplete)
+		assert.True(t, sa.markGap(gap), "the gap ended in the next bar")
+		assert.True(t, sa.calc.Bar().Incomplete)
+
+		assert.False(t, sa.markGap(Gap{Symbol: "BNBBTC", FromID: 2, ToID: 3, Start: 1737734701, End: 1737734720}), "the gap is within the closed bar")
+		assert.Equal(t, 1, sa.missing)
+	})
+}
+
+type recoverFunc func(ctx context.Context, gap Gap) ([]*bconn.WsAggTradeEvent, error)
+
+func (f recoverFunc) Recover(ctx context.Context, gap Gap) ([]*bconn.WsAggTradeEvent, error) {
+	return f(ctx, gap)
+}
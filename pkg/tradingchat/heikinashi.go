@@ -0,0 +1,93 @@
+package tradingchat
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HeikinAshiCalc transforms a series of bars into Heikin-Ashi bars, updates of the bar in progress
+// are transformed from the Heikin-Ashi bar before it until a newer bar arrives
+type HeikinAshiCalc struct {
+	open      time.Time // open time of the bar in progress
+	started   bool
+	hasPrev   bool
+	prevOpen  float64
+	prevClose float64
+	curOpen   float64
+	curClose  float64
+}
+
+func NewHeikinAshiCalc() *HeikinAshiCalc {
+	return &HeikinAshiCalc{}
+}
+
+// Update returns the Heikin-Ashi bar of bar, prices keep the precision of bar. The first bar opens
+// halfway between its own open and close
+func (h *HeikinAshiCalc) Update(bar OHLCBar) (OHLCBar, error) {
+	var o, hi, l, c float64
+	for _, v := range []struct {
+		s   string
+		dst *float64
+	}{
+		{bar.O, &o},
+		{bar.H, &hi},
+		{bar.L, &l},
+		{bar.C, &c},
+	} {
+		f, err := strconv.ParseFloat(v.s, 64)
+		if err != nil {
+			return OHLCBar{}, err
+		}
+		*v.dst = f
+	}
+
+	open := bar.OpenTime()
+	if h.started && open.After(h.open) {
+		h.prevOpen, h.prevClose, h.hasPrev = h.curOpen, h.curClose, true
+	}
+	h.open, h.started = open, true
+
+	haClose := (o + hi + l + c) / 4
+	haOpen := (o + c) / 2
+	if h.hasPrev {
+		haOpen = (h.prevOpen + h.prevClose) / 2
+	}
+	h.curOpen, h.curClose = haOpen, haClose
+
+	decimals := max(decimalsOf(bar.O), decimalsOf(bar.H), decimalsOf(bar.L), decimalsOf(bar.C))
+	format := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', decimals, 64)
+	}
+	return OHLCBar{
+		H:          format(math.Max(hi, math.Max(haOpen, haClose))),
+		L:          format(math.Min(l, math.Min(haOpen, haClose))),
+		O:          format(haOpen),
+		C:          format(haClose),
+		T:          bar.T,
+		Incomplete: bar.Incomplete,
+	}, nil
+}
+
+// HeikinAshi transforms bars sorted by time into Heikin-Ashi bars
+func HeikinAshi(bars []OHLCBar) ([]OHLCBar, error) {
+	h := NewHeikinAshiCalc()
+	res := make([]OHLCBar, 0, len(bars))
+	for _, bar := range bars {
+		ha, err := h.Update(bar)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ha)
+	}
+	return res, nil
+}
+
+// decimalsOf counts digits after the decimal point of price
+func decimalsOf(price string) int {
+	if i := strings.IndexByte(price, '.'); i >= 0 {
+		return len(price) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package tradingchat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeikinAshi(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+
+	t.Run("bars should be transformed from the bar before them", func(t *testing.T) {
+		bars, err := HeikinAshi([]OHLCBar{
+			{O: "10", H: "14", L: "8", C: "12", T: open + 59},
+			{O: "12", H: "13", L: "10", C: "11", T: open + 119},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []OHLCBar{
+			// close (10+14+8+12)/4, open (10+12)/2
+			{O: "11", H: "14", L: "8", C: "11", T: open + 59},
+			// close (12+13+10+11)/4, open (11+11)/2
+			{O: "11", H: "13", L: "10", C: "12", T: open + 119},
+		}, bars)
+	})
+
+	t.Run("updates of the bar in progress should keep the bar before it", func(t *testing.T) {
+		h := NewHeikinAshiCalc()
+		h.Update(OHLCBar{O: "1.00", H: "1.00", L: "1.00", C: "1.00", T: open + 10})
+		h.Update(OHLCBar{O: "1.00", H: "1.04", L: "1.00", C: "1.02", T: open + 20})
+
+		ha, err := h.Update(OHLCBar{O: "1.02", H: "1.02", L: "1.02", C: "1.02", T: open + 60})
+		assert.NoError(t, err)
+		// open halfway between 1.01 and 1.015 of the 16:05 bar
+		assert.Equal(t, OHLCBar{O: "1.01", H: "1.02", L: "1.01", C: "1.02", T: open + 60}, ha)
+
+		ha, err = h.Update(OHLCBar{O: "1.02", H: "1.06", L: "0.98", C: "1.04", T: open + 70, Incomplete: true})
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{O: "1.01", H: "1.06", L: "0.98", C: "1.02", T: open + 70, Incomplete: true}, ha)
+	})
+
+	t.Run("malformed prices should fail", func(t *testing.T) {
+		_, err := HeikinAshi([]OHLCBar{{O: "x", H: "1", L: "1", C: "1", T: open}})
+		assert.Error(t, err)
+	})
+}
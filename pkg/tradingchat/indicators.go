@@ -0,0 +1,477 @@
+package tradingchat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidIndicator = errors.New("invalid indicator")
+
+// IndicatorKind names a technical indicator
+type IndicatorKind string
+
+const (
+	IndicatorSMA       IndicatorKind = "sma"
+	IndicatorEMA       IndicatorKind = "ema"
+	IndicatorRSI       IndicatorKind = "rsi"
+	IndicatorMACD      IndicatorKind = "macd"
+	IndicatorBollinger IndicatorKind = "bollinger"
+)
+
+const (
+	// longest period of an indicator, longer ones would need too many bars to warm up
+	MaxIndicatorPeriod = 1000
+
+	defaultMACDFast   = 12
+	defaultMACDSlow   = 26
+	defaultMACDSignal = 9
+	defaultBollingerK = 2
+)
+
+// IndicatorSpec configures an indicator computed on closes of bars at Interval. Period is used by SMA, EMA, RSI
+// and Bollinger, Fast, Slow and Signal by MACD, K is the width of Bollinger bands in standard deviations.
+// Zero values of MACD and Bollinger parameters fall back to 12, 26, 9 and 2
+type IndicatorSpec struct {
+	Kind     IndicatorKind
+	Interval time.Duration
+	Period   int
+	Fast     int
+	Slow     int
+	Signal   int
+	K        float64
+	// Live indicators are also computed on bars in progress
+	Live bool
+}
+
+// withDefaults fills parameters left zero
+func (s IndicatorSpec) withDefaults() IndicatorSpec {
+	if s.Interval == 0 {
+		s.Interval = Interval1M
+	}
+	switch s.Kind {
+	case IndicatorMACD:
+		if s.Fast == 0 {
+			s.Fast = defaultMACDFast
+		}
+		if s.Slow == 0 {
+			s.Slow = defaultMACDSlow
+		}
+		if s.Signal == 0 {
+			s.Signal = defaultMACDSignal
+		}
+	case IndicatorBollinger:
+		if s.K == 0 {
+			s.K = defaultBollingerK
+		}
+	}
+	return s
+}
+
+// Name identifies the indicator in updates, e.g. sma_20_1m or macd_12_26_9_1h
+func (s IndicatorSpec) Name() string {
+	s = s.withDefaults()
+	interval := shortDuration(s.Interval)
+	switch s.Kind {
+	case IndicatorMACD:
+		return fmt.Sprintf("%s_%d_%d_%d_%s", s.Kind, s.Fast, s.Slow, s.Signal, interval)
+	case IndicatorBollinger:
+		return fmt.Sprintf("%s_%d_%s_%s", s.Kind, s.Period, strconv.FormatFloat(s.K, 'f', -1, 64), interval)
+	default:
+		return fmt.Sprintf("%s_%d_%s", s.Kind, s.Period, interval)
+	}
+}
+
+// warmup is the number of closed bars the indicator needs to settle
+func (s IndicatorSpec) warmup() int {
+	s = s.withDefaults()
+	switch s.Kind {
+	case IndicatorEMA, IndicatorRSI:
+		// smoothed indicators depend on every bar, the weight of older ones fades away
+		return 3*s.Period + 1
+	case IndicatorMACD:
+		return 3*s.Slow + s.Signal
+	default:
+		return s.Period
+	}
+}
+
+func (s IndicatorSpec) Validate() error {
+	s = s.withDefaults()
+	if s.Interval < Interval1M || s.Interval%Interval1M != 0 {
+		return fmt.Errorf("%w: interval %v is not a whole number of minutes", ErrInvalidIndicator, s.Interval)
+	}
+	switch s.Kind {
+	case IndicatorSMA, IndicatorEMA, IndicatorRSI, IndicatorBollinger:
+		if s.Period < 1 || s.Period > MaxIndicatorPeriod {
+			return fmt.Errorf("%w: %s period must be within 1 and %d", ErrInvalidIndicator, s.Kind, MaxIndicatorPeriod)
+		}
+		if s.Kind == IndicatorBollinger && s.K <= 0 {
+			return fmt.Errorf("%w: bollinger width must be positive", ErrInvalidIndicator)
+		}
+	case IndicatorMACD:
+		if s.Fast < 1 || s.Slow > MaxIndicatorPeriod || s.Signal < 1 || s.Signal > MaxIndicatorPeriod || s.Fast >= s.Slow {
+			return fmt.Errorf("%w: macd periods must be within 1 and %d with fast shorter than slow", ErrInvalidIndicator, MaxIndicatorPeriod)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidIndicator, s.Kind)
+	}
+	return nil
+}
+
+// IndicatorValue is the value of an indicator at the close of the bar opened at OpenTime. Values of bars in
+// progress aren't Closed, and values of indicators without enough bars yet aren't Ready
+type IndicatorValue struct {
+	Name     string
+	OpenTime time.Time
+	Closed   bool
+	Ready    bool
+	Values   map[string]float64
+}
+
+// Indicator is computed incrementally on closes of bars
+type Indicator interface {
+	// Push adds the close of a closed bar
+	Push(close float64) (values map[string]float64, ready bool)
+	// Peek computes the indicator as if close was pushed, without changing it
+	Peek(close float64) (values map[string]float64, ready bool)
+}
+
+func NewIndicator(spec IndicatorSpec) (Indicator, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	spec = spec.withDefaults()
+	switch spec.Kind {
+	case IndicatorSMA:
+		return &sma{w: newWindow(spec.Period)}, nil
+	case IndicatorEMA:
+		return &ema{e: newEMAState(spec.Period)}, nil
+	case IndicatorRSI:
+		return &rsi{period: spec.Period}, nil
+	case IndicatorMACD:
+		return &macd{
+			fast:   newEMAState(spec.Fast),
+			slow:   newEMAState(spec.Slow),
+			signal: newEMAState(spec.Signal),
+		}, nil
+	default:
+		return &bollinger{w: newWindow(spec.Period), k: spec.K}, nil
+	}
+}
+
+// IndicatorSet computes indicators of a symbol, every indicator rolls 1m bars up to its own interval,
+// and a bar of an interval closes once a bar of the next one arrives. A set lives as long as the subscription
+// it belongs to, a new one is warmed up by Warm with stored bars or recent bars the aggregation checkpoints
+type IndicatorSet struct {
+	specs      []IndicatorSpec
+	indicators []Indicator
+	opens      []time.Time // open time of the bar in progress of every indicator
+	closes     []float64   // close of the bar in progress of every indicator
+}
+
+func NewIndicatorSet(specs []IndicatorSpec) (*IndicatorSet, error) {
+	set := &IndicatorSet{
+		specs:      make([]IndicatorSpec, 0, len(specs)),
+		indicators: make([]Indicator, 0, len(specs)),
+		opens:      make([]time.Time, len(specs)),
+		closes:     make([]float64, len(specs)),
+	}
+	for _, spec := range specs {
+		ind, err := NewIndicator(spec)
+		if err != nil {
+			return nil, err
+		}
+		set.specs = append(set.specs, spec.withDefaults())
+		set.indicators = append(set.indicators, ind)
+	}
+	return set, nil
+}
+
+// Warmup is the number of closed bars of every interval the set needs to settle
+func (set *IndicatorSet) Warmup() map[time.Duration]int {
+	warmup := map[time.Duration]int{}
+	for _, spec := range set.specs {
+		warmup[spec.Interval] = max(warmup[spec.Interval], spec.warmup())
+	}
+	return warmup
+}
+
+// Warm pushes closes of bars at interval that closed before now, bars are sorted by time
+func (set *IndicatorSet) Warm(interval time.Duration, bars []OHLCBar, now time.Time) {
+	current := now.Truncate(interval)
+	for i, spec := range set.specs {
+		if spec.Interval != interval {
+			continue
+		}
+		for _, bar := range bars {
+			open := time.Unix(bar.T, 0).Truncate(interval)
+			if !open.Before(current) || !open.After(set.opens[i]) {
+				continue
+			}
+			c, err := strconv.ParseFloat(bar.C, 64)
+			if err != nil {
+				continue
+			}
+			set.indicators[i].Push(c)
+			set.opens[i] = open
+		}
+	}
+}
+
+// Update takes the latest 1m bar in progress, and returns values of indicators whose bar closed
+// and of live indicators
+func (set *IndicatorSet) Update(bar OHLCBar) ([]IndicatorValue, error) {
+	c, err := strconv.ParseFloat(bar.C, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []IndicatorValue
+	for i, spec := range set.specs {
+		open := time.Unix(bar.T, 0).Truncate(spec.Interval)
+		if open.Before(set.opens[i]) {
+			// late update of a closed bar
+			continue
+		}
+		if open.After(set.opens[i]) {
+			if set.closes[i] != 0 {
+				values, ready := set.indicators[i].Push(set.closes[i])
+				res = append(res, IndicatorValue{Name: spec.Name(), OpenTime: set.opens[i], Closed: true, Ready: ready, Values: values})
+			}
+			set.opens[i] = open
+		}
+		set.closes[i] = c
+
+		if spec.Live {
+			values, ready := set.indicators[i].Peek(c)
+			res = append(res, IndicatorValue{Name: spec.Name(), OpenTime: open, Ready: ready, Values: values})
+		}
+	}
+	return res, nil
+}
+
+// window keeps the sum and the sum of squares of the latest closes
+type window struct {
+	vals  []float64
+	next  int
+	count int
+	sum   float64
+	sumSq float64
+}
+
+func newWindow(n int) *window {
+	return &window{vals: make([]float64, n)}
+}
+
+func (w *window) push(x float64) {
+	w.sum, w.sumSq, w.count = w.with(x)
+	w.vals[w.next] = x
+	w.next = (w.next + 1) % len(w.vals)
+}
+
+// with returns sums and count of the window as if x was pushed
+func (w *window) with(x float64) (sum, sumSq float64, count int) {
+	sum, sumSq, count = w.sum+x, w.sumSq+x*x, w.count+1
+	if w.count == len(w.vals) {
+		old := w.vals[w.next]
+		sum, sumSq, count = sum-old, sumSq-old*old, w.count
+	}
+	return sum, sumSq, count
+}
+
+func (w *window) full(count int) bool {
+	return count == len(w.vals)
+}
+
+type sma struct {
+	w *window
+}
+
+// Push implements Indicator.
+func (s *sma) Push(close float64) (map[string]float64, bool) {
+	s.w.push(close)
+	return s.value(s.w.sum, s.w.count)
+}
+
+// Peek implements Indicator.
+func (s *sma) Peek(close float64) (map[string]float64, bool) {
+	sum, _, count := s.w.with(close)
+	return s.value(sum, count)
+}
+
+func (s *sma) value(sum float64, count int) (map[string]float64, bool) {
+	return map[string]float64{"value": sum / float64(count)}, s.w.full(count)
+}
+
+// emaState is an exponential moving average seeded with the simple average of its first period values
+type emaState struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+func newEMAState(period int) emaState {
+	return emaState{period: period, alpha: 2 / float64(period+1)}
+}
+
+func (e emaState) next(x float64) emaState {
+	e.count++
+	if e.count <= e.period {
+		e.value += (x - e.value) / float64(e.count)
+	} else {
+		e.value += e.alpha * (x - e.value)
+	}
+	return e
+}
+
+func (e emaState) ready() bool {
+	return e.count >= e.period
+}
+
+type ema struct {
+	e emaState
+}
+
+// Push implements Indicator.
+func (e *ema) Push(close float64) (map[string]float64, bool) {
+	e.e = e.e.next(close)
+	return map[string]float64{"value": e.e.value}, e.e.ready()
+}
+
+// Peek implements Indicator.
+func (e *ema) Peek(close float64) (map[string]float64, bool) {
+	next := e.e.next(close)
+	return map[string]float64{"value": next.value}, next.ready()
+}
+
+// rsi uses Wilder's smoothing, averages of the first period changes are simple ones
+type rsi struct {
+	period  int
+	count   int // changes seen
+	prev    float64
+	started bool
+	gain    float64
+	loss    float64
+}
+
+func (r rsi) next(x float64) rsi {
+	if !r.started {
+		r.prev, r.started = x, true
+		return r
+	}
+	change := x - r.prev
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+	r.prev = x
+	r.count++
+	if r.count <= r.period {
+		r.gain += (gain - r.gain) / float64(r.count)
+		r.loss += (loss - r.loss) / float64(r.count)
+	} else {
+		n := float64(r.period)
+		r.gain = (r.gain*(n-1) + gain) / n
+		r.loss = (r.loss*(n-1) + loss) / n
+	}
+	return r
+}
+
+func (r rsi) value() (map[string]float64, bool) {
+	v := 50.0
+	switch {
+	case r.loss == 0 && r.gain > 0:
+		v = 100
+	case r.loss > 0:
+		v = 100 - 100/(1+r.gain/r.loss)
+	}
+	return map[string]float64{"value": v}, r.count >= r.period
+}
+
+// Push implements Indicator.
+func (r *rsi) Push(close float64) (map[string]float64, bool) {
+	*r = r.next(close)
+	return r.value()
+}
+
+// Peek implements Indicator.
+func (r *rsi) Peek(close float64) (map[string]float64, bool) {
+	return r.next(close).value()
+}
+
+// macd is the difference of a fast and a slow EMA, the signal line is an EMA of the difference
+// once the slow EMA is ready
+type macd struct {
+	fast   emaState
+	slow   emaState
+	signal emaState
+}
+
+func (m macd) next(x float64) macd {
+	m.fast, m.slow = m.fast.next(x), m.slow.next(x)
+	if m.slow.ready() {
+		m.signal = m.signal.next(m.fast.value - m.slow.value)
+	}
+	return m
+}
+
+func (m macd) value() (map[string]float64, bool) {
+	line := m.fast.value - m.slow.value
+	return map[string]float64{
+		"macd":      line,
+		"signal":    m.signal.value,
+		"histogram": line - m.signal.value,
+	}, m.signal.ready()
+}
+
+// Push implements Indicator.
+func (m *macd) Push(close float64) (map[string]float64, bool) {
+	*m = m.next(close)
+	return m.value()
+}
+
+// Peek implements Indicator.
+func (m *macd) Peek(close float64) (map[string]float64, bool) {
+	return m.next(close).value()
+}
+
+// bollinger bands are k population standard deviations around the simple moving average
+type bollinger struct {
+	w *window
+	k float64
+}
+
+// Push implements Indicator.
+func (b *bollinger) Push(close float64) (map[string]float64, bool) {
+	b.w.push(close)
+	return b.value(b.w.sum, b.w.sumSq, b.w.count)
+}
+
+// Peek implements Indicator.
+func (b *bollinger) Peek(close float64) (map[string]float64, bool) {
+	return b.value(b.w.with(close))
+}
+
+func (b *bollinger) value(sum, sumSq float64, count int) (map[string]float64, bool) {
+	n := float64(count)
+	mean := sum / n
+	std := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+	return map[string]float64{
+		"upper":  mean + b.k*std,
+		"middle": mean,
+		"lower":  mean - b.k*std,
+	}, b.w.full(count)
+}
+
+// shortDuration formats intervals like 1m, 5m, 1h and 1d
+func shortDuration(d time.Duration) string {
+	switch {
+	case d%Interval1D == 0:
+		return fmt.Sprintf("%dd", d/Interval1D)
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
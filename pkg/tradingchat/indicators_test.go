@@ -0,0 +1,130 @@
+package tradingchat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndicators(t *testing.T) {
+	push := func(t *testing.T, spec IndicatorSpec, closes ...float64) ([]map[string]float64, []bool) {
+		ind, err := NewIndicator(spec)
+		assert.NoError(t, err)
+		var values []map[string]float64
+		var ready []bool
+		for _, c := range closes {
+			peeked, peekedReady := ind.Peek(c)
+			v, r := ind.Push(c)
+			assert.Equal(t, peeked, v, "peek should not differ from push")
+			assert.Equal(t, peekedReady, r)
+			values = append(values, v)
+			ready = append(ready, r)
+		}
+		return values, ready
+	}
+
+	t.Run("sma should average the latest period closes", func(t *testing.T) {
+		values, ready := push(t, IndicatorSpec{Kind: IndicatorSMA, Period: 3}, 1, 2, 3, 4, 5)
+		assert.Equal(t, []bool{false, false, true, true, true}, ready)
+		assert.Equal(t, []float64{2, 3, 4}, []float64{values[2]["value"], values[3]["value"], values[4]["value"]})
+	})
+
+	t.Run("ema should be seeded with the sma of its first period closes", func(t *testing.T) {
+		values, ready := push(t, IndicatorSpec{Kind: IndicatorEMA, Period: 3}, 1, 2, 3, 4, 5)
+		assert.Equal(t, []bool{false, false, true, true, true}, ready)
+		assert.Equal(t, []float64{2, 3, 4}, []float64{values[2]["value"], values[3]["value"], values[4]["value"]})
+	})
+
+	t.Run("rsi should smooth gains and losses", func(t *testing.T) {
+		values, ready := push(t, IndicatorSpec{Kind: IndicatorRSI, Period: 2}, 1, 2, 3, 2)
+		assert.Equal(t, []bool{false, false, true, true}, ready)
+		assert.Equal(t, 100.0, values[2]["value"])
+		assert.Equal(t, 50.0, values[3]["value"])
+	})
+
+	t.Run("macd should be the difference of its emas", func(t *testing.T) {
+		values, ready := push(t, IndicatorSpec{Kind: IndicatorMACD, Fast: 2, Slow: 3, Signal: 2}, 1, 2, 3, 4, 5, 6)
+		assert.Equal(t, []bool{false, false, false, true, true, true}, ready)
+		assert.InDelta(t, 0.5, values[5]["macd"], 1e-9)
+		assert.InDelta(t, 0.5, values[5]["signal"], 1e-9)
+		assert.InDelta(t, 0, values[5]["histogram"], 1e-9)
+	})
+
+	t.Run("bollinger bands should be k standard deviations around the sma", func(t *testing.T) {
+		values, ready := push(t, IndicatorSpec{Kind: IndicatorBollinger, Period: 2}, 5, 1, 3)
+		assert.Equal(t, []bool{false, true, true}, ready)
+		assert.Equal(t, map[string]float64{"upper": 4, "middle": 2, "lower": 0}, values[2])
+	})
+
+	t.Run("invalid specs should be rejected", func(t *testing.T) {
+		for _, spec := range []IndicatorSpec{
+			{Kind: "vwap", Period: 3},
+			{Kind: IndicatorSMA},
+			{Kind: IndicatorEMA, Period: MaxIndicatorPeriod + 1},
+			{Kind: IndicatorMACD, Fast: 26, Slow: 12},
+			{Kind: IndicatorRSI, Period: 14, Interval: 90 * time.Second},
+		} {
+			_, err := NewIndicator(spec)
+			assert.ErrorIs(t, err, ErrInvalidIndicator, spec)
+		}
+	})
+
+	t.Run("names should tell parameters and interval", func(t *testing.T) {
+		assert.Equal(t, "sma_20_1m", IndicatorSpec{Kind: IndicatorSMA, Period: 20}.Name())
+		assert.Equal(t, "macd_12_26_9_1h", IndicatorSpec{Kind: IndicatorMACD, Interval: Interval1H}.Name())
+		assert.Equal(t, "bollinger_20_2.5_5m", IndicatorSpec{Kind: IndicatorBollinger, Period: 20, K: 2.5, Interval: Interval5M}.Name())
+	})
+}
+
+func TestIndicatorSet(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+
+	t.Run("indicators should be updated on bar close, and on bars in progress if live", func(t *testing.T) {
+		set, err := NewIndicatorSet([]IndicatorSpec{
+			{Kind: IndicatorSMA, Period: 2},
+			{Kind: IndicatorSMA, Period: 2, Interval: Interval5M, Live: true},
+		})
+		assert.NoError(t, err)
+
+		values, err := set.Update(OHLCBar{C: "1", T: open + 10})
+		assert.NoError(t, err)
+		assert.Equal(t, []IndicatorValue{
+			{Name: "sma_2_5m", OpenTime: time.Unix(open, 0), Values: map[string]float64{"value": 1}},
+		}, values)
+
+		set.Update(OHLCBar{C: "2", T: open + 20})
+		// the 16:05 bar closed at 2
+		values, _ = set.Update(OHLCBar{C: "4", T: open + 60})
+		assert.Equal(t, []IndicatorValue{
+			{Name: "sma_2_1m", OpenTime: time.Unix(open, 0), Closed: true, Values: map[string]float64{"value": 2}},
+			{Name: "sma_2_5m", OpenTime: time.Unix(open, 0), Values: map[string]float64{"value": 4}},
+		}, values)
+
+		// the 16:06 bar closed at 4, the 16:05 5m bar closed at 4
+		values, _ = set.Update(OHLCBar{C: "6", T: open + 300})
+		assert.Equal(t, []IndicatorValue{
+			{Name: "sma_2_1m", OpenTime: time.Unix(open+60, 0), Closed: true, Ready: true, Values: map[string]float64{"value": 3}},
+			{Name: "sma_2_5m", OpenTime: time.Unix(open, 0), Closed: true, Values: map[string]float64{"value": 4}},
+			{Name: "sma_2_5m", OpenTime: time.Unix(open+300, 0), Ready: true, Values: map[string]float64{"value": 5}},
+		}, values)
+	})
+
+	t.Run("warmed indicators should be ready with the first closed bar", func(t *testing.T) {
+		set, err := NewIndicatorSet([]IndicatorSpec{{Kind: IndicatorSMA, Period: 3}})
+		assert.NoError(t, err)
+		assert.Equal(t, map[time.Duration]int{Interval1M: 3}, set.Warmup())
+
+		set.Warm(Interval1M, []OHLCBar{
+			{C: "1", T: open - 120},
+			{C: "2", T: open - 60},
+			{C: "9", T: open}, // still in progress
+		}, time.Unix(open+30, 0))
+		set.Update(OHLCBar{C: "3", T: open + 30})
+		values, _ := set.Update(OHLCBar{C: "5", T: open + 60})
+		assert.Equal(t, []IndicatorValue{
+			{Name: "sma_3_1m", OpenTime: time.Unix(open, 0), Closed: true, Ready: true, Values: map[string]float64{"value": 2}},
+		}, values)
+	})
+}
@@ -0,0 +1,52 @@
+package tradingchat
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	Interval5M = time.Minute * 5
+	Interval1H = time.Hour
+	Interval1D = time.Hour * 24
+)
+
+// Rollup merges bars sorted by time into bars of a longer interval, each merged bar
+// keeps the newest time of the bars it is made of
+func Rollup(bars []OHLCBar, interval time.Duration) []OHLCBar {
+	var res []OHLCBar
+	var bucket time.Time
+	for _, bar := range bars {
+		t := time.Unix(bar.T, 0).Truncate(interval)
+		if len(res) == 0 || !t.Equal(bucket) {
+			bucket = t
+			res = append(res, bar)
+			continue
+		}
+
+		mergeBar(&res[len(res)-1], bar)
+	}
+	return res
+}
+
+// mergeBar merges bar into last, bar is the later one
+func mergeBar(last *OHLCBar, bar OHLCBar) {
+	if priceLess(last.H, bar.H) {
+		last.H = bar.H
+	}
+	if priceLess(bar.L, last.L) {
+		last.L = bar.L
+	}
+	last.C = bar.C
+	last.T = bar.T
+}
+
+// priceLess compares prices by value, so that "0.9" < "0.10" doesn't hold
+func priceLess(a, b string) bool {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return fa < fb
+}
@@ -0,0 +1,43 @@
+package tradingchat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollup(t *testing.T) {
+	t.Run("1m bars should be merged into 5m bars", func(t *testing.T) {
+		// 16:05 on Jan 24th 2025
+		var inittime int64 = 1737734700
+		bars := []OHLCBar{
+			{H: "0.11121", L: "0.11101", O: "0.11111", C: "0.11120", T: inittime + 59},
+			{H: "0.11131", L: "0.11110", O: "0.11120", C: "0.11125", T: inittime + 61},
+			{H: "0.11124", L: "0.11099", O: "0.11125", C: "0.11100", T: inittime + 299},
+			{H: "0.11104", L: "0.11104", O: "0.11104", C: "0.11104", T: inittime + 300},
+		}
+
+		assert.Equal(t,
+			[]OHLCBar{
+				{H: "0.11131", L: "0.11099", O: "0.11111", C: "0.11100", T: inittime + 299},
+				{H: "0.11104", L: "0.11104", O: "0.11104", C: "0.11104", T: inittime + 300},
+			},
+			Rollup(bars, Interval5M),
+		)
+	})
+
+	t.Run("prices should be compared by value", func(t *testing.T) {
+		bars := []OHLCBar{
+			{H: "9.5", L: "9.5", O: "9.5", C: "9.5", T: 0},
+			{H: "10.1", L: "10.1", O: "10.1", C: "10.1", T: 1},
+		}
+		assert.Equal(t,
+			[]OHLCBar{{H: "10.1", L: "9.5", O: "9.5", C: "10.1", T: 1}},
+			Rollup(bars, Interval1H),
+		)
+	})
+
+	t.Run("empty input should produce no bars", func(t *testing.T) {
+		assert.Empty(t, Rollup(nil, Interval1D))
+	})
+}
@@ -0,0 +1,219 @@
+package tradingchat
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+// FeedStatusKind is what a FeedStatus reports
+type FeedStatusKind string
+
+const (
+	FeedStale   FeedStatusKind = "stale"   // a symbol didn't trade for much longer than it usually does
+	FeedLive    FeedStatusKind = "live"    // a stale symbol traded again
+	FeedSpike   FeedStatusKind = "spike"   // the price of a symbol jumped too many standard deviations of its recent returns
+	FeedStalled FeedStatusKind = "stalled" // no trade of any symbol arrived for a while, the stream may have stalled
+	FeedResumed FeedStatusKind = "resumed" // trades arrive again after a stall
+)
+
+// FeedStatus is an event of the health of the feed, Symbol is empty for stalls of the whole feed
+type FeedStatus struct {
+	Symbol    string
+	Kind      FeedStatusKind
+	At        time.Time
+	LastTrade time.Time     // arrival of the last trade before the event
+	Silence   time.Duration // time without trades, stale and stalled only
+	Expected  time.Duration // time without trades a symbol is stale after, stale and live only
+	Price     string        // spikes only
+	Deviation float64       // standard deviations of a spike
+}
+
+// MonitorOptions tune FeedMonitor, zero values fall back to the defaults
+type MonitorOptions struct {
+	// a symbol is stale once it didn't trade for StaleFactor times its mean time between trades, and MinStale at least
+	StaleFactor float64
+	MinStale    time.Duration
+	// the feed is stalled once no trade of any symbol arrived for FeedStale
+	FeedStale time.Duration
+	// a return further than SpikeStdDevs standard deviations from the mean of the last SpikeWindow returns is a spike
+	SpikeStdDevs float64
+	SpikeWindow  int
+	// how often liveness is checked
+	CheckEvery time.Duration
+}
+
+func (o MonitorOptions) withDefaults() MonitorOptions {
+	if o.StaleFactor <= 0 {
+		o.StaleFactor = 10
+	}
+	if o.MinStale <= 0 {
+		o.MinStale = 30 * time.Second
+	}
+	if o.FeedStale <= 0 {
+		o.FeedStale = time.Minute
+	}
+	if o.SpikeStdDevs <= 0 {
+		o.SpikeStdDevs = 6
+	}
+	if o.SpikeWindow < 2 {
+		o.SpikeWindow = 100
+	}
+	if o.CheckEvery <= 0 {
+		o.CheckEvery = 5 * time.Second
+	}
+	return o
+}
+
+// FeedMonitor tracks when trades of every symbol arrive and how their prices move. Liveness is judged by arrival
+// on the clock rather than trade time, so a silently stalled stream is noticed. Symbols are tracked from their first trade
+type FeedMonitor struct {
+	opts MonitorOptions
+
+	mu        *sync.Mutex
+	symbols   map[string]*symbolHealth
+	lastTrade time.Time
+	stalled   bool
+}
+
+type symbolHealth struct {
+	last     time.Time
+	interval float64 // moving average of seconds between trades, 0 until the second trade
+	stale    bool
+
+	price   float64
+	returns []float64 // ring of the latest log returns
+	next    int
+	sum     float64
+	sumSq   float64
+}
+
+func NewFeedMonitor(opts MonitorOptions) *FeedMonitor {
+	return &FeedMonitor{
+		opts:    opts.withDefaults(),
+		mu:      &sync.Mutex{},
+		symbols: map[string]*symbolHealth{},
+	}
+}
+
+// Observe tracks e arriving at now, and returns statuses it changed: the feed resumed, the symbol is live again
+// or its price spiked
+func (m *FeedMonitor) Observe(e *bconn.WsAggTradeEvent, now time.Time) []FeedStatus {
+	price, err := strconv.ParseFloat(e.Price, 64)
+	if err != nil || price <= 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []FeedStatus
+	if m.stalled {
+		res = append(res, FeedStatus{Kind: FeedResumed, At: now, LastTrade: m.lastTrade, Silence: now.Sub(m.lastTrade)})
+		m.stalled = false
+	}
+	m.lastTrade = now
+
+	h, ok := m.symbols[e.Symbol]
+	if !ok {
+		m.symbols[e.Symbol] = &symbolHealth{last: now, price: price, returns: make([]float64, 0, m.opts.SpikeWindow)}
+		return res
+	}
+	if h.stale {
+		res = append(res, FeedStatus{Symbol: e.Symbol, Kind: FeedLive, At: now, LastTrade: h.last, Silence: now.Sub(h.last), Expected: m.staleAfter(h)})
+		h.stale = false
+	}
+	gap := now.Sub(h.last).Seconds()
+	if h.interval == 0 {
+		h.interval = gap
+	} else {
+		h.interval += 0.05 * (gap - h.interval)
+	}
+	h.last = now
+
+	r := math.Log(price / h.price)
+	h.price = price
+	if dev, ok := h.deviation(r); ok && dev > m.opts.SpikeStdDevs {
+		res = append(res, FeedStatus{Symbol: e.Symbol, Kind: FeedSpike, At: now, LastTrade: now, Price: e.Price, Deviation: dev})
+	}
+	h.push(r, m.opts.SpikeWindow)
+	return res
+}
+
+// Check returns symbols that became stale, and whether the feed stalled, at now
+func (m *FeedMonitor) Check(now time.Time) []FeedStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var res []FeedStatus
+	if !m.stalled && !m.lastTrade.IsZero() && now.Sub(m.lastTrade) >= m.opts.FeedStale {
+		m.stalled = true
+		res = append(res, FeedStatus{Kind: FeedStalled, At: now, LastTrade: m.lastTrade, Silence: now.Sub(m.lastTrade)})
+	}
+	for symbol, h := range m.symbols {
+		if h.stale {
+			continue
+		}
+		if after := m.staleAfter(h); now.Sub(h.last) >= after {
+			h.stale = true
+			res = append(res, FeedStatus{Symbol: symbol, Kind: FeedStale, At: now, LastTrade: h.last, Silence: now.Sub(h.last), Expected: after})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Symbol < res[j].Symbol })
+	return res
+}
+
+// Stale lists symbols that are stale now
+func (m *FeedMonitor) Stale() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var stale []string
+	for symbol, h := range m.symbols {
+		if h.stale {
+			stale = append(stale, symbol)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// Forget stops tracking symbol, e.g. once it's removed
+func (m *FeedMonitor) Forget(symbol string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.symbols, symbol)
+}
+
+func (m *FeedMonitor) staleAfter(h *symbolHealth) time.Duration {
+	return max(m.opts.MinStale, time.Duration(m.opts.StaleFactor*h.interval*float64(time.Second)))
+}
+
+// deviation is how many standard deviations r is away from the mean of the window, once the window is full
+func (h *symbolHealth) deviation(r float64) (float64, bool) {
+	n := float64(len(h.returns))
+	if len(h.returns) < cap(h.returns) {
+		return 0, false
+	}
+	mean := h.sum / n
+	variance := h.sumSq/n - mean*mean
+	if variance <= 0 {
+		return 0, false
+	}
+	return math.Abs(r-mean) / math.Sqrt(variance), true
+}
+
+func (h *symbolHealth) push(r float64, window int) {
+	if len(h.returns) < window {
+		h.returns = append(h.returns, r)
+	} else {
+		old := h.returns[h.next]
+		h.sum -= old
+		h.sumSq -= old * old
+		h.returns[h.next] = r
+		h.next = (h.next + 1) % window
+	}
+	h.sum += r
+	h.sumSq += r * r
+}
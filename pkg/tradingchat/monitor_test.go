@@ -0,0 +1,89 @@
+package tradingchat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedMonitor(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	start := time.Unix(1737734700, 0)
+	trade := func(symbol, price string) *bconn.WsAggTradeEvent {
+		return &bconn.WsAggTradeEvent{Symbol: symbol, Price: price}
+	}
+
+	t.Run("symbols should be stale after many times their usual time between trades", func(t *testing.T) {
+		m := NewFeedMonitor(MonitorOptions{StaleFactor: 10, MinStale: 5 * time.Second, FeedStale: time.Hour})
+		for i := 0; i < 5; i++ {
+			m.Observe(trade("ETHBTC", "0.03"), start.Add(time.Duration(i)*time.Second))
+		}
+		last := start.Add(4 * time.Second)
+
+		assert.Empty(t, m.Check(last.Add(9*time.Second)))
+		statuses := m.Check(last.Add(10 * time.Second))
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, FeedStale, statuses[0].Kind)
+		assert.Equal(t, 10*time.Second, statuses[0].Expected)
+		assert.Equal(t, []string{"ETHBTC"}, m.Stale())
+		assert.Empty(t, m.Check(last.Add(20*time.Second)), "stale once")
+
+		statuses = m.Observe(trade("ETHBTC", "0.03"), last.Add(30*time.Second))
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, FeedLive, statuses[0].Kind)
+		assert.Equal(t, 30*time.Second, statuses[0].Silence)
+		assert.Empty(t, m.Stale())
+	})
+
+	t.Run("the feed should stall without any trade", func(t *testing.T) {
+		m := NewFeedMonitor(MonitorOptions{MinStale: time.Hour, FeedStale: time.Minute})
+		assert.Empty(t, m.Check(start.Add(time.Hour)), "nothing arrived yet")
+		m.Observe(trade("ETHBTC", "0.03"), start)
+
+		statuses := m.Check(start.Add(time.Minute))
+		assert.Equal(t, []FeedStatus{{Kind: FeedStalled, At: start.Add(time.Minute), LastTrade: start, Silence: time.Minute}}, statuses)
+		statuses = m.Observe(trade("BNBBTC", "0.01"), start.Add(2*time.Minute))
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, FeedResumed, statuses[0].Kind)
+	})
+
+	t.Run("returns far from recent ones should be spikes", func(t *testing.T) {
+		m := NewFeedMonitor(MonitorOptions{SpikeStdDevs: 4, SpikeWindow: 20})
+		now := start
+		for i := 0; i < 21; i++ {
+			now = now.Add(time.Second)
+			assert.Empty(t, m.Observe(trade("ETHBTC", fmt.Sprintf("0.03%d", i%2)), now))
+		}
+		statuses := m.Observe(trade("ETHBTC", "0.05"), now.Add(time.Second))
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, FeedSpike, statuses[0].Kind)
+		assert.Equal(t, "0.05", statuses[0].Price)
+		assert.Greater(t, statuses[0].Deviation, 4.0)
+	})
+}
+
+func TestAggrFeedStatuses(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	// 16:05 on Jan 24th 2025
+	clock := NewFakeClock(time.Unix(1737734700, 0))
+	done := make(chan struct{})
+	defer close(done)
+	stream := make(chan *bconn.WsAggTradeEvent)
+	ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"ETHBTC"}, AggrOptions{
+		Clock:   clock,
+		Monitor: NewFeedMonitor(MonitorOptions{MinStale: 10 * time.Second, FeedStale: time.Hour, CheckEvery: 5 * time.Second}),
+	})
+
+	stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", AggTradeID: 1, Price: "0.03", TradeTime: 1737734700000}
+	<-updateCh
+	clock.Advance(5 * time.Second)
+	clock.Advance(5 * time.Second)
+
+	st := <-ag.FeedStatuses()
+	assert.Equal(t, "ETHBTC", st.Symbol)
+	assert.Equal(t, FeedStale, st.Kind)
+}
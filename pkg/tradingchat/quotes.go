@@ -0,0 +1,257 @@
+package tradingchat
+
+import (
+	"strconv"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
+)
+
+// QuoteBar is the best bid and ask of a symbol over a minute, so symbols that rarely trade still have a price
+// series. A bar opens with the quote the previous bar closed with, bars start from the first quote of a symbol,
+// and start over from the first quote after the stream was down
+type QuoteBar struct {
+	Symbol     string  `json:"symbol"`
+	OpenTime   int64   `json:"open_time"`   // unix seconds
+	Bid        OHLCBar `json:"bid"`         // best bid, T is the time of its latest quote
+	Ask        OHLCBar `json:"ask"`         // best ask
+	MeanSpread float64 `json:"mean_spread"` // weighted by the time each spread lasted within the elapsed part of the minute
+	Quotes     int64   `json:"quotes"`      // quotes received within the minute
+}
+
+// QuoteUpdate is a quote bar in progress, or closed
+type QuoteUpdate struct {
+	Bar    QuoteBar
+	Closed bool
+}
+
+// RollupQuotes merges quote bars sorted by time into bars of a longer interval opening at its start,
+// quotes are summed up and mean spreads averaged over the minutes
+func RollupQuotes(bars []QuoteBar, interval time.Duration) []QuoteBar {
+	var res []QuoteBar
+	var minutes int
+	for _, bar := range bars {
+		open := time.Unix(bar.OpenTime, 0).Truncate(interval).Unix()
+		if len(res) == 0 || res[len(res)-1].OpenTime != open {
+			bar.OpenTime = open
+			res = append(res, bar)
+			minutes = 1
+			continue
+		}
+
+		last := &res[len(res)-1]
+		mergeBar(&last.Bid, bar.Bid)
+		mergeBar(&last.Ask, bar.Ask)
+		last.Quotes += bar.Quotes
+		minutes++
+		last.MeanSpread += (bar.MeanSpread - last.MeanSpread) / float64(minutes)
+	}
+	return res
+}
+
+// quoteCalc builds quote bars of a symbol, quotes arrive without a time so they are timed on arrival
+type quoteCalc struct {
+	bar     QuoteBar
+	lastID  int64
+	spread  float64
+	since   time.Time // start of the latest spread
+	from    time.Time // start of the spreads weighed, the open or the first quote of the first bar
+	area    float64   // spreads times the seconds they lasted, from until since
+	started bool      // a quote arrived
+	changed bool      // updated since the latest update was taken
+	halted  bool      // the stream ended, no bar opens after the one in progress
+}
+
+func (c *quoteCalc) update(e *bconn.WsBookTickerEvent, now time.Time) {
+	bid, errBid := strconv.ParseFloat(e.BestBidPrice, 64)
+	ask, errAsk := strconv.ParseFloat(e.BestAskPrice, 64)
+	// a side without orders is quoted as 0
+	if errBid != nil || errAsk != nil || bid <= 0 || ask <= 0 || (e.UpdateID > 0 && e.UpdateID <= c.lastID) {
+		return
+	}
+	c.lastID = e.UpdateID
+	c.halted = false
+	if !c.started {
+		c.started = true
+		c.bar = QuoteBar{
+			Symbol:   e.Symbol,
+			OpenTime: now.Truncate(Interval1M).Unix(),
+			Bid:      OHLCBar{O: e.BestBidPrice, H: e.BestBidPrice, L: e.BestBidPrice},
+			Ask:      OHLCBar{O: e.BestAskPrice, H: e.BestAskPrice, L: e.BestAskPrice},
+		}
+		c.since, c.from = now, now
+	}
+	c.area += c.spread * now.Sub(c.since).Seconds()
+	c.spread, c.since = ask-bid, now
+	updateQuote(&c.bar.Bid, e.BestBidPrice, now.Unix())
+	updateQuote(&c.bar.Ask, e.BestAskPrice, now.Unix())
+	c.bar.Quotes++
+	c.changed = true
+}
+
+// at is the bar in progress with its mean spread as of now
+func (c *quoteCalc) at(now time.Time) QuoteBar {
+	bar := c.bar
+	area := c.area + c.spread*now.Sub(c.since).Seconds()
+	if elapsed := now.Sub(c.from).Seconds(); elapsed > 0 {
+		bar.MeanSpread = area / elapsed
+	} else {
+		bar.MeanSpread = c.spread
+	}
+	return bar
+}
+
+// roll closes the bar once its minute is over at now, the next bar opens with the quote it closed with unless halted
+func (c *quoteCalc) roll(now time.Time) (QuoteBar, bool) {
+	end := time.Unix(c.bar.OpenTime, 0).Add(Interval1M)
+	if !c.started || now.Before(end) {
+		return QuoteBar{}, false
+	}
+	closed := c.at(end)
+	if c.halted {
+		*c = quoteCalc{lastID: c.lastID}
+		return closed, true
+	}
+
+	open := now.Truncate(Interval1M)
+	c.bar = QuoteBar{
+		Symbol:   closed.Symbol,
+		OpenTime: open.Unix(),
+		Bid:      OHLCBar{O: closed.Bid.C, H: closed.Bid.C, L: closed.Bid.C, C: closed.Bid.C, T: open.Unix()},
+		Ask:      OHLCBar{O: closed.Ask.C, H: closed.Ask.C, L: closed.Ask.C, C: closed.Ask.C, T: open.Unix()},
+	}
+	c.area, c.since, c.from, c.changed = 0, open, open, true
+	return closed, true
+}
+
+// QuoteOptions tune QuoteAggr, zero values fall back to the defaults
+type QuoteOptions struct {
+	// StreamURL is the websocket endpoint of bookTicker streams, BinanceStreamURL if it's empty
+	StreamURL string
+	// Clock times quotes and closes quote bars, the wall clock if it's nil
+	Clock Clock
+}
+
+// QuoteAggr builds quote bars of symbols from binance bookTicker streams
+type QuoteAggr struct {
+	logger  logr.Logger
+	symbols []string
+	clock   Clock
+	// serve opens a connection streaming best quotes of symbols, swapped in tests
+	serve func(symbols []string, handler func(*bconn.WsBookTickerEvent), errHandler func(error)) (doneCh, stopCh chan struct{}, err error)
+}
+
+func NewQuoteAggr(logger logr.Logger, symbols []string, opts QuoteOptions) *QuoteAggr {
+	streamURL := opts.StreamURL
+	if streamURL == "" {
+		streamURL = BinanceStreamURL
+	}
+	return &QuoteAggr{
+		logger:  logger,
+		symbols: symbols,
+		clock:   orSystemClock(opts.Clock),
+		serve: func(symbols []string, handler func(*bconn.WsBookTickerEvent), errHandler func(error)) (chan struct{}, chan struct{}, error) {
+			return bconn.NewWebsocketStreamClient(true, streamURL).WsCombinedBookTickerServe(symbols, handler, errHandler)
+		},
+	}
+}
+
+// Symbols are the symbols quote bars are built for
+func (q *QuoteAggr) Symbols() []string {
+	return q.symbols
+}
+
+// Stream streams best quotes of the symbols, and returns updates of their quote bars: bars in progress at most
+// once a second if they changed, and bars as they close. If the stream ends by itself bars in progress close
+// as usual, but no bar opens until it's redialed and quotes arrive again. The stream is closed once done is closed
+func (q *QuoteAggr) Stream(done <-chan struct{}) (<-chan QuoteUpdate, error) {
+	events := make(chan *bconn.WsBookTickerEvent, 100)
+	dial := func() (streamConn, error) {
+		doneCh, stopCh, err := q.serve(
+			q.symbols,
+			func(e *bconn.WsBookTickerEvent) {
+				select {
+				case events <- e:
+				case <-done:
+				}
+			},
+			func(err error) {
+				q.logger.Error(err, "quote stream error")
+			},
+		)
+		if err != nil {
+			return streamConn{}, err
+		}
+		q.logger.Info("subscribed", "symbols", q.symbols)
+		return streamConn{doneCh: doneCh, stopCh: stopCh}, nil
+	}
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	updateCh := make(chan QuoteUpdate, 500)
+	// the ticker is created before the goroutine starts, so a fake clock advanced right after returning hits it
+	ticker := q.clock.NewTicker(time.Second)
+	go func() {
+		defer close(updateCh)
+		defer ticker.Stop()
+		defer func() { conn.stop() }()
+
+		calcs := map[string]*quoteCalc{}
+		for _, symbol := range q.symbols {
+			calcs[symbol] = &quoteCalc{}
+		}
+		emit := func(u QuoteUpdate) bool {
+			select {
+			case updateCh <- u:
+				return true
+			case <-done:
+				return false
+			}
+		}
+		streamDone := conn.doneCh
+		var redialed <-chan streamConn
+		for {
+			select {
+			case <-done:
+				return
+			case <-streamDone:
+				q.logger.Error(ErrConnectionLost, "quote stream ended, no bars open until it's redialed", "symbols", q.symbols)
+				streamDone = nil
+				for _, c := range calcs {
+					c.halted = true
+				}
+				redialed = redialStream(q.logger.WithValues("symbols", q.symbols), q.clock, done, dial)
+			case conn = <-redialed:
+				streamDone, redialed = conn.doneCh, nil
+			case now := <-ticker.C():
+				for _, c := range calcs {
+					if bar, ok := c.roll(now); ok && !emit(QuoteUpdate{Bar: bar, Closed: true}) {
+						return
+					}
+					if c.started && c.changed {
+						c.changed = false
+						if !emit(QuoteUpdate{Bar: c.at(now)}) {
+							return
+						}
+					}
+				}
+			case e := <-events:
+				c, ok := calcs[e.Symbol]
+				if !ok {
+					q.logger.V(2).Error(ErrNotHanlderFound, "unsupported symbol", "symbol", e.Symbol)
+					continue
+				}
+				q.logger.V(4).Info("incoming quote", "event", e)
+				now := q.clock.Now()
+				if bar, ok := c.roll(now); ok && !emit(QuoteUpdate{Bar: bar, Closed: true}) {
+					return
+				}
+				c.update(e, now)
+			}
+		}
+	}()
+	return updateCh, nil
+}
@@ -0,0 +1,242 @@
+package tradingchat
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func quote(id int64, bid, ask string) *bconn.WsBookTickerEvent {
+	return &bconn.WsBookTickerEvent{UpdateID: id, Symbol: "BNBBTC", BestBidPrice: bid, BestAskPrice: ask}
+}
+
+func TestQuoteCalc(t *testing.T) {
+	open := time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC)
+	c := &quoteCalc{}
+
+	_, ok := c.roll(open.Add(time.Hour))
+	assert.False(t, ok, "no bar before the first quote")
+
+	c.update(quote(1, "0.0110", "0.0112"), open.Add(10*time.Second))
+	c.update(quote(2, "0.0111", "0.0112"), open.Add(40*time.Second))
+	c.update(quote(2, "0.0100", "0.0200"), open.Add(41*time.Second))
+	c.update(quote(3, "0.0109", "0"), open.Add(42*time.Second))
+	c.update(quote(4, "0.0109", "0.0110"), open.Add(50*time.Second))
+
+	bar := c.at(open.Add(55 * time.Second))
+	assert.Equal(t, OHLCBar{O: "0.0110", H: "0.0111", L: "0.0109", C: "0.0109", T: open.Unix() + 50}, bar.Bid)
+	assert.Equal(t, OHLCBar{O: "0.0112", H: "0.0112", L: "0.0110", C: "0.0110", T: open.Unix() + 50}, bar.Ask)
+	assert.Equal(t, int64(3), bar.Quotes, "stale and one sided quotes are dropped")
+	// 30s at 0.0002, 10s at 0.0001 and 5s at 0.0001 since the first quote
+	assert.InDelta(t, (30*0.0002+15*0.0001)/45, bar.MeanSpread, 1e-12)
+
+	_, ok = c.roll(open.Add(59 * time.Second))
+	assert.False(t, ok)
+	closed, ok := c.roll(open.Add(61 * time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, open.Unix(), closed.OpenTime)
+	assert.Equal(t, "BNBBTC", closed.Symbol)
+	assert.InDelta(t, (30*0.0002+20*0.0001)/50, closed.MeanSpread, 1e-12)
+
+	t.Run("next bar should open with the closing quote", func(t *testing.T) {
+		next := c.at(open.Add(90 * time.Second))
+		assert.Equal(t, open.Unix()+60, next.OpenTime)
+		assert.Equal(t, OHLCBar{O: "0.0109", H: "0.0109", L: "0.0109", C: "0.0109", T: open.Unix() + 60}, next.Bid)
+		assert.Equal(t, int64(0), next.Quotes)
+		assert.InDelta(t, 0.0001, next.MeanSpread, 1e-12)
+	})
+
+	t.Run("halted calc should start over from the next quote", func(t *testing.T) {
+		c.halted = true
+		closed, ok := c.roll(open.Add(2 * time.Minute))
+		assert.True(t, ok, "the bar in progress still closes")
+		assert.Equal(t, open.Unix()+60, closed.OpenTime)
+		_, ok = c.roll(open.Add(5 * time.Minute))
+		assert.False(t, ok, "no bar carries the last quote on")
+
+		c.update(quote(3, "0.0120", "0.0121"), open.Add(5*time.Minute+10*time.Second))
+		assert.False(t, c.started, "stale quotes are still dropped")
+		c.update(quote(5, "0.0120", "0.0121"), open.Add(5*time.Minute+10*time.Second))
+		bar := c.at(open.Add(5*time.Minute + 20*time.Second))
+		assert.Equal(t, open.Unix()+300, bar.OpenTime)
+		assert.Equal(t, "0.0120", bar.Bid.O)
+		assert.Equal(t, int64(1), bar.Quotes)
+		assert.InDelta(t, 0.0001, bar.MeanSpread, 1e-12)
+	})
+}
+
+func TestRollupQuotes(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	var open int64 = 1737734700
+	bars := []QuoteBar{
+		{Symbol: "BNBBTC", OpenTime: open - 60, Bid: OHLCBar{O: "0.0109", H: "0.0109", L: "0.0109", C: "0.0109", T: open - 30}, Ask: OHLCBar{O: "0.0110", H: "0.0110", L: "0.0110", C: "0.0110", T: open - 30}, MeanSpread: 0.0001, Quotes: 1},
+		{Symbol: "BNBBTC", OpenTime: open, Bid: OHLCBar{O: "0.0109", H: "0.0111", L: "0.0109", C: "0.0110", T: open + 40}, Ask: OHLCBar{O: "0.0110", H: "0.0113", L: "0.0110", C: "0.0112", T: open + 40}, MeanSpread: 0.0002, Quotes: 3},
+		{Symbol: "BNBBTC", OpenTime: open + 120, Bid: OHLCBar{O: "0.0110", H: "0.0110", L: "0.0108", C: "0.0108", T: open + 130}, Ask: OHLCBar{O: "0.0112", H: "0.0112", L: "0.0109", C: "0.0109", T: open + 130}, MeanSpread: 0.0003, Quotes: 2},
+	}
+
+	res := RollupQuotes(bars, Interval5M)
+	if assert.Len(t, res, 2) {
+		assert.Equal(t, bars[0].Bid, res[0].Bid)
+		assert.Equal(t, open-300, res[0].OpenTime, "bars open at the start of their interval")
+		assert.Equal(t, open, res[1].OpenTime)
+		assert.Equal(t, OHLCBar{O: "0.0109", H: "0.0111", L: "0.0108", C: "0.0108", T: open + 130}, res[1].Bid)
+		assert.Equal(t, OHLCBar{O: "0.0110", H: "0.0113", L: "0.0109", C: "0.0109", T: open + 130}, res[1].Ask)
+		assert.Equal(t, int64(5), res[1].Quotes)
+		assert.InDelta(t, 0.00025, res[1].MeanSpread, 1e-12)
+	}
+	assert.Equal(t, open, bars[1].OpenTime, "bars are left as they are")
+}
+
+func TestQuoteAggr(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	clock := NewFakeClock(time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC))
+	var handler func(*bconn.WsBookTickerEvent)
+	stopped := make(chan struct{})
+	q := NewQuoteAggr(logger, []string{"BNBBTC"}, QuoteOptions{Clock: clock})
+	q.serve = func(symbols []string, h func(*bconn.WsBookTickerEvent), _ func(error)) (chan struct{}, chan struct{}, error) {
+		assert.Equal(t, []string{"BNBBTC"}, symbols)
+		handler = h
+		doneCh, stopCh := make(chan struct{}), make(chan struct{})
+		go func() {
+			<-stopCh
+			close(doneCh)
+			close(stopped)
+		}()
+		return doneCh, stopCh, nil
+	}
+
+	done := make(chan struct{})
+	updates, err := q.Stream(done)
+	assert.NoError(t, err)
+	receive := func() QuoteUpdate {
+		select {
+		case u := <-updates:
+			return u
+		case <-time.After(time.Second):
+			t.Fatal("no quote update")
+			return QuoteUpdate{}
+		}
+	}
+
+	handler(quote(1, "0.0110", "0.0112"))
+	handler(quote(2, "0.0111", "0.0112"))
+	handler(quote(3, "0.0111", "0.0113"))
+	// quotes are handled concurrently with ticks, so ticks go on until the bar in progress has them all
+	var u QuoteUpdate
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		select {
+		case u = <-updates:
+			return u.Bar.Quotes == 3
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	assert.False(t, u.Closed)
+	assert.Equal(t, "0.0111", u.Bar.Bid.C)
+	assert.Equal(t, "0.0113", u.Bar.Ask.C)
+
+	// a late tick is timed when it was due, so the clock moves a tick at a time until the minute is over
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		select {
+		case u = <-updates:
+			return u.Closed
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	assert.True(t, u.Closed)
+	assert.Equal(t, int64(1737734700), u.Bar.OpenTime)
+	assert.Equal(t, int64(3), u.Bar.Quotes)
+	u = receive()
+	assert.False(t, u.Closed, "quiet symbols still get a bar every minute")
+	assert.Equal(t, int64(1737734760), u.Bar.OpenTime)
+	assert.Equal(t, "0.0111", u.Bar.Bid.O)
+
+	close(done)
+	for range updates {
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("connection not stopped")
+	}
+}
+
+func TestQuoteAggrRedial(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	clock := NewFakeClock(time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC))
+	var mu sync.Mutex
+	var handlers []func(*bconn.WsBookTickerEvent)
+	var kills []chan struct{}
+	q := NewQuoteAggr(logger, []string{"BNBBTC"}, QuoteOptions{Clock: clock})
+	q.serve = func(_ []string, h func(*bconn.WsBookTickerEvent), _ func(error)) (chan struct{}, chan struct{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		doneCh, stopCh, kill := make(chan struct{}), make(chan struct{}), make(chan struct{})
+		handlers, kills = append(handlers, h), append(kills, kill)
+		go func() {
+			select {
+			case <-stopCh:
+			case <-kill:
+			}
+			close(doneCh)
+		}()
+		return doneCh, stopCh, nil
+	}
+	conns := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handlers)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	updates, err := q.Stream(done)
+	assert.NoError(t, err)
+	// advances the clock a second at a time until an update matches
+	updateUntil := func(match func(QuoteUpdate) bool) QuoteUpdate {
+		var u QuoteUpdate
+		assert.Eventually(t, func() bool {
+			clock.Advance(time.Second)
+			select {
+			case u = <-updates:
+				return match(u)
+			case <-time.After(10 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second, time.Millisecond)
+		return u
+	}
+
+	handlers[0](quote(1, "0.0110", "0.0112"))
+	updateUntil(func(u QuoteUpdate) bool { return u.Bar.Quotes == 1 })
+	close(kills[0])
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		return conns() == 2
+	}, 5*time.Second, time.Millisecond, "stream is not redialed")
+
+	u := updateUntil(func(u QuoteUpdate) bool { return u.Closed })
+	assert.Equal(t, int64(1737734700), u.Bar.OpenTime)
+	clock.Advance(2 * time.Minute)
+	select {
+	case u := <-updates:
+		t.Fatalf("quote carried on while no quote arrived since the stream ended: %+v", u)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mu.Lock()
+	handler := handlers[1]
+	mu.Unlock()
+	handler(quote(2, "0.0111", "0.0113"))
+	u = updateUntil(func(u QuoteUpdate) bool { return u.Bar.Quotes == 1 })
+	assert.False(t, u.Closed)
+	assert.Equal(t, "0.0111", u.Bar.Bid.O)
+	assert.Greater(t, u.Bar.OpenTime, int64(1737734760))
+}
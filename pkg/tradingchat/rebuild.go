@@ -0,0 +1,77 @@
+package tradingchat
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
+)
+
+var (
+	ErrInvalidInterval = errors.New("interval must be a multiple of 1 minute")
+)
+
+// RebuildBars replays recorded trades of a symbol through OHLCCalc in trade id order, and rolls
+// the 1 minute bars up to interval
+func RebuildBars(logger logr.Logger, trades []*bconn.WsAggTradeEvent, interval time.Duration) ([]OHLCBar, error) {
+	if interval < Interval1M || interval%Interval1M != 0 {
+		return nil, ErrInvalidInterval
+	}
+
+	sorted := make([]*bconn.WsAggTradeEvent, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].AggTradeID < sorted[j].AggTradeID })
+
+	var bars []OHLCBar
+	b := NewBarBuilder(logger)
+	for _, e := range sorted {
+		if bar, ok := b.Add(e); ok {
+			bars = append(bars, bar)
+		}
+	}
+	if bar, ok := b.Flush(); ok {
+		bars = append(bars, bar)
+	}
+
+	if interval == Interval1M {
+		return bars, nil
+	}
+	return Rollup(bars, interval), nil
+}
+
+// BarBuilder builds 1 minute bars from trades fed in order with OHLCCalc, without keeping the trades around
+type BarBuilder struct {
+	calc    *OHLCCalc
+	started bool
+}
+
+func NewBarBuilder(logger logr.Logger) *BarBuilder {
+	return &BarBuilder{calc: NewOHLCCalc(logger)}
+}
+
+// Add updates the bar in progress with e, and returns the previous bar if e is the first trade of a later minute
+func (b *BarBuilder) Add(e *bconn.WsAggTradeEvent) (OHLCBar, bool) {
+	prev := b.calc.Bar()
+	b.calc.update(e)
+	if !b.started {
+		b.started = true
+		return OHLCBar{}, false
+	}
+	if !b.calc.Bar().OpenTime().Equal(prev.OpenTime()) {
+		return prev, true
+	}
+	return OHLCBar{}, false
+}
+
+// Flush returns the bar in progress and starts over
+func (b *BarBuilder) Flush() (OHLCBar, bool) {
+	if !b.started {
+		return OHLCBar{}, false
+	}
+	bar := b.calc.Bar()
+	b.calc = NewOHLCCalc(b.calc.logger)
+	b.started = false
+	return bar, true
+}
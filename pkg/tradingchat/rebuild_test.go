@@ -0,0 +1,53 @@
+package tradingchat
+
+import (
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRebuildBars(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+
+	// 16:05 on Jan 24th 2025, trades are recorded out of order
+	trades := []*bconn.WsAggTradeEvent{
+		{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", TradeTime: 1737734711000},
+		{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", TradeTime: 1737734701000},
+		{Symbol: "BNBBTC", AggTradeID: 3, Price: "0.11104", TradeTime: 1737734759000},
+		{Symbol: "BNBBTC", AggTradeID: 4, Price: "0.11101", TradeTime: 1737734760000},
+		{Symbol: "BNBBTC", AggTradeID: 5, Price: "0.11131", TradeTime: 1737735001000},
+	}
+
+	t.Run("1m bars should be rebuilt in trade id order", func(t *testing.T) {
+		bars, err := RebuildBars(logger, trades, Interval1M)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]OHLCBar{
+				{H: "0.11121", L: "0.11104", O: "0.11111", C: "0.11104", T: 1737734759},
+				{H: "0.11101", L: "0.11101", O: "0.11101", C: "0.11101", T: 1737734760},
+				{H: "0.11131", L: "0.11131", O: "0.11131", C: "0.11131", T: 1737735001},
+			},
+			bars,
+		)
+	})
+
+	t.Run("bars should be rolled up to longer interval", func(t *testing.T) {
+		bars, err := RebuildBars(logger, trades, Interval5M)
+		assert.NoError(t, err)
+		assert.Equal(t,
+			[]OHLCBar{
+				{H: "0.11121", L: "0.11101", O: "0.11111", C: "0.11101", T: 1737734760},
+				{H: "0.11131", L: "0.11131", O: "0.11131", C: "0.11131", T: 1737735001},
+			},
+			bars,
+		)
+	})
+
+	t.Run("interval shorter than a minute should be rejected", func(t *testing.T) {
+		_, err := RebuildBars(logger, trades, 30*time.Second)
+		assert.ErrorIs(t, err, ErrInvalidInterval)
+	})
+}
@@ -0,0 +1,46 @@
+package tradingchat
+
+import "time"
+
+// bars of every history interval kept by the aggregation, indicators needing more are warmed up from the store
+const recentBarsKept = 200
+
+// recentIntervals are the intervals recent bars are kept at, the ones history can be queried at
+var recentIntervals = []time.Duration{Interval1M, Interval5M, Interval1H, Interval1D}
+
+// RecentBars are the latest bars of every history interval keyed by its short name, e.g. 5m, rolled up from
+// closed 1 minute bars and timed by the bucket they opened at like listed bars. The last bar of an interval longer
+// than 1 minute may be a bucket still in progress. Slices are never changed in place, so states can share them
+type RecentBars map[string][]OHLCBar
+
+// push returns recent bars with bar closed
+func (r RecentBars) push(bar OHLCBar) RecentBars {
+	res := make(RecentBars, len(recentIntervals))
+	for _, interval := range recentIntervals {
+		name := shortDuration(interval)
+		res[name] = rollInto(r[name], bar, interval)
+	}
+	return res
+}
+
+// rollInto returns a copy of bars at interval with bar merged into the bucket it opened in, bars of buckets
+// older than the last one are too late to be kept
+func rollInto(bars []OHLCBar, bar OHLCBar, interval time.Duration) []OHLCBar {
+	open := time.Unix(bar.T, 0).Truncate(interval).Unix()
+	n := len(bars)
+	if n > 0 && bars[n-1].T > open {
+		return bars
+	}
+	if n > 0 && bars[n-1].T == open {
+		res := append([]OHLCBar(nil), bars...)
+		last := &res[n-1]
+		incomplete := last.Incomplete || bar.Incomplete
+		mergeBar(last, bar)
+		last.T, last.Incomplete = open, incomplete
+		return res
+	}
+	bar.T = open
+	res := make([]OHLCBar, 0, min(n+1, recentBarsKept))
+	res = append(res, bars[max(0, n+1-recentBarsKept):]...)
+	return append(res, bar)
+}
@@ -0,0 +1,231 @@
+package tradingchat
+
+import (
+	"math"
+	"strconv"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+var (
+	_ ActivityBuilder = (*ActivityCalc)(nil)
+	_ ActivityBuilder = (*RenkoCalc)(nil)
+	_ ActivityBuilder = (*RangeCalc)(nil)
+
+	_ checkpointedBuilder = (*ActivityCalc)(nil)
+	_ checkpointedBuilder = (*RenkoCalc)(nil)
+	_ checkpointedBuilder = (*RangeCalc)(nil)
+)
+
+// RenkoCalc builds Renko bricks of Threshold price on a grid of multiples of Threshold, starting from the
+// level closest to the first trade. A brick in the direction of the last one needs the price to move a brick
+// beyond it, a reversal brick needs two, and opens at the open of the last brick. A trade moving several bricks
+// closes all of them, the trades since the last brick are counted in the first one. Updates of the brick in
+// progress open at the last level and follow the price
+type RenkoCalc struct {
+	spec     ActivitySpec
+	level    int64 // index of the close of the last brick on the grid
+	dir      int   // direction of the last brick, 0 before the first one
+	started  bool
+	decimals int
+	forming  ActivityBar // trades since the last brick
+	open     bool
+}
+
+func NewRenkoCalc(spec ActivitySpec) *RenkoCalc {
+	return &RenkoCalc{
+		spec:     spec,
+		decimals: decimalsOf(strconv.FormatFloat(spec.Threshold, 'f', -1, 64)),
+	}
+}
+
+// Update implements ActivityBuilder.
+func (c *RenkoCalc) Update(e *bconn.WsAggTradeEvent) ([]ActivityUpdate, error) {
+	price, qty, err := parseTrade(e)
+	if err != nil {
+		return nil, err
+	}
+	size := c.spec.Threshold
+	if !c.started {
+		c.level = int64(math.Round(price / size))
+		c.started = true
+	}
+	c.decimals = max(c.decimals, decimalsOf(e.Price))
+	if !c.open {
+		c.forming = openActivityBar(e)
+		c.open = true
+	}
+	c.forming.add(e, price, qty)
+
+	eps := size * 1e-9
+	at := func(level int64) float64 { return float64(level) * size }
+	var res []ActivityUpdate
+	for {
+		var from, to int64
+		switch {
+		case c.dir >= 0 && price >= at(c.level+1)-eps:
+			from, to, c.dir = c.level, c.level+1, 1
+		case c.dir <= 0 && price <= at(c.level-1)+eps:
+			from, to, c.dir = c.level, c.level-1, -1
+		case c.dir > 0 && price <= at(c.level-2)+eps:
+			from, to, c.dir = c.level-1, c.level-2, -1
+		case c.dir < 0 && price >= at(c.level+2)-eps:
+			from, to, c.dir = c.level+1, c.level+2, 1
+		default:
+			if c.open {
+				res = append(res, ActivityUpdate{Symbol: e.Symbol, Spec: c.spec, Bar: c.inProgress()})
+			}
+			return res, nil
+		}
+		c.level = to
+
+		brick := c.forming
+		brick.O, brick.C = c.format(at(from)), c.format(at(to))
+		brick.H, brick.L = c.format(math.Max(at(from), at(to))), c.format(math.Min(at(from), at(to)))
+		res = append(res, ActivityUpdate{Symbol: e.Symbol, Spec: c.spec, Bar: brick, Closed: true})
+
+		// further bricks of the same trade
+		c.forming = ActivityBar{
+			OHLCBar: OHLCBar{T: tradeUnix(e)},
+			OpenT:   tradeUnix(e),
+			FirstID: e.AggTradeID,
+			Seq:     nextSeq(brick, e.AggTradeID),
+			LastID:  e.AggTradeID,
+		}
+		c.open = false
+	}
+}
+
+// inProgress is the brick in progress, opened at the last level and spanning trades since
+func (c *RenkoCalc) inProgress() ActivityBar {
+	bar := c.forming
+	bar.O = c.format(float64(c.level) * c.spec.Threshold)
+	if priceLess(bar.H, bar.O) {
+		bar.H = bar.O
+	}
+	if priceLess(bar.O, bar.L) {
+		bar.L = bar.O
+	}
+	return bar
+}
+
+func (c *RenkoCalc) format(f float64) string {
+	return strconv.FormatFloat(f, 'f', c.decimals, 64)
+}
+
+func (c *RenkoCalc) state() ActivityState {
+	return ActivityState{Spec: c.spec, Bar: c.forming, Open: c.open, Level: c.level, Dir: c.dir, Started: c.started, Decimals: c.decimals}
+}
+
+func (c *RenkoCalc) restore(state ActivityState) {
+	c.forming, c.open = state.Bar, state.Open
+	c.level, c.dir, c.started = state.Level, state.Dir, state.Started
+	c.decimals = max(c.decimals, state.Decimals)
+}
+
+// RangeCalc builds range bars spanning exactly Threshold price from high to low. A bar closes once its span
+// reaches Threshold. A trade jumping beyond closes it at the price Threshold away from its other end, and further
+// bars of Threshold span the way to the trade like Renko bricks do, the trade is counted in the first one
+type RangeCalc struct {
+	spec     ActivitySpec
+	bar      ActivityBar
+	open     bool
+	high     float64
+	low      float64
+	decimals int
+}
+
+func NewRangeCalc(spec ActivitySpec) *RangeCalc {
+	return &RangeCalc{
+		spec:     spec,
+		decimals: decimalsOf(strconv.FormatFloat(spec.Threshold, 'f', -1, 64)),
+	}
+}
+
+// Update implements ActivityBuilder.
+func (c *RangeCalc) Update(e *bconn.WsAggTradeEvent) ([]ActivityUpdate, error) {
+	price, qty, err := parseTrade(e)
+	if err != nil {
+		return nil, err
+	}
+	span := c.spec.Threshold
+	eps := span * 1e-9
+	c.decimals = max(c.decimals, decimalsOf(e.Price))
+	if !c.open {
+		c.bar = openActivityBar(e)
+		c.high, c.low = price, price
+		c.open = true
+	}
+
+	var res []ActivityUpdate
+	counted := false
+	for {
+		up := price > c.low+span+eps
+		if !up && price >= c.high-span-eps {
+			break
+		}
+		edge := c.high - span
+		if up {
+			edge = c.low + span
+		}
+		if !counted {
+			c.bar.add(e, price, qty)
+			counted = true
+		}
+		c.bar.C = c.format(edge)
+		if up {
+			c.bar.H = c.bar.C
+		} else {
+			c.bar.L = c.bar.C
+		}
+		res = append(res, ActivityUpdate{Symbol: e.Symbol, Spec: c.spec, Bar: c.bar, Closed: true})
+		c.openAt(e, edge)
+	}
+
+	if counted {
+		// the rest of the jump, within the bar opened at the last edge
+		if priceLess(c.bar.H, e.Price) {
+			c.bar.H = e.Price
+		}
+		if priceLess(e.Price, c.bar.L) {
+			c.bar.L = e.Price
+		}
+		c.bar.C = e.Price
+	} else {
+		c.bar.add(e, price, qty)
+	}
+	c.high, c.low = math.Max(c.high, price), math.Min(c.low, price)
+
+	closed := c.high-c.low >= span-eps
+	if closed {
+		c.open = false
+	}
+	return append(res, ActivityUpdate{Symbol: e.Symbol, Spec: c.spec, Bar: c.bar, Closed: closed}), nil
+}
+
+// openAt opens a bar at level crossed by e, e is counted in the bar it closed
+func (c *RangeCalc) openAt(e *bconn.WsAggTradeEvent, level float64) {
+	p := c.format(level)
+	c.bar = ActivityBar{
+		OHLCBar: OHLCBar{H: p, L: p, O: p, C: p, T: tradeUnix(e)},
+		OpenT:   tradeUnix(e),
+		FirstID: e.AggTradeID,
+		Seq:     nextSeq(c.bar, e.AggTradeID),
+		LastID:  e.AggTradeID,
+	}
+	c.high, c.low = level, level
+}
+
+func (c *RangeCalc) format(f float64) string {
+	return strconv.FormatFloat(f, 'f', c.decimals, 64)
+}
+
+func (c *RangeCalc) state() ActivityState {
+	return ActivityState{Spec: c.spec, Bar: c.bar, Open: c.open, High: c.high, Low: c.low, Decimals: c.decimals}
+}
+
+func (c *RangeCalc) restore(state ActivityState) {
+	c.bar, c.open = state.Bar, state.Open
+	c.high, c.low = state.High, state.Low
+	c.decimals = max(c.decimals, state.Decimals)
+}
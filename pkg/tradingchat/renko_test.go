@@ -0,0 +1,128 @@
+package tradingchat
+
+import (
+	"strconv"
+	"testing"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenkoCalc(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+	calc := NewActivityBuilder(ActivitySpec{Type: ActivityRenko, Threshold: 10})
+	update := func(id int64, price string) []ActivityUpdate {
+		updates, err := calc.Update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: id, Price: price, Quantity: "1", TradeTime: (open + id) * 1000})
+		assert.NoError(t, err)
+		return updates
+	}
+
+	t.Run("the brick in progress should open at the last level", func(t *testing.T) {
+		update(1, "100")
+		updates := update(2, "105")
+		assert.Len(t, updates, 1)
+		assert.False(t, updates[0].Closed)
+		assert.Equal(t, OHLCBar{H: "105", L: "100", O: "100", C: "105", T: open + 2}, updates[0].Bar.OHLCBar)
+	})
+
+	t.Run("a brick should close a brick beyond the last level", func(t *testing.T) {
+		updates := update(3, "112")
+		assert.Len(t, updates, 1)
+		assert.True(t, updates[0].Closed)
+		assert.Equal(t, OHLCBar{H: "110", L: "100", O: "100", C: "110", T: open + 3}, updates[0].Bar.OHLCBar)
+		assert.Equal(t, []int64{1, 3, 3}, []int64{updates[0].Bar.FirstID, updates[0].Bar.LastID, updates[0].Bar.Trades})
+	})
+
+	t.Run("reversals should need two bricks", func(t *testing.T) {
+		updates := update(4, "95")
+		assert.Len(t, updates, 1)
+		assert.False(t, updates[0].Closed)
+		assert.Equal(t, OHLCBar{H: "110", L: "95", O: "110", C: "95", T: open + 4}, updates[0].Bar.OHLCBar)
+
+		updates = update(5, "88")
+		assert.Len(t, updates, 1)
+		assert.True(t, updates[0].Closed)
+		assert.Equal(t, OHLCBar{H: "100", L: "90", O: "100", C: "90", T: open + 5}, updates[0].Bar.OHLCBar)
+		assert.Equal(t, int64(2), updates[0].Bar.Trades)
+	})
+
+	t.Run("a trade moving several bricks should close all of them", func(t *testing.T) {
+		updates := update(6, "60")
+		assert.Len(t, updates, 3)
+		var closes []string
+		var seqs []int64
+		for _, u := range updates {
+			assert.True(t, u.Closed)
+			assert.Equal(t, int64(6), u.Bar.FirstID)
+			closes = append(closes, u.Bar.C)
+			seqs = append(seqs, u.Bar.Seq)
+		}
+		assert.Equal(t, []string{"80", "70", "60"}, closes)
+		assert.Equal(t, []int64{0, 1, 2}, seqs, "bricks of the same trade are told apart by seq")
+		assert.Equal(t, int64(1), updates[0].Bar.Trades)
+		assert.Equal(t, int64(0), updates[2].Bar.Trades)
+	})
+
+	t.Run("malformed prices should fail", func(t *testing.T) {
+		_, err := calc.Update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "x"})
+		assert.Error(t, err)
+	})
+}
+
+func TestRangeCalc(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+	calc := NewActivityBuilder(ActivitySpec{Type: ActivityRange, Threshold: 5})
+	var closed []ActivityBar
+	var last ActivityUpdate
+	for i, price := range []string{"100", "102", "99", "104", "106", "112", "125"} {
+		updates, err := calc.Update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: int64(i + 1), Price: price, Quantity: "1", TradeTime: (open + int64(i)) * 1000})
+		assert.NoError(t, err)
+		for _, u := range updates {
+			if u.Closed {
+				closed = append(closed, u.Bar)
+			}
+			last = u
+		}
+	}
+
+	assert.Len(t, closed, 4)
+	for _, bar := range closed {
+		h, _ := strconv.ParseFloat(bar.H, 64)
+		l, _ := strconv.ParseFloat(bar.L, 64)
+		assert.Equal(t, 5.0, h-l, "bars should span exactly the threshold")
+	}
+	// closed once the span reached the threshold
+	assert.Equal(t, OHLCBar{H: "104", L: "99", O: "100", C: "104", T: open + 3}, closed[0].OHLCBar)
+	// closed at the threshold by the trade jumping beyond it, which is counted in it
+	assert.Equal(t, OHLCBar{H: "111", L: "106", O: "106", C: "111", T: open + 5}, closed[1].OHLCBar)
+	assert.Equal(t, []int64{5, 6, 2}, []int64{closed[1].FirstID, closed[1].LastID, closed[1].Trades})
+	// a bigger jump closes bars all the way to the trade
+	assert.Equal(t, OHLCBar{H: "116", L: "111", O: "111", C: "116", T: open + 6}, closed[2].OHLCBar)
+	assert.Equal(t, int64(1), closed[2].Trades)
+	assert.Equal(t, OHLCBar{H: "121", L: "116", O: "116", C: "121", T: open + 6}, closed[3].OHLCBar)
+	assert.Equal(t, int64(0), closed[3].Trades)
+
+	assert.False(t, last.Closed)
+	assert.Equal(t, OHLCBar{H: "125", L: "121", O: "121", C: "125", T: open + 6}, last.Bar.OHLCBar)
+	// bars opened by the trade closing them share its id
+	assert.Equal(t, []int64{7, 0}, []int64{closed[3].FirstID, closed[3].Seq})
+	assert.Equal(t, []int64{7, 1}, []int64{last.Bar.FirstID, last.Bar.Seq})
+
+	t.Run("jump down should close bars at the threshold below the high", func(t *testing.T) {
+		calc := NewActivityBuilder(ActivitySpec{Type: ActivityRange, Threshold: 0.5})
+		calc.Update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 1, Price: "10.2", TradeTime: open * 1000})
+		updates, err := calc.Update(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 2, Price: "9.1", TradeTime: (open + 1) * 1000})
+		assert.NoError(t, err)
+		var bars []OHLCBar
+		for _, u := range updates {
+			bars = append(bars, u.Bar.OHLCBar)
+		}
+		assert.Equal(t, []OHLCBar{
+			{H: "10.2", L: "9.7", O: "10.2", C: "9.7", T: open + 1},
+			{H: "9.7", L: "9.2", O: "9.7", C: "9.2", T: open + 1},
+			{H: "9.2", L: "9.1", O: "9.2", C: "9.1", T: open + 1},
+		}, bars)
+	})
+}
@@ -0,0 +1,436 @@
+package tradingchat
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"container/heap"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
+)
+
+type ReplayFormat string
+
+const (
+	// FormatJSONLines is one WsAggTradeEvent json per line, as recorded by the trade tape
+	FormatJSONLines ReplayFormat = "jsonl"
+	// FormatCSV has a header of symbol,agg_trade_id,price,quantity,trade_time,is_buyer_maker,
+	// trade_time in unix milliseconds, seconds are accepted as well
+	FormatCSV ReplayFormat = "csv"
+	// FormatBinanceAggTrades is the aggTrades dump of data.binance.vision, symbol is taken from
+	// the file name when not given
+	FormatBinanceAggTrades ReplayFormat = "binance"
+)
+
+var (
+	ErrUnknownFormat = errors.New("unknown trade file format")
+	ErrMalformedRow  = errors.New("malformed trade row")
+)
+
+var _ TradeSource = (*ReplaySource)(nil)
+
+// ReplaySource replays recorded trade files merged by trade time, either as fast as possible when speed is 0,
+// or paced at the recorded speed times speed
+type ReplaySource struct {
+	logger logr.Logger
+	files  []string
+	format ReplayFormat
+	symbol string
+	speed  float64
+	clock  Clock
+	err    error // the error that ended the replay early, written before the stream is closed
+}
+
+// NewReplaySource replays files of format, an empty format is detected from file extensions
+func NewReplaySource(logger logr.Logger, files []string, format ReplayFormat, symbol string, speed float64) *ReplaySource {
+	return &ReplaySource{
+		logger: logger,
+		files:  files,
+		format: format,
+		symbol: symbol,
+		speed:  speed,
+		clock:  SystemClock,
+	}
+}
+
+// WithClock returns a copy of r paced by clock instead of the wall clock
+func (r *ReplaySource) WithClock(clock Clock) *ReplaySource {
+	c := *r
+	c.clock = orSystemClock(clock)
+	return &c
+}
+
+// Stream implements TradeSource, every file is opened before it returns. The stream ends early if a file
+// can't be read, Err tells why
+func (r *ReplaySource) Stream(done <-chan struct{}) (<-chan *bconn.WsAggTradeEvent, error) {
+	files := make(replayHeap, 0, len(r.files))
+	for i, path := range r.files {
+		dec, closer, err := OpenTradeFile(path, r.format, r.symbol)
+		if err != nil {
+			files.close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		files = append(files, &replayFile{path: path, order: i, dec: dec, closer: closer})
+	}
+	r.logger.Info("replaying trade files", "files", r.files)
+
+	eventCh := make(chan *bconn.WsAggTradeEvent)
+	go func() {
+		defer close(eventCh)
+		defer files.close()
+
+		// files are merged k-way, the heap holds every file with a trade left by its next trade
+		merging := make(replayHeap, 0, len(files))
+		for _, f := range files {
+			ok, err := f.advance()
+			if err != nil {
+				r.fail(err)
+				return
+			}
+			if ok {
+				merging = append(merging, f)
+			}
+		}
+		heap.Init(&merging)
+
+		var startedAt time.Time
+		var firstMs int64
+		for len(merging) > 0 {
+			f := merging[0]
+			e := f.next
+			if r.speed > 0 {
+				if startedAt.IsZero() {
+					startedAt, firstMs = r.clock.Now(), e.TradeTime
+				}
+				due := startedAt.Add(time.Duration(float64(e.TradeTime-firstMs)/r.speed) * time.Millisecond)
+				if wait := due.Sub(r.clock.Now()); wait > 0 {
+					select {
+					case <-r.clock.After(wait):
+					case <-done:
+						return
+					}
+				}
+			}
+
+			select {
+			case eventCh <- e:
+			case <-done:
+				return
+			}
+
+			ok, err := f.advance()
+			if err != nil {
+				r.fail(err)
+				return
+			}
+			if ok {
+				heap.Fix(&merging, 0)
+			} else {
+				heap.Pop(&merging)
+			}
+		}
+		r.logger.Info("replay finished", "files", len(r.files))
+	}()
+	return eventCh, nil
+}
+
+// Err returns the error that ended the replay before every trade was streamed, it's valid once the stream is closed
+func (r *ReplaySource) Err() error {
+	return r.err
+}
+
+func (r *ReplaySource) fail(err error) {
+	r.logger.Error(err, "replay stopped")
+	r.err = err
+}
+
+// replayFile is a trade file being replayed along with its next trade
+type replayFile struct {
+	path   string
+	order  int // position in the list of files, trades of the same time are replayed in it
+	dec    TradeDecoder
+	closer io.Closer
+	next   *bconn.WsAggTradeEvent
+}
+
+// advance reads the next trade of f, it returns false once there is none left
+func (f *replayFile) advance() (bool, error) {
+	e, err := f.dec.Next()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", f.path, err)
+	}
+	f.next = e
+	return true, nil
+}
+
+// replayHeap orders files by trade time of their next trade
+type replayHeap []*replayFile
+
+func (h replayHeap) Len() int { return len(h) }
+func (h replayHeap) Less(i, j int) bool {
+	if h[i].next.TradeTime != h[j].next.TradeTime {
+		return h[i].next.TradeTime < h[j].next.TradeTime
+	}
+	return h[i].order < h[j].order
+}
+func (h replayHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *replayHeap) Push(x any)   { *h = append(*h, x.(*replayFile)) }
+func (h *replayHeap) Pop() any {
+	old := *h
+	f := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return f
+}
+
+func (h replayHeap) close() {
+	for _, f := range h {
+		f.closer.Close()
+	}
+}
+
+// TradeDecoder reads trades one by one, io.EOF is returned after the last trade
+type TradeDecoder interface {
+	Next() (*bconn.WsAggTradeEvent, error)
+}
+
+// OpenTradeFile opens a trade file of format, gzip and zip archives are decompressed transparently.
+// Decoded events carry the trade time in unix milliseconds as both TradeTime and Time
+func OpenTradeFile(path string, format ReplayFormat, symbol string) (TradeDecoder, io.Closer, error) {
+	name := filepath.Base(path)
+	if format == "" {
+		format = detectFormat(name)
+	}
+	if symbol == "" && format == FormatBinanceAggTrades {
+		symbol, _, _ = strings.Cut(name, "-")
+	}
+
+	rd, closer, err := OpenDecompressed(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dec, err := NewTradeDecoder(rd, format, symbol)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	return dec, closer, nil
+}
+
+// OpenDecompressed opens path, gzip files are decompressed and entries of zip archives are concatenated
+func OpenDecompressed(path string) (io.Reader, io.Closer, error) {
+	name := filepath.Base(path)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		zr, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		var readers []io.Reader
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			fr, err := f.Open()
+			if err != nil {
+				zr.Close()
+				return nil, nil, err
+			}
+			readers = append(readers, fr)
+		}
+		return io.MultiReader(readers...), zr, nil
+	case strings.HasSuffix(name, ".gz"):
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return gz, f, nil
+	default:
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, f, nil
+	}
+}
+
+func detectFormat(name string) ReplayFormat {
+	name = strings.TrimSuffix(name, ".gz")
+	switch {
+	case strings.Contains(name, "aggTrades") || strings.HasSuffix(name, ".zip"):
+		return FormatBinanceAggTrades
+	case strings.HasSuffix(name, ".csv"):
+		return FormatCSV
+	default:
+		return FormatJSONLines
+	}
+}
+
+func NewTradeDecoder(r io.Reader, format ReplayFormat, symbol string) (TradeDecoder, error) {
+	switch format {
+	case FormatJSONLines:
+		return &jsonLinesDecoder{scanner: bufio.NewScanner(r)}, nil
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		return &csvDecoder{r: cr}, nil
+	case FormatBinanceAggTrades:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		return &binanceDecoder{r: cr, symbol: symbol}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+type jsonLinesDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (d *jsonLinesDecoder) Next() (*bconn.WsAggTradeEvent, error) {
+	for d.scanner.Scan() {
+		if len(strings.TrimSpace(d.scanner.Text())) == 0 {
+			continue
+		}
+		var e bconn.WsAggTradeEvent
+		if err := json.Unmarshal(d.scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		// tapes recorded before trade times were kept in milliseconds have seconds
+		e.TradeTime = toUnixMilli(e.TradeTime)
+		if e.Time == 0 {
+			e.Time = e.TradeTime
+		}
+		return &e, nil
+	}
+	if err := d.scanner.Err(); err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type csvDecoder struct {
+	r      *csv.Reader
+	header map[string]int
+}
+
+func (d *csvDecoder) Next() (*bconn.WsAggTradeEvent, error) {
+	if d.header == nil {
+		row, err := d.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		d.header = make(map[string]int, len(row))
+		for i, col := range row {
+			d.header[strings.TrimSpace(col)] = i
+		}
+	}
+
+	row, err := d.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	field := func(col string) string {
+		i, ok := d.header[col]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	id, err := strconv.ParseInt(field("agg_trade_id"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedRow, row)
+	}
+	ts, err := strconv.ParseInt(field("trade_time"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedRow, row)
+	}
+	maker, _ := strconv.ParseBool(field("is_buyer_maker"))
+	ms := toUnixMilli(ts)
+	return &bconn.WsAggTradeEvent{
+		Event:        "aggTrade",
+		Time:         ms,
+		Symbol:       field("symbol"),
+		AggTradeID:   id,
+		Price:        field("price"),
+		Quantity:     field("quantity"),
+		TradeTime:    ms,
+		IsBuyerMaker: maker,
+	}, nil
+}
+
+// binanceDecoder reads rows of agg_trade_id,price,quantity,first_trade_id,last_trade_id,transact_time,is_buyer_maker[,is_best_match],
+// some dumps start with a header
+type binanceDecoder struct {
+	r      *csv.Reader
+	symbol string
+}
+
+func (d *binanceDecoder) Next() (*bconn.WsAggTradeEvent, error) {
+	for {
+		row, err := d.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < 7 {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedRow, row)
+		}
+		id, err := strconv.ParseInt(row[0], 10, 64)
+		if err != nil {
+			// header row
+			continue
+		}
+		first, err1 := strconv.ParseInt(row[3], 10, 64)
+		last, err2 := strconv.ParseInt(row[4], 10, 64)
+		ts, err3 := strconv.ParseInt(row[5], 10, 64)
+		maker, err4 := strconv.ParseBool(row[6])
+		if err := errors.Join(err1, err2, err3, err4); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedRow, row)
+		}
+		ms := toUnixMilli(ts)
+		return &bconn.WsAggTradeEvent{
+			Event:                 "aggTrade",
+			Time:                  ms,
+			Symbol:                d.symbol,
+			AggTradeID:            id,
+			Price:                 row[1],
+			Quantity:              row[2],
+			FirstBreakdownTradeID: first,
+			LastBreakdownTradeID:  last,
+			TradeTime:             ms,
+			IsBuyerMaker:          maker,
+		}, nil
+	}
+}
+
+// toUnixMilli normalizes timestamps of dumps, spot dumps switched from milliseconds to microseconds in 2025
+func toUnixMilli(ts int64) int64 {
+	switch {
+	case ts >= 1e14:
+		return ts / 1000
+	case ts < 1e11:
+		return ts * 1000
+	default:
+		return ts
+	}
+}
@@ -0,0 +1,281 @@
+package tradingchat
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeAll(t *testing.T, dec TradeDecoder) []*bconn.WsAggTradeEvent {
+	t.Helper()
+	var events []*bconn.WsAggTradeEvent
+	for {
+		e, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			return events
+		}
+		if !assert.NoError(t, err) {
+			return events
+		}
+		events = append(events, e)
+	}
+}
+
+func TestTradeDecoder(t *testing.T) {
+	want := []*bconn.WsAggTradeEvent{
+		{Event: "aggTrade", Time: 1737734701000, Symbol: "BNBBTC", AggTradeID: 1, Price: "0.11111", Quantity: "1.5", TradeTime: 1737734701000, IsBuyerMaker: true},
+		{Event: "aggTrade", Time: 1737734711000, Symbol: "BNBBTC", AggTradeID: 2, Price: "0.11121", Quantity: "0.2", TradeTime: 1737734711000},
+	}
+
+	t.Run("json lines should be decoded as recorded", func(t *testing.T) {
+		in := `{"e":"aggTrade","E":1737734701000,"s":"BNBBTC","a":1,"p":"0.11111","q":"1.5","T":1737734701,"m":true}
+
+{"e":"aggTrade","s":"BNBBTC","a":2,"p":"0.11121","q":"0.2","T":1737734711,"m":false}
+`
+		dec, err := NewTradeDecoder(strings.NewReader(in), FormatJSONLines, "")
+		assert.NoError(t, err)
+		assert.Equal(t, want, decodeAll(t, dec))
+	})
+
+	t.Run("csv should be decoded by its header", func(t *testing.T) {
+		in := `symbol,agg_trade_id,price,quantity,trade_time,is_buyer_maker
+BNBBTC,1,0.11111,1.5,1737734701,true
+BNBBTC,2,0.11121,0.2,1737734711,false
+`
+		dec, err := NewTradeDecoder(strings.NewReader(in), FormatCSV, "")
+		assert.NoError(t, err)
+		assert.Equal(t, want, decodeAll(t, dec))
+	})
+
+	t.Run("binance dump should normalize timestamps and skip header", func(t *testing.T) {
+		in := `agg_trade_id,price,quantity,first_trade_id,last_trade_id,transact_time,is_buyer_maker,is_best_match
+1,0.11111,1.5,10,12,1737734701000,True,True
+2,0.11121,0.2,13,13,1737734711000000,False,True
+`
+		dec, err := NewTradeDecoder(strings.NewReader(in), FormatBinanceAggTrades, "BNBBTC")
+		assert.NoError(t, err)
+		got := decodeAll(t, dec)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, int64(12), got[0].LastBreakdownTradeID)
+			got[0].FirstBreakdownTradeID, got[0].LastBreakdownTradeID = 0, 0
+			got[1].FirstBreakdownTradeID, got[1].LastBreakdownTradeID = 0, 0
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("malformed row should be rejected", func(t *testing.T) {
+		dec, err := NewTradeDecoder(strings.NewReader("1,0.1,1,1,1,abc,true\n"), FormatBinanceAggTrades, "BNBBTC")
+		assert.NoError(t, err)
+		_, err = dec.Next()
+		assert.ErrorIs(t, err, ErrMalformedRow)
+	})
+
+	t.Run("unknown format should be rejected", func(t *testing.T) {
+		_, err := NewTradeDecoder(strings.NewReader(""), "xml", "")
+		assert.ErrorIs(t, err, ErrUnknownFormat)
+	})
+}
+
+func TestOpenTradeFile(t *testing.T) {
+	dir := t.TempDir()
+	rows := "1,0.11111,1.5,10,12,1737734701000,true,true\n2,0.11121,0.2,13,13,1737734711000,false,true\n"
+
+	zipPath := filepath.Join(dir, "BNBBTC-aggTrades-2025-01-24.zip")
+	f, err := os.Create(zipPath)
+	assert.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("BNBBTC-aggTrades-2025-01-24.csv")
+	assert.NoError(t, err)
+	_, err = w.Write([]byte(rows))
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	assert.NoError(t, f.Close())
+
+	gzPath := filepath.Join(dir, "trades.jsonl.gz")
+	f, err = os.Create(gzPath)
+	assert.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	_, err = gz.Write([]byte(`{"s":"ETHBTC","a":7,"p":"0.03","T":1737734701}` + "\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, gz.Close())
+	assert.NoError(t, f.Close())
+
+	t.Run("zip dump should take symbol from file name", func(t *testing.T) {
+		dec, closer, err := OpenTradeFile(zipPath, "", "")
+		assert.NoError(t, err)
+		defer closer.Close()
+		got := decodeAll(t, dec)
+		if assert.Len(t, got, 2) {
+			assert.Equal(t, "BNBBTC", got[0].Symbol)
+			assert.Equal(t, int64(1737734711000), got[1].TradeTime)
+		}
+	})
+
+	t.Run("gzip json lines should be detected by extension", func(t *testing.T) {
+		dec, closer, err := OpenTradeFile(gzPath, "", "")
+		assert.NoError(t, err)
+		defer closer.Close()
+		got := decodeAll(t, dec)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, "ETHBTC", got[0].Symbol)
+			assert.Equal(t, int64(1737734701000), got[0].Time)
+		}
+	})
+}
+
+func TestReplaySource(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+
+	path := filepath.Join(t.TempDir(), "trades.csv")
+	assert.NoError(t, os.WriteFile(path, []byte(`symbol,agg_trade_id,price,quantity,trade_time,is_buyer_maker
+BNBBTC,1,0.11111,1,1737734701,false
+BNBBTC,2,0.11121,1,1737734711,false
+BNBBTC,3,0.11104,1,1737734759,false
+BNBBTC,4,0.11101,1,1737734760,false
+BNBBTC,5,0.11131,1,1737734761,true
+`), 0o644))
+
+	t.Run("replay should feed aggregator deterministically", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+
+		stream, err := NewReplaySource(logger, []string{path}, "", "", 0).Stream(done)
+		assert.NoError(t, err)
+		aggr, updateCh := NewAggrStream(logger, done, stream, []string{"BNBBTC"})
+
+		updates := 0
+		for range updateCh {
+			updates++
+		}
+		assert.Equal(t, 5, updates)
+
+		bar, err := aggr.OHLCBar("BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, OHLCBar{H: "0.11131", L: "0.11101", O: "0.11101", C: "0.11131", T: 1737734761}, bar)
+	})
+
+	t.Run("replay should be paced by speed", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+
+		// 60 recorded seconds at 600x
+		stream, err := NewReplaySource(logger, []string{path}, FormatCSV, "", 600).Stream(done)
+		assert.NoError(t, err)
+
+		start := time.Now()
+		n := 0
+		for range stream {
+			n++
+		}
+		assert.Equal(t, 5, n)
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("replay should be paced by the clock", func(t *testing.T) {
+		done := make(chan struct{})
+		defer close(done)
+		start := time.Unix(1737734701, 0)
+		clock := NewFakeClock(start)
+
+		stream, err := NewReplaySource(logger, []string{path}, FormatCSV, "", 1).WithClock(clock).Stream(done)
+		assert.NoError(t, err)
+
+		// how far the clock was advanced when each trade arrived
+		var at []time.Duration
+		assert.Eventually(t, func() bool {
+			for {
+				select {
+				case _, ok := <-stream:
+					if !ok {
+						return true
+					}
+					at = append(at, clock.Now().Sub(start))
+				default:
+					clock.Advance(time.Second)
+					return false
+				}
+			}
+		}, 5*time.Second, time.Millisecond)
+		if assert.Len(t, at, 5) {
+			assert.Less(t, at[0], 10*time.Second)
+			assert.GreaterOrEqual(t, at[1], 10*time.Second)
+			assert.GreaterOrEqual(t, at[4], time.Minute)
+		}
+	})
+
+	t.Run("files should be merged by trade time", func(t *testing.T) {
+		other := filepath.Join(t.TempDir(), "ethbtc.csv")
+		assert.NoError(t, os.WriteFile(other, []byte(`symbol,agg_trade_id,price,quantity,trade_time,is_buyer_maker
+ETHBTC,7,0.03111,1,1737734700,false
+ETHBTC,8,0.03121,1,1737734711,false
+ETHBTC,9,0.03104,1,1737734762,false
+`), 0o644))
+		done := make(chan struct{})
+		defer close(done)
+
+		src := NewReplaySource(logger, []string{path, other}, FormatCSV, "", 0)
+		stream, err := src.Stream(done)
+		assert.NoError(t, err)
+		var order []string
+		for e := range stream {
+			order = append(order, e.Symbol+strconv.FormatInt(e.AggTradeID, 10))
+		}
+		assert.Equal(t, []string{"ETHBTC7", "BNBBTC1", "BNBBTC2", "ETHBTC8", "BNBBTC3", "BNBBTC4", "BNBBTC5", "ETHBTC9"}, order,
+			"trades of the same time keep the order of their files")
+		assert.NoError(t, src.Err())
+	})
+
+	t.Run("file that can't be opened should fail the stream", func(t *testing.T) {
+		_, err := NewReplaySource(logger, []string{path, filepath.Join(t.TempDir(), "missing.csv")}, "", "", 0).Stream(make(chan struct{}))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		assert.ErrorContains(t, err, "missing.csv")
+	})
+
+	t.Run("unreadable row should end the replay with an error", func(t *testing.T) {
+		broken := filepath.Join(t.TempDir(), "broken.csv")
+		assert.NoError(t, os.WriteFile(broken, []byte(`symbol,agg_trade_id,price,quantity,trade_time,is_buyer_maker
+BNBBTC,1,0.11111,1,1737734701,false
+BNBBTC,two,0.11121,1,1737734711,false
+BNBBTC,3,0.11104,1,1737734759,false
+`), 0o644))
+		done := make(chan struct{})
+		defer close(done)
+
+		src := NewReplaySource(logger, []string{broken}, "", "", 0)
+		stream, err := src.Stream(done)
+		assert.NoError(t, err)
+		n := 0
+		for range stream {
+			n++
+		}
+		assert.Equal(t, 1, n)
+		assert.ErrorIs(t, src.Err(), ErrMalformedRow)
+		assert.ErrorContains(t, src.Err(), "broken.csv")
+	})
+
+	t.Run("replay should stop when done", func(t *testing.T) {
+		done := make(chan struct{})
+		stream, err := NewReplaySource(logger, []string{path}, FormatCSV, "", 0.001).Stream(done)
+		assert.NoError(t, err)
+
+		<-stream
+		close(done)
+		select {
+		case _, ok := <-stream:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("replay did not stop")
+		}
+	})
+}
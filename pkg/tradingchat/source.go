@@ -0,0 +1,243 @@
+package tradingchat
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr"
+)
+
+var (
+	ErrSourceNotStarted = errors.New("source has to be streaming to change its symbols")
+	ErrConnectionLost   = errors.New("connection to binance ended")
+)
+
+const (
+	// a connection that ended is redialed after redialDelay, doubled after every failure up to maxRedialDelay
+	redialDelay    = time.Second
+	maxRedialDelay = time.Minute
+)
+
+// TradeSource produces the trade events NewAggrStream aggregates, the stream is closed once
+// the source runs out of trades, live sources stop producing when done is closed
+type TradeSource interface {
+	Stream(done <-chan struct{}) (<-chan *bconn.WsAggTradeEvent, error)
+}
+
+// DynamicSource is a TradeSource whose symbols can be changed while streaming
+type DynamicSource interface {
+	TradeSource
+	Subscribe(symbols []string) error
+	Unsubscribe(symbols []string) error
+}
+
+var _ DynamicSource = (*BinanceSource)(nil)
+
+// BinanceSource streams live aggregated trades of symbols from binance, symbols subscribed together
+// share a connection, which is stopped once all of its symbols are unsubscribed. Trades of
+// unsubscribed symbols are dropped, as a stopped driver may keep reading. A connection ending
+// by itself is reported to errHandler and redialed until it's back or the stream is done
+type BinanceSource struct {
+	logger     logr.Logger
+	symbols    []string
+	errHandler func(error)
+	clock      Clock
+	// serve opens a connection streaming trades of symbols, swapped in tests
+	serve func(symbols []string, handler func(*bconn.WsAggTradeEvent), errHandler func(error)) (doneCh, stopCh chan struct{}, err error)
+
+	rw     *sync.RWMutex
+	out    chan *bconn.WsAggTradeEvent
+	done   <-chan struct{}
+	active map[string]bool
+	conns  []*binanceConn
+}
+
+type binanceConn struct {
+	symbols []string
+	stop    func()
+}
+
+func NewBinanceSource(logger logr.Logger, symbols []string, errHandler func(error)) *BinanceSource {
+	return &BinanceSource{
+		logger:     logger,
+		symbols:    symbols,
+		errHandler: errHandler,
+		clock:      SystemClock,
+		serve: func(symbols []string, handler func(*bconn.WsAggTradeEvent), errHandler func(error)) (chan struct{}, chan struct{}, error) {
+			return bconn.NewWebsocketStreamClient(true, BinanceStreamURL).WsCombinedAggTradeServe(symbols, handler, errHandler)
+		},
+		rw:     &sync.RWMutex{},
+		active: map[string]bool{},
+	}
+}
+
+// Stream implements TradeSource.
+func (b *BinanceSource) Stream(done <-chan struct{}) (<-chan *bconn.WsAggTradeEvent, error) {
+	b.rw.Lock()
+	b.out = make(chan *bconn.WsAggTradeEvent)
+	b.done = done
+	b.rw.Unlock()
+
+	if err := b.Subscribe(b.symbols); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-done
+		b.rw.Lock()
+		defer b.rw.Unlock()
+		for _, c := range b.conns {
+			c.stop()
+		}
+		b.conns = nil
+	}()
+	return b.out, nil
+}
+
+// Subscribe implements DynamicSource.
+func (b *BinanceSource) Subscribe(symbols []string) error {
+	b.rw.Lock()
+	defer b.rw.Unlock()
+	if b.out == nil {
+		return ErrSourceNotStarted
+	}
+
+	// symbols of a connection still open only need to be let through again
+	var dial []string
+	for _, s := range symbols {
+		if b.active[s] {
+			continue
+		}
+		if !slices.ContainsFunc(b.conns, func(c *binanceConn) bool { return slices.Contains(c.symbols, s) }) {
+			dial = append(dial, s)
+		}
+		b.active[s] = true
+	}
+	if len(dial) == 0 {
+		return nil
+	}
+	if err := b.dial(dial); err != nil {
+		for _, s := range dial {
+			delete(b.active, s)
+		}
+		return err
+	}
+	return nil
+}
+
+// dial opens a connection streaming trades of symbols, b.rw has to be held
+func (b *BinanceSource) dial(symbols []string) error {
+	doneCh, stopCh, err := b.serve(
+		symbols,
+		func(event *bconn.WsAggTradeEvent) {
+			b.rw.RLock()
+			active := b.active[event.Symbol]
+			b.rw.RUnlock()
+			if !active {
+				return
+			}
+			b.logger.V(4).Info("incoming event", "event", event)
+			select {
+			case b.out <- event:
+			case <-b.done:
+			}
+		},
+		func(err error) {
+			b.logger.V(2).Error(err, "driver stopped")
+			b.errHandler(err)
+		},
+	)
+	if err != nil {
+		b.logger.V(2).Error(err, "starting driver failed")
+		return err
+	}
+
+	conn := &binanceConn{symbols: symbols}
+	// the driver reads stopCh itself on read errors, so it can't be closed
+	conn.stop = func() {
+		select {
+		case stopCh <- struct{}{}:
+		case <-doneCh:
+		}
+	}
+	b.conns = append(b.conns, conn)
+	b.logger.Info("subscribed", "symbols", symbols)
+
+	go func() {
+		<-doneCh
+		b.rw.Lock()
+		b.conns = slices.DeleteFunc(b.conns, func(c *binanceConn) bool { return c == conn })
+		lost := b.unserved(conn.symbols)
+		b.rw.Unlock()
+
+		select {
+		case <-b.done:
+			return
+		default:
+		}
+		if len(lost) > 0 {
+			b.logger.Error(ErrConnectionLost, "connection ended, redialing", "symbols", lost)
+			b.errHandler(ErrConnectionLost)
+			b.redial(lost)
+		}
+	}()
+	return nil
+}
+
+// unserved returns symbols still subscribed that no connection serves, b.rw has to be held
+func (b *BinanceSource) unserved(symbols []string) []string {
+	var lost []string
+	for _, s := range symbols {
+		if b.active[s] && !slices.ContainsFunc(b.conns, func(c *binanceConn) bool { return slices.Contains(c.symbols, s) }) {
+			lost = append(lost, s)
+		}
+	}
+	return lost
+}
+
+// redial serves symbols by a new connection, it retries until it succeeds, the symbols are unsubscribed or the stream is done
+func (b *BinanceSource) redial(symbols []string) {
+	for delay := redialDelay; ; delay = min(2*delay, maxRedialDelay) {
+		select {
+		case <-b.done:
+			return
+		case <-b.clock.After(delay):
+		}
+
+		b.rw.Lock()
+		lost := b.unserved(symbols)
+		var err error
+		if len(lost) > 0 {
+			err = b.dial(lost)
+		}
+		b.rw.Unlock()
+		if err == nil {
+			return
+		}
+		b.logger.Error(err, "redialing failed", "symbols", lost, "retry_in", min(2*delay, maxRedialDelay))
+		b.errHandler(err)
+	}
+}
+
+// Unsubscribe implements DynamicSource.
+func (b *BinanceSource) Unsubscribe(symbols []string) error {
+	b.rw.Lock()
+	defer b.rw.Unlock()
+	if b.out == nil {
+		return ErrSourceNotStarted
+	}
+
+	for _, s := range symbols {
+		delete(b.active, s)
+	}
+	for _, c := range b.conns {
+		if !slices.ContainsFunc(c.symbols, func(s string) bool { return b.active[s] }) {
+			b.logger.Info("unsubscribed", "symbols", c.symbols)
+			c.stop()
+		}
+	}
+	return nil
+}
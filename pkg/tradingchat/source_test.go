@@ -0,0 +1,162 @@
+package tradingchat
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver stands in for binance connections, each served connection is kept to emit trades through
+type fakeDriver struct {
+	mu       sync.Mutex
+	conns    []*fakeConn
+	failures int // serving fails this many more times
+}
+
+type fakeConn struct {
+	symbols []string
+	handler func(*bconn.WsAggTradeEvent)
+	stopped chan struct{}
+	die     chan struct{} // ends the connection as if it was lost
+}
+
+func (d *fakeDriver) serve(symbols []string, handler func(*bconn.WsAggTradeEvent), _ func(error)) (chan struct{}, chan struct{}, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failures > 0 {
+		d.failures--
+		return nil, nil, errors.New("dial failed")
+	}
+	doneCh, stopCh := make(chan struct{}), make(chan struct{})
+	conn := &fakeConn{symbols: symbols, handler: handler, stopped: doneCh, die: make(chan struct{})}
+	d.conns = append(d.conns, conn)
+	go func() {
+		select {
+		case <-stopCh:
+		case <-conn.die:
+		}
+		close(doneCh)
+	}()
+	return doneCh, stopCh, nil
+}
+
+func (d *fakeDriver) conn(i int) *fakeConn {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.conns[i]
+}
+
+func (d *fakeDriver) served() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.conns)
+}
+
+func TestBinanceSource(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	driver := &fakeDriver{}
+	src := NewBinanceSource(logger, []string{"BNBBTC"}, func(err error) {})
+	src.serve = driver.serve
+
+	assert.ErrorIs(t, src.Subscribe([]string{"ETHBTC"}), ErrSourceNotStarted)
+
+	done := make(chan struct{})
+	defer close(done)
+	stream, err := src.Stream(done)
+	assert.NoError(t, err)
+
+	emit := func(conn *fakeConn, symbol string) {
+		go conn.handler(&bconn.WsAggTradeEvent{Symbol: symbol, TradeTime: 1737734701000})
+	}
+	receive := func() *bconn.WsAggTradeEvent {
+		select {
+		case e := <-stream:
+			return e
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		}
+	}
+
+	t.Run("added symbols should be served by a new connection", func(t *testing.T) {
+		assert.NoError(t, src.Subscribe([]string{"ETHBTC", "BNBBTC"}))
+		assert.Len(t, driver.conns, 2)
+		assert.Equal(t, []string{"ETHBTC"}, driver.conn(1).symbols)
+
+		emit(driver.conn(1), "ETHBTC")
+		e := receive()
+		if assert.NotNil(t, e) {
+			assert.Equal(t, "ETHBTC", e.Symbol)
+			assert.Equal(t, int64(1737734701000), e.TradeTime)
+		}
+	})
+
+	t.Run("removed symbols should be dropped and idle connections stopped", func(t *testing.T) {
+		assert.NoError(t, src.Unsubscribe([]string{"ETHBTC"}))
+		select {
+		case <-driver.conn(1).stopped:
+		case <-time.After(time.Second):
+			t.Fatal("connection of removed symbol is not stopped")
+		}
+
+		emit(driver.conn(1), "ETHBTC")
+		assert.Nil(t, receive())
+
+		emit(driver.conn(0), "BNBBTC")
+		assert.NotNil(t, receive())
+	})
+}
+
+func TestBinanceSourceRedial(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	clock := NewFakeClock(time.Date(2025, 1, 24, 16, 5, 0, 0, time.UTC))
+	driver := &fakeDriver{}
+	errs := make(chan error, 10)
+	src := NewBinanceSource(logger, []string{"BNBBTC", "ETHBTC"}, func(err error) { errs <- err })
+	src.serve = driver.serve
+	src.clock = clock
+
+	done := make(chan struct{})
+	defer close(done)
+	stream, err := src.Stream(done)
+	assert.NoError(t, err)
+
+	// the connection is lost, and the first redial fails too
+	driver.failures = 1
+	close(driver.conn(0).die)
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrConnectionLost)
+	case <-time.After(time.Second):
+		t.Fatal("lost connection is not reported")
+	}
+
+	assert.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		return driver.served() == 2
+	}, 5*time.Second, 10*time.Millisecond, "connection is not redialed")
+	assert.Len(t, errs, 1, "failed redial is reported")
+	assert.Equal(t, []string{"BNBBTC", "ETHBTC"}, driver.conn(1).symbols)
+
+	go driver.conn(1).handler(&bconn.WsAggTradeEvent{Symbol: "ETHBTC", TradeTime: 1737734701000})
+	select {
+	case e := <-stream:
+		assert.Equal(t, "ETHBTC", e.Symbol)
+	case <-time.After(time.Second):
+		t.Fatal("no trade from the redialed connection")
+	}
+
+	t.Run("unsubscribed symbols should not be redialed", func(t *testing.T) {
+		assert.NoError(t, src.Unsubscribe([]string{"ETHBTC"}))
+		close(driver.conn(1).die)
+		assert.Eventually(t, func() bool {
+			clock.Advance(time.Second)
+			return driver.served() == 3
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"BNBBTC"}, driver.conn(2).symbols)
+	})
+}
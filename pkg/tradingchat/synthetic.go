@@ -0,0 +1,206 @@
+package tradingchat
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+)
+
+var ErrInvalidSynthetic = errors.New("invalid synthetic instrument")
+
+const (
+	// legs of a synthetic trade are at most this much older than the trade of the leg that moved
+	syntheticMaxSkew = time.Minute
+	// decimals of synthetic prices parsed by ParseSyntheticSpec
+	syntheticDecimals = 8
+)
+
+// SyntheticKind is how prices of the legs are combined
+type SyntheticKind string
+
+const (
+	SyntheticRatio  SyntheticKind = "ratio"  // first leg over the second, e.g. ETHBNB from ETHBTC and BNBBTC
+	SyntheticSpread SyntheticKind = "spread" // first leg minus the second
+	SyntheticBasket SyntheticKind = "basket" // sum of every leg
+)
+
+// SyntheticLeg is a registered symbol a synthetic instrument is derived from, its price is multiplied by Weight
+type SyntheticLeg struct {
+	Symbol string  `json:"symbol"`
+	Weight float64 `json:"weight"`
+}
+
+// SyntheticSpec defines an instrument whose trades are derived from trades of its legs, it's aggregated
+// into bars of Symbol like any symbol of the trade source
+type SyntheticSpec struct {
+	Symbol   string         `json:"symbol"`
+	Kind     SyntheticKind  `json:"kind"`
+	Legs     []SyntheticLeg `json:"legs"`
+	Decimals int            `json:"decimals"`
+}
+
+func (s SyntheticSpec) Validate() error {
+	if s.Symbol == "" {
+		return fmt.Errorf("%w: symbol is missing", ErrInvalidSynthetic)
+	}
+	switch s.Kind {
+	case SyntheticRatio, SyntheticSpread:
+		if len(s.Legs) != 2 {
+			return fmt.Errorf("%w: %s of %s needs 2 legs", ErrInvalidSynthetic, s.Kind, s.Symbol)
+		}
+	case SyntheticBasket:
+		if len(s.Legs) == 0 {
+			return fmt.Errorf("%w: basket %s has no legs", ErrInvalidSynthetic, s.Symbol)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidSynthetic, s.Kind)
+	}
+	for i, leg := range s.Legs {
+		if leg.Symbol == "" || leg.Symbol == s.Symbol {
+			return fmt.Errorf("%w: %s has an invalid leg %q", ErrInvalidSynthetic, s.Symbol, leg.Symbol)
+		}
+		if leg.Weight == 0 || math.IsNaN(leg.Weight) || math.IsInf(leg.Weight, 0) {
+			return fmt.Errorf("%w: leg %s of %s needs a weight", ErrInvalidSynthetic, leg.Symbol, s.Symbol)
+		}
+		if slices.ContainsFunc(s.Legs[:i], func(l SyntheticLeg) bool { return l.Symbol == leg.Symbol }) {
+			return fmt.Errorf("%w: leg %s of %s is repeated", ErrInvalidSynthetic, leg.Symbol, s.Symbol)
+		}
+	}
+	if s.Decimals < 0 || s.Decimals > 16 {
+		return fmt.Errorf("%w: %s can't have %d decimals", ErrInvalidSynthetic, s.Symbol, s.Decimals)
+	}
+	return nil
+}
+
+// ParseSyntheticSpec parses definitions like ETHBNB=ETHBTC/BNBBTC, SPREAD=ETHBTC-2*BNBBTC or
+// IDX=0.5*ETHBTC+0.5*BNBBTC, legs without a weight weigh 1
+func ParseSyntheticSpec(s string) (SyntheticSpec, error) {
+	symbol, expr, ok := strings.Cut(strings.TrimSpace(s), "=")
+	if !ok {
+		return SyntheticSpec{}, fmt.Errorf("%w: %q isn't symbol=expression", ErrInvalidSynthetic, s)
+	}
+	spec := SyntheticSpec{Symbol: strings.TrimSpace(symbol), Decimals: syntheticDecimals}
+
+	var terms []string
+	switch {
+	case strings.Contains(expr, "/"):
+		spec.Kind, terms = SyntheticRatio, strings.Split(expr, "/")
+	case strings.Contains(expr, "-"):
+		spec.Kind, terms = SyntheticSpread, strings.Split(expr, "-")
+	default:
+		spec.Kind, terms = SyntheticBasket, strings.Split(expr, "+")
+	}
+	for _, term := range terms {
+		leg := SyntheticLeg{Symbol: strings.TrimSpace(term), Weight: 1}
+		if w, sym, ok := strings.Cut(term, "*"); ok {
+			weight, err := strconv.ParseFloat(strings.TrimSpace(w), 64)
+			if err != nil {
+				return SyntheticSpec{}, fmt.Errorf("%w: %q has an invalid weight", ErrInvalidSynthetic, s)
+			}
+			leg = SyntheticLeg{Symbol: strings.TrimSpace(sym), Weight: weight}
+		}
+		spec.Legs = append(spec.Legs, leg)
+	}
+	return spec, spec.Validate()
+}
+
+var _ derived = (*synthetic)(nil)
+
+// synthetic derives trades of a synthetic instrument from the latest trade of every leg
+type synthetic struct {
+	spec   SyntheticSpec
+	prices []float64
+	times  []int64 // time of the latest trade of every leg in milliseconds, 0 until it traded
+	lastID int64   // id of the latest synthetic trade, ids are contiguous like ids of real trades
+}
+
+func newSynthetic(spec SyntheticSpec) *synthetic {
+	return &synthetic{
+		spec:   spec,
+		prices: make([]float64, len(spec.Legs)),
+		times:  make([]int64, len(spec.Legs)),
+	}
+}
+
+func (s *synthetic) symbol() string      { return s.spec.Symbol }
+func (s *synthetic) resume(lastID int64) { s.lastID = lastID }
+
+func (s *synthetic) legs() []string {
+	legs := make([]string, 0, len(s.spec.Legs))
+	for _, leg := range s.spec.Legs {
+		legs = append(legs, leg.Symbol)
+	}
+	return legs
+}
+
+// trade implements derived. It updates the leg of e, and returns the synthetic trade at the time of e once
+// every leg traded within syntheticMaxSkew before it. Legs keep their latest trade, late older trades are ignored
+func (s *synthetic) trade(e *bconn.WsAggTradeEvent) (*bconn.WsAggTradeEvent, bool) {
+	i := slices.IndexFunc(s.spec.Legs, func(l SyntheticLeg) bool { return l.Symbol == e.Symbol })
+	if i < 0 || e.TradeTime < s.times[i] {
+		return nil, false
+	}
+	price, err := strconv.ParseFloat(e.Price, 64)
+	if err != nil {
+		return nil, false
+	}
+	s.prices[i], s.times[i] = price, e.TradeTime
+
+	for _, t := range s.times {
+		if t == 0 || e.TradeTime-t > syntheticMaxSkew.Milliseconds() {
+			return nil, false
+		}
+	}
+	v, ok := s.value()
+	if !ok {
+		return nil, false
+	}
+	s.lastID++
+	return &bconn.WsAggTradeEvent{
+		Event:      e.Event,
+		Symbol:     s.spec.Symbol,
+		AggTradeID: s.lastID,
+		Price:      strconv.FormatFloat(v, 'f', s.spec.Decimals, 64),
+		TradeTime:  e.TradeTime,
+	}, true
+}
+
+func (s *synthetic) state() DerivedState {
+	legs := make([]LegState, len(s.prices))
+	for i := range legs {
+		legs[i] = LegState{Price: s.prices[i], Time: s.times[i]}
+	}
+	return DerivedState{Legs: legs}
+}
+
+func (s *synthetic) restore(state DerivedState) error {
+	if len(state.Legs) != len(s.spec.Legs) {
+		return ErrStateMismatch
+	}
+	for i, leg := range state.Legs {
+		s.prices[i], s.times[i] = leg.Price, leg.Time
+	}
+	return nil
+}
+
+func (s *synthetic) value() (float64, bool) {
+	legs := s.spec.Legs
+	var v float64
+	switch s.spec.Kind {
+	case SyntheticRatio:
+		v = legs[0].Weight * s.prices[0] / (legs[1].Weight * s.prices[1])
+	case SyntheticSpread:
+		v = legs[0].Weight*s.prices[0] - legs[1].Weight*s.prices[1]
+	default:
+		for i, leg := range legs {
+			v += leg.Weight * s.prices[i]
+		}
+	}
+	return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+}
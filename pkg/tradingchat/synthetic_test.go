@@ -0,0 +1,114 @@
+package tradingchat
+
+import (
+	"testing"
+	"time"
+
+	bconn "github.com/binance/binance-connector-go"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyntheticSpec(t *testing.T) {
+	t.Run("definitions should be parsed", func(t *testing.T) {
+		spec, err := ParseSyntheticSpec("ETHBNB=ETHBTC/BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, SyntheticSpec{
+			Symbol:   "ETHBNB",
+			Kind:     SyntheticRatio,
+			Legs:     []SyntheticLeg{{Symbol: "ETHBTC", Weight: 1}, {Symbol: "BNBBTC", Weight: 1}},
+			Decimals: 8,
+		}, spec)
+
+		spec, err = ParseSyntheticSpec("IDX=0.5*ETHBTC+0.5*BNBBTC")
+		assert.NoError(t, err)
+		assert.Equal(t, SyntheticBasket, spec.Kind)
+		assert.Equal(t, []SyntheticLeg{{Symbol: "ETHBTC", Weight: 0.5}, {Symbol: "BNBBTC", Weight: 0.5}}, spec.Legs)
+	})
+
+	t.Run("invalid definitions should fail", func(t *testing.T) {
+		for _, s := range []string{"ETHBNB", "ETHBNB=ETHBTC/BNBBTC/LTCBTC", "X=ETHBTC-ETHBTC", "X=x*ETHBTC", "X=0*ETHBTC", "ETHBTC=ETHBTC/BNBBTC"} {
+			_, err := ParseSyntheticSpec(s)
+			assert.ErrorIs(t, err, ErrInvalidSynthetic, s)
+		}
+	})
+}
+
+func TestSynthetic(t *testing.T) {
+	// 16:05 on Jan 24th 2025
+	const open = int64(1737734700)
+	spec, _ := ParseSyntheticSpec("SPREAD=ETHBTC-2*BNBBTC")
+	syn := newSynthetic(spec)
+
+	_, ok := syn.trade(&bconn.WsAggTradeEvent{Symbol: "ETHBTC", Price: "0.03", TradeTime: open * 1000})
+	assert.False(t, ok, "a leg has no price yet")
+
+	trade, ok := syn.trade(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.02", TradeTime: (open + 10) * 1000})
+	assert.True(t, ok)
+	assert.Equal(t, &bconn.WsAggTradeEvent{Symbol: "SPREAD", AggTradeID: 1, Price: "-0.01000000", TradeTime: (open + 10) * 1000}, trade)
+
+	_, ok = syn.trade(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.01", TradeTime: (open + 5) * 1000})
+	assert.False(t, ok, "late trades of a leg should be ignored")
+
+	_, ok = syn.trade(&bconn.WsAggTradeEvent{Symbol: "BNBBTC", Price: "0.01", TradeTime: (open + 61) * 1000})
+	assert.False(t, ok, "the other leg is stale")
+
+	trade, ok = syn.trade(&bconn.WsAggTradeEvent{Symbol: "ETHBTC", Price: "0.04", TradeTime: (open + 62) * 1000})
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), trade.AggTradeID)
+	assert.Equal(t, "0.02000000", trade.Price)
+}
+
+func TestAggrSynthetics(t *testing.T) {
+	logger := testr.NewWithOptions(t, testr.Options{Verbosity: 4})
+	ethbnb, _ := ParseSyntheticSpec("ETHBNB=ETHBTC/BNBBTC")
+	orphan, _ := ParseSyntheticSpec("LTCBNB=LTCBTC/BNBBTC")
+
+	done := make(chan struct{})
+	defer close(done)
+	stream := make(chan *bconn.WsAggTradeEvent)
+	ag, updateCh, _ := NewAggrStreamWithOptions(logger, done, stream, []string{"ETHBTC", "BNBBTC"}, AggrOptions{
+		Synthetics: []SyntheticSpec{ethbnb, orphan},
+	})
+	assert.Equal(t, []string{"BNBBTC", "ETHBNB", "ETHBTC"}, ag.Symbols(), "synthetics of unregistered legs should be ignored")
+
+	// 16:05 on Jan 24th 2025
+	stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", AggTradeID: 1, Price: "0.03", TradeTime: 1737734700000}
+	stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 1, Price: "0.01", TradeTime: 1737734701000}
+	stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", AggTradeID: 2, Price: "0.02", TradeTime: 1737734702000}
+	updates := map[string]int{}
+	for len(updates) < 3 || updates["ETHBNB"] < 2 {
+		updates[<-updateCh]++
+	}
+
+	bar, err := ag.OHLCBar("ETHBNB")
+	assert.NoError(t, err)
+	assert.Equal(t, OHLCBar{H: "3.00000000", L: "2.00000000", O: "3.00000000", C: "2.00000000", T: 1737734702}, bar)
+
+	// a duplicated trade of a leg derives no trade
+	stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", AggTradeID: 2, Price: "0.02", TradeTime: 1737734702000}
+	stream <- &bconn.WsAggTradeEvent{Symbol: "BNBBTC", AggTradeID: 2, Price: "0.02", TradeTime: 1737734703000}
+	for bar.C != "1.00000000" {
+		if <-updateCh == "ETHBNB" {
+			bar, _ = ag.OHLCBar("ETHBNB")
+		}
+	}
+	assert.Equal(t, int64(3), snapshot(ag, nil, time.Unix(1737734703, 0)).Symbols["ETHBNB"].LastID)
+
+	// legs are kept while the instrument is registered, and stop deriving it once it's removed
+	assert.Empty(t, ag.RemoveSymbols([]string{"ETHBTC"}))
+	assert.True(t, ag.IsLeg("ETHBTC"))
+	assert.Equal(t, []string{"ETHBNB"}, ag.RemoveSymbols([]string{"ETHBNB"}))
+	assert.False(t, ag.IsLeg("ETHBTC"))
+
+	// trades of a symbol registered again under the name of the instrument are its own, a derived trade
+	// would be dispatched to it before the trade that follows
+	ag.AddSymbols([]string{"ETHBNB"})
+	stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBTC", AggTradeID: 3, Price: "0.03", TradeTime: 1737734704000}
+	stream <- &bconn.WsAggTradeEvent{Symbol: "ETHBNB", AggTradeID: 1, Price: "5", TradeTime: 1737734705000}
+	for <-updateCh != "ETHBNB" {
+	}
+	bar, _ = ag.OHLCBar("ETHBNB")
+	assert.Equal(t, "5", bar.O)
+	assert.Equal(t, []string{"ETHBTC"}, ag.RemoveSymbols([]string{"ETHBTC"}))
+}
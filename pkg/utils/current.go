@@ -8,7 +8,10 @@ func OrDone[T any](done <-chan struct{}, stream <-chan T) <-chan T {
 			select {
 			case <-done:
 				return
-			case data := <-stream:
+			case data, ok := <-stream:
+				if !ok {
+					return
+				}
 				select {
 				case <-done:
 					return
@@ -20,4 +23,3 @@ func OrDone[T any](done <-chan struct{}, stream <-chan T) <-chan T {
 	}()
 	return relayStream
 }
-
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS ohlc1m_symbol_ts_key;
+ALTER TABLE OHLC1M DROP COLUMN IF EXISTS symbol;
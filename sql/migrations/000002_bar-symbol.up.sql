@@ -0,0 +1,10 @@
+ALTER TABLE OHLC1M ADD COLUMN symbol VARCHAR(20) NOT NULL DEFAULT '';
+
+-- bars used to be inserted on every trade, keep only the latest row of each minute
+DELETE FROM OHLC1M a USING OHLC1M b
+WHERE a.symbol = b.symbol
+  AND date_trunc('minute', a.ts) = date_trunc('minute', b.ts)
+  AND a.id < b.id;
+UPDATE OHLC1M SET ts = date_trunc('minute', ts);
+
+CREATE UNIQUE INDEX ohlc1m_symbol_ts_key ON OHLC1M (symbol, ts);
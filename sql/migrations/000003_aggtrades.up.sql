@@ -0,0 +1,10 @@
+CREATE TABLE AGGTRADES (
+  symbol         VARCHAR(20) NOT NULL,
+  id             BIGINT NOT NULL,
+  price          NUMERIC(28,10) NOT NULL,
+  qty            NUMERIC(28,10) NOT NULL,
+  is_buyer_maker BOOLEAN NOT NULL,
+  trade_time     BIGINT NOT NULL,
+  PRIMARY KEY (symbol, id)
+);
+CREATE INDEX aggtrades_symbol_trade_time_idx ON AGGTRADES (symbol, trade_time);
@@ -0,0 +1,5 @@
+CREATE TABLE CHECKPOINTS (
+  name     VARCHAR(64) PRIMARY KEY,
+  state    JSONB NOT NULL,
+  taken_at BIGINT NOT NULL
+);
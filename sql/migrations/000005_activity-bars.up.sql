@@ -0,0 +1,18 @@
+CREATE TABLE ACTIVITY_BARS (
+  symbol     VARCHAR(20) NOT NULL,
+  bar_type   VARCHAR(16) NOT NULL,
+  threshold  NUMERIC(38,10) NOT NULL,
+  first_id   BIGINT NOT NULL,
+  last_id    BIGINT NOT NULL,
+  open_time  BIGINT NOT NULL,
+  close_time BIGINT NOT NULL,
+  h          NUMERIC(28,10) NOT NULL,
+  l          NUMERIC(28,10) NOT NULL,
+  o          NUMERIC(28,10) NOT NULL,
+  c          NUMERIC(28,10) NOT NULL,
+  volume     NUMERIC(38,10) NOT NULL,
+  notional   NUMERIC(38,10) NOT NULL,
+  trades     BIGINT NOT NULL,
+  PRIMARY KEY (symbol, bar_type, threshold, first_id)
+);
+CREATE INDEX activity_bars_close_time_idx ON ACTIVITY_BARS (symbol, bar_type, threshold, close_time);
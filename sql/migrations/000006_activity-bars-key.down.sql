@@ -0,0 +1,3 @@
+DELETE FROM ACTIVITY_BARS WHERE seq > 0;
+ALTER TABLE ACTIVITY_BARS DROP CONSTRAINT activity_bars_pkey, ADD PRIMARY KEY (symbol, bar_type, threshold, first_id);
+ALTER TABLE ACTIVITY_BARS DROP COLUMN seq;
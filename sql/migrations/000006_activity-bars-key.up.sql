@@ -0,0 +1,3 @@
+-- a trade moving price by several Renko bricks or range bars closes bars sharing first_id, seq orders them
+ALTER TABLE ACTIVITY_BARS ADD COLUMN seq BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE ACTIVITY_BARS DROP CONSTRAINT activity_bars_pkey, ADD PRIMARY KEY (symbol, bar_type, threshold, first_id, seq);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS QUOTE_BARS;
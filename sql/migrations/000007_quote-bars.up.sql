@@ -0,0 +1,16 @@
+CREATE TABLE QUOTE_BARS (
+  symbol      VARCHAR(20) NOT NULL,
+  open_time   BIGINT NOT NULL,
+  updated_at  BIGINT NOT NULL,
+  bid_h       NUMERIC(28,10) NOT NULL,
+  bid_l       NUMERIC(28,10) NOT NULL,
+  bid_o       NUMERIC(28,10) NOT NULL,
+  bid_c       NUMERIC(28,10) NOT NULL,
+  ask_h       NUMERIC(28,10) NOT NULL,
+  ask_l       NUMERIC(28,10) NOT NULL,
+  ask_o       NUMERIC(28,10) NOT NULL,
+  ask_c       NUMERIC(28,10) NOT NULL,
+  mean_spread NUMERIC(28,10) NOT NULL,
+  quotes      BIGINT NOT NULL,
+  PRIMARY KEY (symbol, open_time)
+);
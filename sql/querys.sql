@@ -21,3 +21,97 @@ WHERE id = $1;
 -- name: DeleteBar :exec
 DELETE FROM OHLC1M
 WHERE id = $1;
+
+-- name: UpsertBar :exec
+INSERT INTO OHLC1M (
+  symbol, h, l, o, c, ts
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (symbol, ts) DO UPDATE
+  set h = EXCLUDED.h,
+ l = EXCLUDED.l,
+ o = EXCLUDED.o,
+ c = EXCLUDED.c;
+
+-- name: ListBarsBySymbol :many
+SELECT * FROM OHLC1M
+WHERE symbol = @symbol AND ts >= @start_ts AND ts < @end_ts
+ORDER BY ts;
+
+-- name: InsertTrades :exec
+INSERT INTO AGGTRADES (
+  symbol, id, price, qty, is_buyer_maker, trade_time
+)
+SELECT
+  unnest(@symbols::varchar[]),
+  unnest(@ids::bigint[]),
+  unnest(@prices::text[])::numeric,
+  unnest(@qtys::text[])::numeric,
+  unnest(@is_buyer_makers::boolean[]),
+  unnest(@trade_times::bigint[])
+ON CONFLICT DO NOTHING;
+
+-- name: ListTrades :many
+SELECT * FROM AGGTRADES
+WHERE symbol = @symbol AND trade_time >= @start_time AND trade_time < @end_time
+ORDER BY id;
+
+-- name: GetCheckpoint :one
+SELECT * FROM CHECKPOINTS
+WHERE name = $1;
+
+-- name: SaveCheckpoint :exec
+INSERT INTO CHECKPOINTS (
+  name, state, taken_at
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (name) DO UPDATE
+  set state = EXCLUDED.state,
+ taken_at = EXCLUDED.taken_at;
+
+-- name: UpsertActivityBar :exec
+INSERT INTO ACTIVITY_BARS (
+  symbol, bar_type, threshold, first_id, last_id, open_time, close_time, h, l, o, c, volume, notional, trades, seq
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+)
+ON CONFLICT (symbol, bar_type, threshold, first_id, seq) DO UPDATE
+  set last_id = EXCLUDED.last_id,
+ close_time = EXCLUDED.close_time,
+ h = EXCLUDED.h,
+ l = EXCLUDED.l,
+ o = EXCLUDED.o,
+ c = EXCLUDED.c,
+ volume = EXCLUDED.volume,
+ notional = EXCLUDED.notional,
+ trades = EXCLUDED.trades;
+
+-- name: ListActivityBars :many
+SELECT * FROM ACTIVITY_BARS
+WHERE symbol = @symbol AND bar_type = @bar_type AND threshold = @threshold
+  AND close_time >= @start_time AND close_time < @end_time
+ORDER BY first_id, seq;
+
+-- name: UpsertQuoteBar :exec
+INSERT INTO QUOTE_BARS (
+  symbol, open_time, updated_at, bid_h, bid_l, bid_o, bid_c, ask_h, ask_l, ask_o, ask_c, mean_spread, quotes
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (symbol, open_time) DO UPDATE
+  set updated_at = EXCLUDED.updated_at,
+ bid_h = EXCLUDED.bid_h,
+ bid_l = EXCLUDED.bid_l,
+ bid_c = EXCLUDED.bid_c,
+ ask_h = EXCLUDED.ask_h,
+ ask_l = EXCLUDED.ask_l,
+ ask_c = EXCLUDED.ask_c,
+ mean_spread = EXCLUDED.mean_spread,
+ quotes = EXCLUDED.quotes;
+
+-- name: ListQuoteBars :many
+SELECT * FROM QUOTE_BARS
+WHERE symbol = @symbol AND open_time >= @start_time AND open_time < @end_time
+ORDER BY open_time;
@@ -0,0 +1,8 @@
+-- a hypertable can't be turned back into a plain table, copy its rows over instead
+CREATE TABLE ohlc1m_plain (LIKE OHLC1M INCLUDING DEFAULTS);
+INSERT INTO ohlc1m_plain SELECT * FROM OHLC1M;
+ALTER SEQUENCE ohlc1m_id_seq OWNED BY ohlc1m_plain.id;
+DROP TABLE OHLC1M;
+ALTER TABLE ohlc1m_plain RENAME TO ohlc1m;
+ALTER TABLE OHLC1M ADD PRIMARY KEY (id);
+CREATE UNIQUE INDEX ohlc1m_symbol_ts_key ON OHLC1M (symbol, ts);
@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS timescaledb;
+
+-- unique indexes of a hypertable have to include its time column
+ALTER TABLE OHLC1M DROP CONSTRAINT IF EXISTS ohlc1m_pkey;
+SELECT create_hypertable('ohlc1m', 'ts', chunk_time_interval => INTERVAL '1 day', migrate_data => true);
+
+ALTER TABLE OHLC1M SET (
+  timescaledb.compress,
+  timescaledb.compress_segmentby = 'symbol',
+  timescaledb.compress_orderby = 'ts DESC'
+);
+SELECT add_compression_policy('ohlc1m', INTERVAL '7 days');
+SELECT add_retention_policy('ohlc1m', INTERVAL '365 days');
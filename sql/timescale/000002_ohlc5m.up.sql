@@ -0,0 +1,16 @@
+CREATE MATERIALIZED VIEW OHLC5M
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT symbol,
+  time_bucket(INTERVAL '5 minutes', ts) AS ts,
+  max(h) AS h,
+  min(l) AS l,
+  first(o, ts) AS o,
+  last(c, ts) AS c
+FROM OHLC1M
+GROUP BY symbol, time_bucket(INTERVAL '5 minutes', ts)
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('ohlc5m',
+  start_offset => INTERVAL '1 hour',
+  end_offset => INTERVAL '5 minutes',
+  schedule_interval => INTERVAL '5 minutes');
@@ -0,0 +1,16 @@
+CREATE MATERIALIZED VIEW OHLC1D
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT symbol,
+  time_bucket(INTERVAL '1 day', ts) AS ts,
+  max(h) AS h,
+  min(l) AS l,
+  first(o, ts) AS o,
+  last(c, ts) AS c
+FROM OHLC1M
+GROUP BY symbol, time_bucket(INTERVAL '1 day', ts)
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('ohlc1d',
+  start_offset => INTERVAL '7 days',
+  end_offset => INTERVAL '1 day',
+  schedule_interval => INTERVAL '1 hour');
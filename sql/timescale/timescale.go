@@ -0,0 +1,8 @@
+// Package timescale embeds the migrations of the TimescaleDB storage profile, they are
+// applied on top of the base schema and tracked in a version table of their own.
+package timescale
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS